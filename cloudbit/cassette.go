@@ -0,0 +1,255 @@
+package cloudbit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// cassetteRedactedFields are masked in recorded request and response bodies, as
+// they contain credentials. A replayed test therefore only sees a placeholder
+// for them in the responses.
+var cassetteRedactedFields = map[string]bool{
+	"token":       true,
+	"password":    true,
+	"private_key": true,
+	"kube_config": true,
+}
+
+// cassetteRequestRedactedFields are masked in recorded request bodies. As
+// requests are matched after redaction, they do not need to be stable between
+// recording and replaying (e.g. generated keys or certificates). Public keys and
+// certificates are kept in the responses, so that replayed tests can check them.
+var cassetteRequestRedactedFields = map[string]bool{
+	"token":       true,
+	"password":    true,
+	"private_key": true,
+	"kube_config": true,
+	"public_key":  true,
+	"certificate": true,
+}
+
+// cassetteHeaders are the response headers kept in a cassette.
+var cassetteHeaders = []string{
+	"Content-Type",
+	"X-Request-Id",
+	"X-Pagination-Current-Page",
+	"X-Pagination-Limit",
+	"X-Pagination-Count",
+	"X-Pagination-Total-Count",
+	"X-Pagination-Total-Pages",
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+
+	replayed bool
+}
+
+// cassette records the http interactions of the provider with the api and
+// replays them later on, which allows running tests without the api.
+type cassette struct {
+	Values       []string               `json:"values"`
+	Interactions []*cassetteInteraction `json:"interactions"`
+
+	path      string
+	recording bool
+	mu        sync.Mutex
+}
+
+func newRecordingCassette(path string) *cassette {
+	return &cassette{
+		Values:       []string{},
+		Interactions: []*cassetteInteraction{},
+		path:         path,
+		recording:    true,
+	}
+}
+
+func loadCassette(path string) (*cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &cassette{path: path}
+	err = json.Unmarshal(data, c)
+	if err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Save writes the recorded interactions to the path of the cassette.
+func (c *cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(c.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// Value records the value returned by generate or, when replaying, returns the
+// recorded value instead. This is used for random values in requests, which
+// have to be the same during the replay.
+func (c *cassette) Value(generate func() string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.recording {
+		value := generate()
+		c.Values = append(c.Values, value)
+		return value, nil
+	}
+
+	if len(c.Values) == 0 {
+		return "", errors.New("no more recorded values in cassette")
+	}
+
+	value := c.Values[0]
+	c.Values = c.Values[1:]
+	return value, nil
+}
+
+// Transport returns a transport which records the interactions using base or
+// replays the recorded interactions without calling base.
+func (c *cassette) Transport(base http.RoundTripper) http.RoundTripper {
+	return cassetteTransport{cassette: c, base: base}
+}
+
+func (c *cassette) record(req cassetteRequest, res cassetteResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, &cassetteInteraction{Request: req, Response: res})
+}
+
+// find returns the first interaction matching the request, which has not been
+// replayed yet. Once all matching interactions have been replayed, the last
+// one is returned again, as polling might take more requests than recorded.
+func (c *cassette) find(req cassetteRequest) (*cassetteInteraction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var last *cassetteInteraction
+	for _, interaction := range c.Interactions {
+		if interaction.Request != req {
+			continue
+		}
+
+		if !interaction.replayed {
+			interaction.replayed = true
+			return interaction, true
+		}
+
+		last = interaction
+	}
+
+	return last, last != nil
+}
+
+type cassetteTransport struct {
+	cassette *cassette
+	base     http.RoundTripper
+}
+
+func (c cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	recordedRequest := cassetteRequest{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Body:   redactJSON(body, cassetteRequestRedactedFields),
+	}
+
+	if c.cassette.recording {
+		return c.record(req, recordedRequest)
+	}
+
+	interaction, found := c.cassette.find(recordedRequest)
+	if !found {
+		return nil, fmt.Errorf("no interaction recorded for `%s %s` in cassette %s", req.Method, recordedRequest.URL, c.cassette.path)
+	}
+
+	res := &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}
+
+	for key, value := range interaction.Response.Headers {
+		res.Header.Set(key, value)
+	}
+
+	return res, nil
+}
+
+func (c cassetteTransport) record(req *http.Request, recordedRequest cassetteRequest) (*http.Response, error) {
+	base := c.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	res, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := readBody(&res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	recordedResponse := cassetteResponse{
+		StatusCode: res.StatusCode,
+		Headers:    map[string]string{},
+		Body:       redactJSON(body, cassetteRedactedFields),
+	}
+
+	for _, key := range cassetteHeaders {
+		if value := res.Header.Get(key); value != "" {
+			recordedResponse.Headers[key] = value
+		}
+	}
+
+	c.cassette.record(recordedRequest, recordedResponse)
+	return res, nil
+}
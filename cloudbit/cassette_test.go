@@ -0,0 +1,120 @@
+package cloudbit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/compute"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	api := fakeapi.New()
+	recorder := newRecordingCassette(path)
+
+	client := goclient.NewClient(
+		goclient.WithToken("secret-token"),
+		goclient.WithBase(api.URL()),
+		goclient.WithHTTPClientOption(func(c *http.Client) {
+			c.Transport = recorder.Transport(c.Transport)
+		}),
+	)
+
+	created, err := compute.NewKeyPairService(client).Create(ctx, compute.KeyPairCreate{
+		Name:      "test",
+		PublicKey: testCassettePublicKey,
+	})
+	if err != nil {
+		t.Fatalf("unable to create key pair: %s", err)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("unable to save cassette: %s", err)
+	}
+
+	api.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), testCassettePublicKey) {
+		t.Errorf("expected the cassette to be redacted, got %s", data)
+	}
+
+	player, err := loadCassette(path)
+	if err != nil {
+		t.Fatalf("unable to load cassette: %s", err)
+	}
+
+	client = goclient.NewClient(
+		goclient.WithBase(api.URL()),
+		goclient.WithHTTPClientOption(func(c *http.Client) {
+			c.Transport = player.Transport(c.Transport)
+		}),
+	)
+
+	// the public key does not need to match, as it is redacted
+	replayed, err := compute.NewKeyPairService(client).Create(ctx, compute.KeyPairCreate{
+		Name:      "test",
+		PublicKey: "ssh-ed25519 other",
+	})
+	if err != nil {
+		t.Fatalf("unable to replay key pair creation: %s", err)
+	}
+
+	if replayed != created {
+		t.Errorf("expected replayed key pair %+v, got %+v", created, replayed)
+	}
+
+	_, err = compute.NewKeyPairService(client).Create(ctx, compute.KeyPairCreate{Name: "other"})
+	if err == nil {
+		t.Errorf("expected an error for a request which has not been recorded")
+	}
+}
+
+const testCassettePublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGm1Pj3WbXnTm7EkVxZVf4ZQXJ2kmF5MxU5ZbEKzYv8J test"
+
+func TestCassette_RedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "certificate": "recorded-certificate", "kube_config": "recorded-kube-config"}`))
+	}))
+	defer server.Close()
+
+	recorder := newRecordingCassette(filepath.Join(t.TempDir(), "cassette.json"))
+	client := &http.Client{Transport: recorder.Transport(http.DefaultTransport)}
+
+	body := `{"certificate": "generated-certificate", "password": "secret-password"}`
+	res, err := client.Post(server.URL+"/v4/test", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unable to send request: %s", err)
+	}
+	_ = res.Body.Close()
+
+	interaction := recorder.Interactions[0]
+	for _, value := range []string{"generated-certificate", "secret-password"} {
+		if strings.Contains(interaction.Request.Body, value) {
+			t.Errorf("expected %q to be redacted in the request, got %s", value, interaction.Request.Body)
+		}
+	}
+
+	if strings.Contains(interaction.Response.Body, "recorded-kube-config") {
+		t.Errorf("expected the kube config to be redacted in the response, got %s", interaction.Response.Body)
+	}
+
+	if !strings.Contains(interaction.Response.Body, "recorded-certificate") {
+		t.Errorf("expected the certificate to be kept in the response, got %s", interaction.Response.Body)
+	}
+}
@@ -0,0 +1,186 @@
+package cloudbit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The credentials of the provider can be stored in a config file with named profiles, e.g.
+//
+//	default_profile: production
+//	profiles:
+//	  production:
+//	    token_command: [pass, show, cloudbit/production]
+//	  staging:
+//	    endpoint: https://api.staging.cloudbit.ch/
+//	    token_file: ~/.cloudbit/staging-token
+//
+// The attributes of the provider take precedence over a profile selected using the profile attribute or the
+// CLOUDBIT_PROFILE variable, followed by the CLOUDBIT_* variables and finally the default profile of the file.
+
+const defaultProfile = "default"
+
+type configFile struct {
+	DefaultProfile string                   `yaml:"default_profile"`
+	Profiles       map[string]configProfile `yaml:"profiles"`
+}
+
+type configProfile struct {
+	Endpoint        string   `yaml:"endpoint"`
+	DefaultLocation string   `yaml:"default_location"`
+	Token           string   `yaml:"token"`
+	TokenFile       string   `yaml:"token_file"`
+	TokenCommand    []string `yaml:"token_command"`
+}
+
+// defaultConfigFilePath returns the path of the config file in the user's config directory, which is
+// ~/.config/cloudbit/config.yaml unless XDG_CONFIG_HOME is set.
+func defaultConfigFilePath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "cloudbit", "config.yaml"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "cloudbit", "config.yaml"), nil
+}
+
+// loadConfigFile reads the config file at the given path. Relative token files of the profiles are resolved relative
+// to the directory of the config file.
+func loadConfigFile(path string) (configFile, error) {
+	var config configFile
+
+	path, err := expandHome(path)
+	if err != nil {
+		return config, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for name, profile := range config.Profiles {
+		if profile.TokenFile != "" && !filepath.IsAbs(profile.TokenFile) && !strings.HasPrefix(profile.TokenFile, "~") {
+			profile.TokenFile = filepath.Join(filepath.Dir(path), profile.TokenFile)
+			config.Profiles[name] = profile
+		}
+	}
+
+	return config, nil
+}
+
+// defaultProfileName returns the name of the profile used if none has been selected explicitly, or an empty string
+// if there is no such profile.
+func (c configFile) defaultProfileName() string {
+	if c.DefaultProfile != "" {
+		return c.DefaultProfile
+	}
+
+	if _, ok := c.Profiles[defaultProfile]; ok {
+		return defaultProfile
+	}
+
+	return ""
+}
+
+// token returns the token of the profile, or an empty string if the profile does not contain any token.
+func (c configProfile) token(ctx context.Context) (string, error) {
+	return resolveToken(ctx, c.Token, c.TokenFile, c.TokenCommand)
+}
+
+// resolveToken returns the token from one of the given sources, of which at most one may be set.
+func resolveToken(ctx context.Context, token string, tokenFile string, tokenCommand []string) (string, error) {
+	sources := 0
+	for _, set := range []bool{token != "", tokenFile != "", len(tokenCommand) != 0} {
+		if set {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		return "", errors.New("only one of token, token_file and token_command may be set")
+	}
+
+	switch {
+	case tokenFile != "":
+		return readTokenFile(tokenFile)
+	case len(tokenCommand) != 0:
+		return runTokenCommand(ctx, tokenCommand)
+	default:
+		return token, nil
+	}
+}
+
+func readTokenFile(path string) (string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+
+	return token, nil
+}
+
+// runTokenCommand runs an external credential helper, which prints the token to its standard output.
+func runTokenCommand(ctx context.Context, command []string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("running token command %s: %w: %s", command[0], err, msg)
+		}
+
+		return "", fmt.Errorf("running token command %s: %w", command[0], err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("token command %s did not print a token", command[0])
+	}
+
+	return token, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, path[1:]), nil
+}
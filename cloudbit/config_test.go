@@ -0,0 +1,207 @@
+package cloudbit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const testConfigFile = `
+profiles:
+  default:
+    endpoint: {{endpoint}}/default/
+    default_location: ALP1
+    token: default-token
+  production:
+    endpoint: {{endpoint}}/production/
+    token_file: production-token
+  staging:
+    token_command: [echo, staging-token]
+`
+
+func TestProvider_ConfigureCredentials(t *testing.T) {
+	var authorization, endpoint string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		endpoint = strings.TrimSuffix(r.URL.Path, "v4/test")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "cloudbit", "config.yaml"), strings.ReplaceAll(testConfigFile, "{{endpoint}}", server.URL))
+	writeTestFile(t, filepath.Join(dir, "cloudbit", "production-token"), "production-token\n")
+	writeTestFile(t, filepath.Join(dir, "token"), "file-token\n")
+
+	tests := []struct {
+		name     string
+		config   map[string]tftypes.Value
+		env      map[string]string
+		token    string
+		endpoint string
+		location string
+		err      string
+	}{
+		{
+			name:     "token attribute",
+			config:   map[string]tftypes.Value{"token": tftypes.NewValue(tftypes.String, "attribute-token")},
+			env:      map[string]string{"CLOUDBIT_TOKEN": "env-token", "CLOUDBIT_PROFILE": "production"},
+			token:    "attribute-token",
+			endpoint: "/fallback/",
+		},
+		{
+			name:     "token file attribute",
+			config:   map[string]tftypes.Value{"token_file": tftypes.NewValue(tftypes.String, filepath.Join(dir, "token"))},
+			token:    "file-token",
+			endpoint: "/fallback/",
+		},
+		{
+			name: "token command attribute",
+			config: map[string]tftypes.Value{"token_command": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+				tftypes.NewValue(tftypes.String, "echo"),
+				tftypes.NewValue(tftypes.String, "command-token"),
+			})},
+			token:    "command-token",
+			endpoint: "/fallback/",
+		},
+		{
+			name:     "profile attribute",
+			config:   map[string]tftypes.Value{"profile": tftypes.NewValue(tftypes.String, "production")},
+			env:      map[string]string{"CLOUDBIT_TOKEN": "env-token"},
+			token:    "production-token",
+			endpoint: "/production/",
+		},
+		{
+			name:     "profile variable",
+			env:      map[string]string{"CLOUDBIT_PROFILE": "staging", "CLOUDBIT_TOKEN": "env-token", "CLOUDBIT_ENDPOINT": "{{endpoint}}/env/"},
+			token:    "staging-token",
+			endpoint: "/env/",
+		},
+		{
+			name:     "environment before default profile",
+			env:      map[string]string{"CLOUDBIT_TOKEN": "env-token", "CLOUDBIT_ENDPOINT": "{{endpoint}}/env/"},
+			token:    "env-token",
+			endpoint: "/env/",
+		},
+		{
+			name:     "environment token without default profile endpoint",
+			env:      map[string]string{"CLOUDBIT_TOKEN": "env-token"},
+			token:    "env-token",
+			endpoint: "/fallback/",
+		},
+		{
+			name:     "default profile",
+			token:    "default-token",
+			endpoint: "/default/",
+			location: "ALP1",
+		},
+		{
+			name:   "unknown profile",
+			config: map[string]tftypes.Value{"profile": tftypes.NewValue(tftypes.String, "unknown")},
+			err:    "Unknown Profile",
+		},
+		{
+			name:   "missing config file",
+			config: map[string]tftypes.Value{"config_file": tftypes.NewValue(tftypes.String, filepath.Join(dir, "missing.yaml"))},
+			err:    "Invalid Config File",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", dir)
+			for _, key := range []string{"CLOUDBIT_TOKEN", "CLOUDBIT_PROFILE", "CLOUDBIT_CONFIG_FILE", "CLOUDBIT_ENDPOINT"} {
+				t.Setenv(key, "")
+				_ = os.Unsetenv(key)
+			}
+
+			for key, value := range test.env {
+				t.Setenv(key, strings.ReplaceAll(value, "{{endpoint}}", server.URL))
+			}
+
+			p := New(WithDefaultEndpoint(server.URL + "/fallback/")).(*provider)
+			response := testConfigureProvider(t, p, test.config)
+
+			if test.err != "" {
+				if !response.Diagnostics.HasError() || response.Diagnostics.Errors()[0].Summary() != test.err {
+					t.Fatalf("expected error %q, got %v", test.err, response.Diagnostics)
+				}
+
+				return
+			}
+
+			if response.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", response.Diagnostics)
+			}
+
+			err := p.client.Get(context.Background(), "v4/test", nil)
+			if err != nil {
+				t.Fatalf("unable to send request: %s", err)
+			}
+
+			if authorization != "Bearer "+test.token {
+				t.Errorf("expected token %q, got authorization %q", test.token, authorization)
+			}
+
+			if endpoint != test.endpoint {
+				t.Errorf("expected endpoint %q, got %q", test.endpoint, endpoint)
+			}
+
+			if p.defaultLocation != test.location {
+				t.Errorf("expected default location %q, got %q", test.location, p.defaultLocation)
+			}
+		})
+	}
+}
+
+func TestResolveToken_Conflict(t *testing.T) {
+	_, err := resolveToken(context.Background(), "token", "token-file", nil)
+	if err == nil {
+		t.Fatal("expected an error for multiple token sources")
+	}
+}
+
+func testConfigureProvider(t *testing.T, p *provider, values map[string]tftypes.Value) *tfsdk.ConfigureProviderResponse {
+	ctx := context.Background()
+
+	schema, diagnostics := p.GetSchema(ctx)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to get schema: %v", diagnostics)
+	}
+
+	typ := schema.TerraformType(ctx).(tftypes.Object)
+
+	attributes := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, attributeType := range typ.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+		}
+	}
+
+	request := tfsdk.ConfigureProviderRequest{
+		Config: tfsdk.Config{Schema: schema, Raw: tftypes.NewValue(typ, attributes)},
+	}
+
+	response := &tfsdk.ConfigureProviderResponse{}
+	p.Configure(ctx, request, response)
+
+	return response
+}
+
+func writeTestFile(t *testing.T, name string, content string) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatalf("unable to create directory: %s", err)
+	}
+
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+}
@@ -219,6 +219,9 @@ type computeCertificateDataSource struct {
 }
 
 func (c computeCertificateDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_certificate")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeCertificateDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -228,7 +231,7 @@ func (c computeCertificateDataSource) Read(ctx context.Context, request tfsdk.Re
 
 	list, err := c.certificateService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list certificates: %s", err))
+		addClientError(&response.Diagnostics, "unable to list certificates", err)
 		return
 	}
 
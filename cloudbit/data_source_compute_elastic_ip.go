@@ -137,6 +137,9 @@ type computeElasticIPDataSource struct {
 }
 
 func (c computeElasticIPDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_elastic_ip")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeElasticIPDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -146,7 +149,7 @@ func (c computeElasticIPDataSource) Read(ctx context.Context, request tfsdk.Read
 
 	list, err := c.elasticIPService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list elastic ips: %s", err))
+		addClientError(&response.Diagnostics, "unable to list elastic ips", err)
 		return
 	}
 
@@ -138,6 +138,9 @@ type computeImageDataSource struct {
 }
 
 func (i computeImageDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_image")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeImageDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -147,7 +150,7 @@ func (i computeImageDataSource) Read(ctx context.Context, request tfsdk.ReadData
 
 	list, err := i.imageService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get images: %s", err))
+		addClientError(&response.Diagnostics, "unable to get images", err)
 		return
 	}
 
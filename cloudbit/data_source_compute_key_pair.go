@@ -89,6 +89,9 @@ type computeKeyPairDataSource struct {
 }
 
 func (s computeKeyPairDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_key_pair")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeKeyPairDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -98,7 +101,7 @@ func (s computeKeyPairDataSource) Read(ctx context.Context, request tfsdk.ReadDa
 
 	list, err := s.keyPairService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list key pairs: %s", err))
+		addClientError(&response.Diagnostics, "unable to list key pairs", err)
 		return
 	}
 
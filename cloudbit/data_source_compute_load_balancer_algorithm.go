@@ -89,6 +89,9 @@ type computeLoadBalancerAlgorithmDataSource struct {
 }
 
 func (c computeLoadBalancerAlgorithmDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_load_balancer_algorithm")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerAlgorithmDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -98,7 +101,7 @@ func (c computeLoadBalancerAlgorithmDataSource) Read(ctx context.Context, reques
 
 	list, err := c.loadBalancerEntityService.ListAlgorithms(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list load balancer algorithms: %s", err))
+		addClientError(&response.Diagnostics, "unable to list load balancer algorithms", err)
 		return
 	}
 
@@ -89,6 +89,9 @@ type computeLoadBalancerHealthCheckTypeDataSource struct {
 }
 
 func (c computeLoadBalancerHealthCheckTypeDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_load_balancer_health_check_type")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerHealthCheckTypeDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -98,7 +101,7 @@ func (c computeLoadBalancerHealthCheckTypeDataSource) Read(ctx context.Context,
 
 	list, err := c.loadBalancerEntityService.ListHealthCheckTypes(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list load balancer health check types: %s", err))
+		addClientError(&response.Diagnostics, "unable to list load balancer health check types", err)
 		return
 	}
 
@@ -120,6 +120,9 @@ type computeLoadBalancerMemberDataSource struct {
 }
 
 func (c computeLoadBalancerMemberDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_load_balancer_member")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerMemberDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -132,7 +135,7 @@ func (c computeLoadBalancerMemberDataSource) Read(ctx context.Context, request t
 
 	list, err := c.loadBalancerService.Pools(loadBalancerID).Members(poolID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list load balancer members: %s", err))
+		addClientError(&response.Diagnostics, "unable to list load balancer members", err)
 		return
 	}
 
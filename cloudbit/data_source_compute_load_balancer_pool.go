@@ -237,6 +237,9 @@ type computeLoadBalancerPoolDataSource struct {
 }
 
 func (c computeLoadBalancerPoolDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_load_balancer_pool")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerPoolDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -248,7 +251,7 @@ func (c computeLoadBalancerPoolDataSource) Read(ctx context.Context, request tfs
 
 	list, err := c.loadBalancerService.Pools(loadBalancerID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get load balancer pool: %s", err))
+		addClientError(&response.Diagnostics, "unable to get load balancer pool", err)
 		return
 	}
 
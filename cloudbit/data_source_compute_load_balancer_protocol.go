@@ -89,6 +89,9 @@ type computeLoadBalancerProtocolDataSource struct {
 }
 
 func (c computeLoadBalancerProtocolDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_load_balancer_protocol")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerProtocolDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -98,7 +101,7 @@ func (c computeLoadBalancerProtocolDataSource) Read(ctx context.Context, request
 
 	list, err := c.loadBalancerEntityService.ListProtocols(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list load balancer protocols: %s", err))
+		addClientError(&response.Diagnostics, "unable to list load balancer protocols", err)
 		return
 	}
 
@@ -138,6 +138,9 @@ type computeNetworkDataSource struct {
 }
 
 func (c computeNetworkDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_network")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeNetworkDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -147,7 +150,7 @@ func (c computeNetworkDataSource) Read(ctx context.Context, request tfsdk.ReadDa
 
 	list, err := c.networkService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list networks: %s", err))
+		addClientError(&response.Diagnostics, "unable to list networks", err)
 		return
 	}
 
@@ -0,0 +1,306 @@
+package cloudbit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ tfsdk.DataSourceType = (*computeNetworkAddressesDataSourceType)(nil)
+	_ tfsdk.DataSource     = (*computeNetworkAddressesDataSource)(nil)
+)
+
+const (
+	networkAddressTypeGateway      = "gateway"
+	networkAddressTypeServer       = "server"
+	networkAddressTypeRouter       = "router"
+	networkAddressTypeLoadBalancer = "load_balancer"
+
+	networkAddressesDefaultFreeCount = 1
+)
+
+type computeNetworkUsedAddressData struct {
+	Address types.String `tfsdk:"address"`
+	Type    types.String `tfsdk:"type"`
+	ID      types.Int64  `tfsdk:"id"`
+	Name    types.String `tfsdk:"name"`
+}
+
+type computeNetworkAddressesDataSourceData struct {
+	NetworkID     types.Int64                     `tfsdk:"network_id"`
+	FreeCount     types.Int64                     `tfsdk:"free_count"`
+	CIDR          types.String                    `tfsdk:"cidr"`
+	UsedAddresses []computeNetworkUsedAddressData `tfsdk:"used_addresses"`
+	FreeAddresses []types.String                  `tfsdk:"free_addresses"`
+}
+
+type computeNetworkAddressesDataSourceType struct{}
+
+func (c computeNetworkAddressesDataSourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"network_id": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "unique identifier of the network",
+				Required:            true,
+			},
+			"free_count": {
+				Type:                types.Int64Type,
+				MarkdownDescription: fmt.Sprintf("number of free addresses to return. Defaults to `%d`", networkAddressesDefaultFreeCount),
+				Optional:            true,
+				Computed:            true,
+			},
+			"cidr": {
+				Type:                types.StringType,
+				MarkdownDescription: "CIDR of the network",
+				Computed:            true,
+			},
+			"used_addresses": {
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"address": {
+						Type:                types.StringType,
+						MarkdownDescription: "IP address in use",
+						Computed:            true,
+					},
+					"type": {
+						Type:                types.StringType,
+						MarkdownDescription: "type of the entity using the address. One of `gateway`, `server`, `router` or `load_balancer`",
+						Computed:            true,
+					},
+					"id": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "unique identifier of the entity using the address",
+						Computed:            true,
+					},
+					"name": {
+						Type:                types.StringType,
+						MarkdownDescription: "name of the entity using the address",
+						Computed:            true,
+					},
+				}),
+				MarkdownDescription: "addresses of the network which are in use, ordered by address",
+				Computed:            true,
+			},
+			"free_addresses": {
+				Type: types.ListType{
+					ElemType: types.StringType,
+				},
+				MarkdownDescription: "the next free addresses within the allocation pool of the network",
+				Computed:            true,
+			},
+		},
+	}, nil
+}
+
+func (c computeNetworkAddressesDataSourceType) NewDataSource(ctx context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	prov, diagnostics := convertToLocalProviderType(p)
+	if diagnostics.HasError() {
+		return nil, diagnostics
+	}
+
+	return computeNetworkAddressesDataSource{
+		networkService:      compute.NewNetworkService(prov.client),
+		serverService:       compute.NewServerService(prov.client),
+		routerService:       compute.NewRouterService(prov.client),
+		loadBalancerService: compute.NewLoadBalancerService(prov.client),
+	}, diagnostics
+}
+
+type computeNetworkAddressesDataSource struct {
+	networkService      compute.NetworkService
+	serverService       compute.ServerService
+	routerService       compute.RouterService
+	loadBalancerService compute.LoadBalancerService
+}
+
+func (c computeNetworkAddressesDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_network_addresses")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
+	var config computeNetworkAddressesDataSourceData
+	diagnostics := request.Config.Get(ctx, &config)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	freeCount := networkAddressesDefaultFreeCount
+	if !config.FreeCount.Null {
+		freeCount = int(config.FreeCount.Value)
+	}
+
+	if freeCount < 0 {
+		response.Diagnostics.AddError("Invalid Free Count", fmt.Sprintf("free_count must not be negative, got %d", freeCount))
+		return
+	}
+
+	network, err := c.networkService.Get(ctx, int(config.NetworkID.Value))
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to get network", err)
+		return
+	}
+
+	used, diagnostics := c.listUsedAddresses(ctx, network)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	free, err := findFreeAddresses(network.AllocationPoolStart, network.AllocationPoolEnd, used, freeCount)
+	if err != nil {
+		response.Diagnostics.AddError("Invalid Allocation Pool", fmt.Sprintf("unable to find free addresses in network %d: %s", network.ID, err))
+		return
+	}
+
+	state := computeNetworkAddressesDataSourceData{
+		NetworkID:     types.Int64{Value: int64(network.ID)},
+		FreeCount:     types.Int64{Value: int64(freeCount)},
+		CIDR:          types.String{Value: network.CIDR},
+		UsedAddresses: used,
+		FreeAddresses: make([]types.String, len(free)),
+	}
+
+	for i, address := range free {
+		state.FreeAddresses[i] = types.String{Value: address}
+	}
+
+	diagnostics = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diagnostics...)
+}
+
+func (c computeNetworkAddressesDataSource) listUsedAddresses(ctx context.Context, network compute.Network) (used []computeNetworkUsedAddressData, diagnostics diag.Diagnostics) {
+	add := func(address string, typ string, id int, name string) {
+		if address == "" {
+			return
+		}
+
+		used = append(used, computeNetworkUsedAddressData{
+			Address: types.String{Value: address},
+			Type:    types.String{Value: typ},
+			ID:      types.Int64{Value: int64(id)},
+			Name:    types.String{Value: name},
+		})
+	}
+
+	add(network.GatewayIP, networkAddressTypeGateway, network.ID, network.Name)
+
+	servers, err := c.serverService.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&diagnostics, "unable to list servers", err)
+		return
+	}
+
+	for _, server := range servers.Items {
+		for _, attachment := range server.Networks {
+			if attachment.ID != network.ID {
+				continue
+			}
+
+			for _, iface := range attachment.Interfaces {
+				add(iface.PrivateIP, networkAddressTypeServer, server.ID, server.Name)
+			}
+		}
+	}
+
+	routers, err := c.routerService.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&diagnostics, "unable to list routers", err)
+		return
+	}
+
+	for _, router := range routers.Items {
+		if router.Location.ID != network.Location.ID {
+			continue
+		}
+
+		interfaces, err := c.routerService.RouterInterfaces(router.ID).List(ctx, goclient.Cursor{NoFilter: 1})
+		if err != nil {
+			addClientError(&diagnostics, "unable to list router interfaces", err)
+			return
+		}
+
+		for _, routerInterface := range interfaces.Items {
+			if routerInterface.Network.ID == network.ID {
+				add(routerInterface.PrivateIP, networkAddressTypeRouter, router.ID, router.Name)
+			}
+		}
+	}
+
+	loadBalancers, err := c.loadBalancerService.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&diagnostics, "unable to list load balancers", err)
+		return
+	}
+
+	for _, loadBalancer := range loadBalancers.Items {
+		for _, attachment := range loadBalancer.Networks {
+			if attachment.ID != network.ID {
+				continue
+			}
+
+			for _, iface := range attachment.Interfaces {
+				add(iface.PrivateIP, networkAddressTypeLoadBalancer, loadBalancer.ID, loadBalancer.Name)
+			}
+		}
+	}
+
+	sort.SliceStable(used, func(i, j int) bool {
+		return bytes.Compare(net.ParseIP(used[i].Address.Value), net.ParseIP(used[j].Address.Value)) < 0
+	})
+
+	if used == nil {
+		used = []computeNetworkUsedAddressData{}
+	}
+
+	return
+}
+
+// findFreeAddresses returns up to count addresses between start and end (inclusive) which are not in use.
+func findFreeAddresses(start, end string, used []computeNetworkUsedAddressData, count int) ([]string, error) {
+	first, last := net.ParseIP(start), net.ParseIP(end)
+	if first == nil || last == nil || (first.To4() == nil) != (last.To4() == nil) {
+		return nil, fmt.Errorf("invalid allocation pool %s - %s", start, end)
+	}
+
+	taken := make(map[string]bool, len(used))
+	for _, address := range used {
+		taken[net.ParseIP(address.Address.Value).String()] = true
+	}
+
+	free := make([]string, 0, count)
+	for ip := first; len(free) < count && bytes.Compare(ip, last) <= 0; ip = nextIP(ip) {
+		if !taken[ip.String()] {
+			free = append(free, ip.String())
+		}
+
+		// the address after the end of the pool may wrap around to the start of the address space
+		if ip.Equal(last) {
+			break
+		}
+	}
+
+	return free, nil
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+
+	return next
+}
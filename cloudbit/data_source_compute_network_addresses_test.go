@@ -0,0 +1,90 @@
+package cloudbit
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestFindFreeAddresses(t *testing.T) {
+	used := func(addresses ...string) []computeNetworkUsedAddressData {
+		data := make([]computeNetworkUsedAddressData, len(addresses))
+		for i, address := range addresses {
+			data[i] = computeNetworkUsedAddressData{Address: types.String{Value: address}}
+		}
+		return data
+	}
+
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		used  []computeNetworkUsedAddressData
+		count int
+		free  []string
+		err   bool
+	}{
+		{name: "empty pool", start: "10.0.0.10", end: "10.0.0.20", count: 2, free: []string{"10.0.0.10", "10.0.0.11"}},
+		{name: "used addresses", start: "10.0.0.10", end: "10.0.0.20", used: used("10.0.0.10", "10.0.0.12"), count: 2, free: []string{"10.0.0.11", "10.0.0.13"}},
+		{name: "used addresses outside the pool", start: "10.0.0.10", end: "10.0.0.20", used: used("10.0.0.1"), count: 1, free: []string{"10.0.0.10"}},
+		{name: "end of the pool", start: "10.0.0.253", end: "10.0.0.255", used: used("10.0.0.254"), count: 5, free: []string{"10.0.0.253", "10.0.0.255"}},
+		{name: "across octets", start: "10.0.0.255", end: "10.0.1.1", count: 3, free: []string{"10.0.0.255", "10.0.1.0", "10.0.1.1"}},
+		{name: "count larger than the free space", start: "10.0.0.10", end: "10.0.0.12", used: used("10.0.0.11"), count: 10, free: []string{"10.0.0.10", "10.0.0.12"}},
+		{name: "full pool", start: "10.0.0.10", end: "10.0.0.11", used: used("10.0.0.10", "10.0.0.11"), count: 1, free: []string{}},
+		{name: "zero count", start: "10.0.0.10", end: "10.0.0.20", count: 0, free: []string{}},
+		{name: "ipv6", start: "2001:db8::fffe", end: "2001:db8::1:1", used: used("2001:db8::ffff"), count: 3, free: []string{"2001:db8::fffe", "2001:db8::1:0", "2001:db8::1:1"}},
+		{name: "ipv6 end of the address space", start: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", end: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", count: 5, free: []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}},
+		{name: "invalid start", start: "foo", end: "10.0.0.20", count: 1, err: true},
+		{name: "mixed families", start: "10.0.0.10", end: "2001:db8::1", count: 1, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			free, err := findFreeAddresses(test.start, test.end, test.used, test.count)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", free)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(free, test.free) {
+				t.Errorf("expected %v, got %v", test.free, free)
+			}
+		})
+	}
+}
+
+func TestNextIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		next string
+	}{
+		{ip: "10.0.0.1", next: "10.0.0.2"},
+		{ip: "10.0.0.255", next: "10.0.1.0"},
+		{ip: "10.255.255.255", next: "11.0.0.0"},
+		{ip: "2001:db8::1", next: "2001:db8::2"},
+		{ip: "2001:db8::ffff", next: "2001:db8::1:0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ip, func(t *testing.T) {
+			ip := net.ParseIP(test.ip)
+			next := nextIP(ip)
+
+			if next.String() != test.next {
+				t.Errorf("expected %s, got %s", test.next, next)
+			}
+
+			if ip.String() != test.ip {
+				t.Errorf("expected the ip to be left unchanged, got %s", ip)
+			}
+		})
+	}
+}
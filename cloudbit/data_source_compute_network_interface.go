@@ -132,6 +132,9 @@ type computeNetworkInterfaceDataSource struct {
 }
 
 func (c computeNetworkInterfaceDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_network_interface")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeNetworkInterfaceDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -143,7 +146,7 @@ func (c computeNetworkInterfaceDataSource) Read(ctx context.Context, request tfs
 
 	list, err := c.serverService.NetworkInterfaces(serverID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list network interfaces: %s", err))
+		addClientError(&response.Diagnostics, "unable to list network interfaces", err)
 		return
 	}
 
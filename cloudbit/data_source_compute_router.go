@@ -24,13 +24,32 @@ type computeRouterDataSourceData struct {
 	LocationID types.Int64  `tfsdk:"location_id"`
 	Public     types.Bool   `tfsdk:"public"`
 	PublicIP   types.String `tfsdk:"public_ip"`
+
+	Interfaces []computeRouterInterfaceData `tfsdk:"interfaces"`
+	Routes     []computeRouterRouteData     `tfsdk:"routes"`
 }
 
-func (c *computeRouterDataSourceData) FromEntity(router compute.Router) {
+func (c *computeRouterDataSourceData) FromEntity(router compute.Router, interfaces []compute.RouterInterface, routes []compute.Route) {
 	c.ID = types.Int64{Value: int64(router.ID)}
 	c.Name = types.String{Value: router.Name}
 	c.LocationID = types.Int64{Value: int64(router.Location.ID)}
 	c.Public = types.Bool{Value: router.Public}
+
+	if router.Public {
+		c.PublicIP = types.String{Value: router.PublicIP}
+	} else {
+		c.PublicIP = types.String{Null: true}
+	}
+
+	c.Interfaces = make([]computeRouterInterfaceData, len(interfaces))
+	for i, routerInterface := range interfaces {
+		c.Interfaces[i].FromEntity(routerInterface)
+	}
+
+	c.Routes = make([]computeRouterRouteData, len(routes))
+	for i, route := range routes {
+		c.Routes[i].FromEntity(route)
+	}
 }
 
 func (c computeRouterDataSourceData) AppliesTo(router compute.Router) bool {
@@ -77,6 +96,16 @@ func (c computeRouterDataSourceType) GetSchema(ctx context.Context) (tfsdk.Schem
 				MarkdownDescription: "public IP of the router",
 				Computed:            true,
 			},
+			"interfaces": {
+				MarkdownDescription: "interfaces attached to the router",
+				Computed:            true,
+				Attributes:          tfsdk.ListNestedAttributes(computeRouterInterfaceAttributes()),
+			},
+			"routes": {
+				MarkdownDescription: "static routes of the router",
+				Computed:            true,
+				Attributes:          tfsdk.ListNestedAttributes(computeRouterRouteAttributes()),
+			},
 		},
 	}, nil
 }
@@ -97,6 +126,9 @@ type computeRouterDataSource struct {
 }
 
 func (c computeRouterDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_router")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeRouterDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -106,7 +138,7 @@ func (c computeRouterDataSource) Read(ctx context.Context, request tfsdk.ReadDat
 
 	list, err := c.routerService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list routers: %s", err))
+		addClientError(&response.Diagnostics, "unable to list routers", err)
 		return
 	}
 
@@ -116,8 +148,14 @@ func (c computeRouterDataSource) Read(ctx context.Context, request tfsdk.ReadDat
 		return
 	}
 
+	interfaces, routes, diagnostics := listComputeRouterNetworking(ctx, c.routerService, router.ID)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	var state computeRouterDataSourceData
-	state.FromEntity(router)
+	state.FromEntity(router, interfaces, routes)
 
 	diagnostics = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diagnostics...)
@@ -96,6 +96,9 @@ type computeRouterInterfaceDataSource struct {
 }
 
 func (c computeRouterInterfaceDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_router_interface")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeRouterInterfaceDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -106,7 +109,7 @@ func (c computeRouterInterfaceDataSource) Read(ctx context.Context, request tfsd
 	routerID := int(config.RouterID.Value)
 	list, err := compute.NewRouterInterfaceService(c.client, routerID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list router interfaces: %s", err))
+		addClientError(&response.Diagnostics, "unable to list router interfaces", err)
 		return
 	}
 
@@ -96,6 +96,9 @@ type computeRouterRouteDataSource struct {
 }
 
 func (c computeRouterRouteDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_router_route")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeRouterRouteDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -106,7 +109,7 @@ func (c computeRouterRouteDataSource) Read(ctx context.Context, request tfsdk.Re
 	routerID := int(config.RouterID.Value)
 	list, err := compute.NewRouteService(c.client, routerID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list routes: %s", err))
+		addClientError(&response.Diagnostics, "unable to list routes", err)
 		return
 	}
 
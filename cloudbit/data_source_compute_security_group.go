@@ -89,6 +89,9 @@ type computeSecurityGroupDataSource struct {
 }
 
 func (c computeSecurityGroupDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_security_group")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeSecurityGroupDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -98,7 +101,7 @@ func (c computeSecurityGroupDataSource) Read(ctx context.Context, request tfsdk.
 
 	list, err := c.securityGroupService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list security groups: %s", err))
+		addClientError(&response.Diagnostics, "unable to list security groups", err)
 		return
 	}
 
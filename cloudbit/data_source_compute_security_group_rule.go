@@ -193,6 +193,9 @@ type computeSecurityGroupRuleDataSource struct {
 }
 
 func (c computeSecurityGroupRuleDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_security_group_rule")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeSecurityGroupRuleDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -205,7 +208,7 @@ func (c computeSecurityGroupRuleDataSource) Read(ctx context.Context, request tf
 
 	list, err := c.securityGroupService.Rules(securityGroupID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list security group rules: %s", err))
+		addClientError(&response.Diagnostics, "unable to list security group rules", err)
 		return
 	}
 
@@ -128,6 +128,9 @@ type computeServerDataSource struct {
 }
 
 func (c computeServerDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_server")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeServerDataSourceData
 	response.Diagnostics.Append(request.Config.Get(ctx, &config)...)
 	if response.Diagnostics.HasError() {
@@ -136,7 +139,7 @@ func (c computeServerDataSource) Read(ctx context.Context, request tfsdk.ReadDat
 
 	servers, err := c.serverService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get server: %s", err))
+		addClientError(&response.Diagnostics, "unable to get server", err)
 		return
 	}
 
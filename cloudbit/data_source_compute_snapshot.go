@@ -106,6 +106,9 @@ type computeSnapshotDataSource struct {
 }
 
 func (c computeSnapshotDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_snapshot")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeSnapshotDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -115,7 +118,7 @@ func (c computeSnapshotDataSource) Read(ctx context.Context, request tfsdk.ReadD
 
 	list, err := c.snapshotService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list snapshots: %s", err))
+		addClientError(&response.Diagnostics, "unable to list snapshots", err)
 		return
 	}
 
@@ -108,6 +108,9 @@ type computeVolumeDataSource struct {
 }
 
 func (c computeVolumeDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_compute_volume")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeVolumeDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -117,7 +120,7 @@ func (c computeVolumeDataSource) Read(ctx context.Context, request tfsdk.ReadDat
 
 	list, err := c.volumeService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list volumes: %s", err))
+		addClientError(&response.Diagnostics, "unable to list volumes", err)
 		return
 	}
 
@@ -0,0 +1,329 @@
+package cloudbit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ tfsdk.DataSourceType               = (*costEstimateDataSourceType)(nil)
+	_ tfsdk.DataSource                   = (*costEstimateDataSource)(nil)
+	_ tfsdk.DataSourceWithValidateConfig = (*costEstimateDataSource)(nil)
+)
+
+// productTypeElasticIP is the key of the product type of elastic ips, as listed by `GET /v4/entities/product-types`.
+// It appears in the product responses recorded by the goclient (flow/product_test.go). There is no such evidence for
+// the product type of volumes, so volume_product_id has to be configured explicitly instead.
+const productTypeElasticIP = "compute-engine-elastic-ip"
+
+type costEstimateProductData struct {
+	ProductID types.Int64 `tfsdk:"product_id"`
+	Quantity  types.Int64 `tfsdk:"quantity"`
+	Hours     types.Int64 `tfsdk:"hours"`
+}
+
+type costEstimateItemData struct {
+	ProductID   types.Int64   `tfsdk:"product_id"`
+	Name        types.String  `tfsdk:"name"`
+	Quantity    types.Int64   `tfsdk:"quantity"`
+	Hours       types.Int64   `tfsdk:"hours"`
+	MonthlyCost types.Float64 `tfsdk:"monthly_cost"`
+}
+
+type costEstimateDataSourceData struct {
+	Products []costEstimateProductData `tfsdk:"products"`
+
+	VolumeSize         types.Int64 `tfsdk:"volume_size"`
+	VolumeProductID    types.Int64 `tfsdk:"volume_product_id"`
+	ElasticIPCount     types.Int64 `tfsdk:"elastic_ip_count"`
+	ElasticIPProductID types.Int64 `tfsdk:"elastic_ip_product_id"`
+
+	Items            []costEstimateItemData `tfsdk:"items"`
+	TotalMonthlyCost types.Float64          `tfsdk:"total_monthly_cost"`
+}
+
+type costEstimateDataSourceType struct{}
+
+func (c costEstimateDataSourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"products": {
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"product_id": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "unique identifier of the product",
+						Required:            true,
+					},
+					"quantity": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "number of units of the product. Defaults to `1`",
+						Optional:            true,
+						Computed:            true,
+					},
+					"hours": {
+						Type:                types.Int64Type,
+						MarkdownDescription: fmt.Sprintf("hours per month the product is in use. Defaults to `%d`", hoursPerMonth),
+						Optional:            true,
+						Computed:            true,
+					},
+				}),
+				MarkdownDescription: "products to include in the estimate",
+				Optional:            true,
+			},
+			"volume_size": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "total size of all volumes in GiB",
+				Optional:            true,
+			},
+			"volume_product_id": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "unique identifier of the product used to price volumes, required if `volume_size` is set. The price per GiB is the price of the product divided by the amount of its `Storage` item, or the price of the product itself if it has no such item",
+				Optional:            true,
+			},
+			"elastic_ip_count": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "number of elastic ips",
+				Optional:            true,
+			},
+			"elastic_ip_product_id": {
+				Type:                types.Int64Type,
+				MarkdownDescription: fmt.Sprintf("unique identifier of the product used to price elastic ips. Defaults to the most expensive product of type `%s`, so that the estimate is not based on a limited free tier. A warning is shown if the default is used, as it may overestimate the cost", productTypeElasticIP),
+				Optional:            true,
+				Computed:            true,
+			},
+			"items": {
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"product_id": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "unique identifier of the product",
+						Computed:            true,
+					},
+					"name": {
+						Type:                types.StringType,
+						MarkdownDescription: "name of the product",
+						Computed:            true,
+					},
+					"quantity": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "number of units of the product, in GiB for volumes",
+						Computed:            true,
+					},
+					"hours": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "hours per month the product is in use",
+						Computed:            true,
+					},
+					"monthly_cost": {
+						Type:                types.Float64Type,
+						MarkdownDescription: "estimated cost of the item per month",
+						Computed:            true,
+					},
+				}),
+				MarkdownDescription: "estimated cost per item, in the order of `products` followed by volumes and elastic ips",
+				Computed:            true,
+			},
+			"total_monthly_cost": {
+				Type:                types.Float64Type,
+				MarkdownDescription: "estimated total cost per month",
+				Computed:            true,
+			},
+		},
+	}, nil
+}
+
+func (c costEstimateDataSourceType) NewDataSource(ctx context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	prov, diagnostics := convertToLocalProviderType(p)
+	if diagnostics.HasError() {
+		return nil, diagnostics
+	}
+
+	return costEstimateDataSource{
+		productService: common.NewProductService(prov.client),
+	}, diagnostics
+}
+
+type costEstimateDataSource struct {
+	productService common.ProductService
+}
+
+func (c costEstimateDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_cost_estimate")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
+	var config costEstimateDataSourceData
+	diagnostics := request.Config.Get(ctx, &config)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	list, err := c.productService.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to get products", err)
+		return
+	}
+
+	products := make(map[int]common.Product, len(list.Items))
+	for _, product := range list.Items {
+		products[product.ID] = product
+	}
+
+	state := costEstimateDataSourceData{
+		Products:           config.Products,
+		VolumeSize:         config.VolumeSize,
+		VolumeProductID:    config.VolumeProductID,
+		ElasticIPCount:     config.ElasticIPCount,
+		ElasticIPProductID: config.ElasticIPProductID,
+		Items:              []costEstimateItemData{},
+	}
+
+	for i := range state.Products {
+		item := &state.Products[i]
+
+		product, found := products[int(item.ProductID.Value)]
+		if !found {
+			response.Diagnostics.AddError("Not Found", fmt.Sprintf("unable to find product with id %d", item.ProductID.Value))
+			return
+		}
+
+		if item.Quantity.Null {
+			item.Quantity = types.Int64{Value: 1}
+		}
+
+		if item.Hours.Null {
+			item.Hours = types.Int64{Value: hoursPerMonth}
+		}
+
+		state.addItem(product, item.Quantity.Value, item.Hours.Value, productPricePerHour(product))
+	}
+
+	if !config.VolumeSize.Null && config.VolumeSize.Value != 0 {
+		product, found := products[int(config.VolumeProductID.Value)]
+		if !found {
+			response.Diagnostics.AddError("Not Found", fmt.Sprintf("unable to find volume product with id %d", config.VolumeProductID.Value))
+			return
+		}
+
+		state.addItem(product, config.VolumeSize.Value, hoursPerMonth, volumePricePerGiBHour(product))
+	}
+
+	if !config.ElasticIPCount.Null && config.ElasticIPCount.Value != 0 {
+		product, err := findPricingProduct(config.ElasticIPProductID, productTypeElasticIP, list.Items)
+		if err != nil {
+			response.Diagnostics.AddError("Not Found", fmt.Sprintf("unable to find elastic ip product: %s", err))
+			return
+		}
+
+		if config.ElasticIPProductID.Null {
+			response.Diagnostics.AddAttributeWarning(
+				path.Root("elastic_ip_product_id"),
+				"Default Pricing Product",
+				fmt.Sprintf("The elastic ips are priced using %s (%d), the most expensive product of type %s, which may overestimate their cost. Configure elastic_ip_product_id to price them using another product.", product.Name, product.ID, productTypeElasticIP),
+			)
+		}
+
+		state.ElasticIPProductID = types.Int64{Value: int64(product.ID)}
+		state.addItem(product, config.ElasticIPCount.Value, hoursPerMonth, productPricePerHour(product))
+	} else if config.ElasticIPProductID.Null {
+		state.ElasticIPProductID = types.Int64{Null: true}
+	}
+
+	diagnostics = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diagnostics...)
+}
+
+func (c costEstimateDataSource) ValidateConfig(ctx context.Context, request tfsdk.ValidateDataSourceConfigRequest, response *tfsdk.ValidateDataSourceConfigResponse) {
+	var volumeSize, volumeProductID types.Int64
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, path.Root("volume_size"), &volumeSize)...)
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, path.Root("volume_product_id"), &volumeProductID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if volumeSize.Null || volumeSize.Unknown || volumeSize.Value == 0 || !volumeProductID.Null {
+		return
+	}
+
+	response.Diagnostics.AddAttributeError(
+		path.Root("volume_product_id"),
+		"Missing Volume Product",
+		"volume_product_id is required if volume_size is set, as the product used to price volumes cannot be determined automatically.",
+	)
+}
+
+func (c *costEstimateDataSourceData) addItem(product common.Product, quantity int64, hours int64, pricePerHour float64) {
+	cost := pricePerHour * float64(quantity) * float64(hours)
+
+	c.Items = append(c.Items, costEstimateItemData{
+		ProductID:   types.Int64{Value: int64(product.ID)},
+		Name:        types.String{Value: product.Name},
+		Quantity:    types.Int64{Value: quantity},
+		Hours:       types.Int64{Value: hours},
+		MonthlyCost: types.Float64{Value: cost},
+	})
+
+	c.TotalMonthlyCost = types.Float64{Value: c.TotalMonthlyCost.Value + cost}
+}
+
+// volumePricePerGiBHour returns the price of a single GiB of the volume product per hour. If the product includes a
+// `Storage` item, its price is assumed to cover the amount of that item, otherwise a single GiB.
+func volumePricePerGiBHour(product common.Product) float64 {
+	pricePerHour := productPricePerHour(product)
+	if size := productItemAmount(product, "Storage"); size > 1 {
+		pricePerHour /= float64(size)
+	}
+
+	return pricePerHour
+}
+
+// findPricingProduct returns the product with the given id or, if no id is configured, the most expensive product of
+// the given type. The most expensive product is used to avoid underestimating the cost, as cheaper products are usually
+// limited free tiers.
+func findPricingProduct(productID types.Int64, productType string, products []common.Product) (common.Product, error) {
+	config := productDataSourceData{
+		ID:         productID,
+		Name:       types.String{Null: true},
+		Type:       types.String{Value: productType},
+		LocationID: types.Int64{Null: true},
+		MinCPU:     types.Int64{Null: true},
+		MinMemory:  types.Int64{Null: true},
+		MinDisk:    types.Int64{Null: true},
+	}
+
+	if !productID.Null {
+		config.Type = types.String{Null: true}
+	}
+
+	var (
+		result common.Product
+		found  bool
+	)
+
+	for _, product := range products {
+		if !config.AppliesTo(product) {
+			continue
+		}
+
+		if !found || productPricePerHour(product) > productPricePerHour(result) {
+			result = product
+			found = true
+		}
+	}
+
+	if !found && !productID.Null {
+		return result, fmt.Errorf("no product with id %d found", productID.Value)
+	}
+
+	if !found {
+		return result, fmt.Errorf("no product of type %s found, configure the product id explicitly", productType)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,189 @@
+package cloudbit
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+const testProductTypeVolume = "volume"
+
+func TestCostEstimateDataSourceData_AddItem(t *testing.T) {
+	var estimate costEstimateDataSourceData
+
+	estimate.addItem(testProduct(1, "compute-engine", 0.5, testHourlyCycle, 1, 1, 1), 2, 10, 0.5)
+	estimate.addItem(testProduct(2, "compute-engine-volume", 0.001, testHourlyCycle, 0, 0, 1), 100, hoursPerMonth, 0.001)
+
+	if len(estimate.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(estimate.Items))
+	}
+
+	first := estimate.Items[0]
+	if first.ProductID.Value != 1 || first.Name.Value != "product-1" || first.Quantity.Value != 2 || first.Hours.Value != 10 {
+		t.Errorf("unexpected item %+v", first)
+	}
+
+	expected := []float64{2 * 10 * 0.5, 100 * hoursPerMonth * 0.001}
+	for i, item := range estimate.Items {
+		if math.Abs(item.MonthlyCost.Value-expected[i]) > 1e-9 {
+			t.Errorf("expected item %d to cost %f, got %f", i, expected[i], item.MonthlyCost.Value)
+		}
+	}
+
+	if math.Abs(estimate.TotalMonthlyCost.Value-(expected[0]+expected[1])) > 1e-9 {
+		t.Errorf("expected a total of %f, got %f", expected[0]+expected[1], estimate.TotalMonthlyCost.Value)
+	}
+}
+
+func TestFindPricingProduct(t *testing.T) {
+	products := []common.Product{
+		testProduct(1, productTypeElasticIP, 0, testHourlyCycle, 0, 0, 0),
+		testProduct(2, productTypeElasticIP, 0.0069, testHourlyCycle, 0, 0, 0),
+		// billed monthly, 3.65 / 730 is cheaper per hour than product 2
+		testProduct(3, productTypeElasticIP, 3.65, testMonthlyCycle, 0, 0, 0),
+		testProduct(4, testProductTypeVolume, 0.00014, testHourlyCycle, 0, 0, 1),
+		testProduct(5, "compute-engine", 1, testHourlyCycle, 1, 1, 1),
+	}
+
+	tests := []struct {
+		name        string
+		productID   types.Int64
+		productType string
+		product     int
+		err         string
+	}{
+		{name: "most expensive", productID: types.Int64{Null: true}, productType: productTypeElasticIP, product: 2},
+		{name: "single product", productID: types.Int64{Null: true}, productType: testProductTypeVolume, product: 4},
+		{name: "explicit product", productID: types.Int64{Value: 1}, productType: productTypeElasticIP, product: 1},
+		{name: "explicit product of other type", productID: types.Int64{Value: 5}, productType: testProductTypeVolume, product: 5},
+		{name: "unknown product", productID: types.Int64{Value: 100}, productType: testProductTypeVolume, err: "no product with id 100 found"},
+		{name: "unknown type", productID: types.Int64{Null: true}, productType: "compute-engine-snapshot", err: "no product of type compute-engine-snapshot found"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			product, err := findPricingProduct(test.productID, test.productType, products)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if product.ID != test.product {
+				t.Errorf("expected product %d, got %d", test.product, product.ID)
+			}
+		})
+	}
+}
+
+func TestVolumePricePerGiBHour(t *testing.T) {
+	tests := []struct {
+		name    string
+		product common.Product
+		want    float64
+	}{
+		{name: "single GiB", product: testProduct(1, testProductTypeVolume, 0.0002, testHourlyCycle, 0, 0, 1), want: 0.0002},
+		{name: "multiple GiB", product: testProduct(2, testProductTypeVolume, 0.02, testHourlyCycle, 0, 0, 100), want: 0.0002},
+		{name: "monthly", product: testProduct(3, testProductTypeVolume, 14.6, testMonthlyCycle, 0, 0, 100), want: 0.0002},
+		{name: "without storage item", product: common.Product{Price: 0.0002, UsageCycle: testHourlyCycle}, want: 0.0002},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := volumePricePerGiBHour(test.product); math.Abs(got-test.want) > 1e-12 {
+				t.Errorf("expected %g, got %g", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCostEstimateDataSource_Pricing(t *testing.T) {
+	_, prov := testFakeProvider(t)
+	ctx := context.Background()
+
+	dataSource, diagnostics := costEstimateDataSourceType{}.NewDataSource(ctx, prov)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to create data source: %v", diagnostics)
+	}
+
+	number := func(value int) tftypes.Value {
+		return tftypes.NewValue(tftypes.Number, value)
+	}
+
+	tests := []struct {
+		name    string
+		config  map[string]tftypes.Value
+		err     string
+		warning string
+	}{
+		{
+			name:   "volumes without product",
+			config: map[string]tftypes.Value{"volume_size": number(100)},
+			err:    "Missing Volume Product",
+		},
+		{
+			name:   "volumes",
+			config: map[string]tftypes.Value{"volume_size": number(100), "volume_product_id": number(fakeapi.ProductVolume)},
+		},
+		{
+			name:    "elastic ips with default product",
+			config:  map[string]tftypes.Value{"elastic_ip_count": number(2)},
+			warning: "Default Pricing Product",
+		},
+		{
+			name:   "elastic ips",
+			config: map[string]tftypes.Value{"elastic_ip_count": number(2), "elastic_ip_product_id": number(fakeapi.ProductElasticIP)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := testResourceState(t, costEstimateDataSourceType{}, test.config)
+			config := tfsdk.Config{Schema: state.Schema, Raw: state.Raw}
+
+			var diagnostics diag.Diagnostics
+
+			validateResponse := tfsdk.ValidateDataSourceConfigResponse{}
+			dataSource.(tfsdk.DataSourceWithValidateConfig).ValidateConfig(ctx, tfsdk.ValidateDataSourceConfigRequest{Config: config}, &validateResponse)
+			diagnostics.Append(validateResponse.Diagnostics...)
+
+			if !diagnostics.HasError() {
+				readResponse := tfsdk.ReadDataSourceResponse{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}
+				dataSource.Read(ctx, tfsdk.ReadDataSourceRequest{Config: config}, &readResponse)
+				diagnostics.Append(readResponse.Diagnostics...)
+			}
+
+			if test.err != "" {
+				if !diagnostics.HasError() || diagnostics.Errors()[0].Summary() != test.err {
+					t.Fatalf("expected error %q, got %v", test.err, diagnostics)
+				}
+				return
+			}
+
+			if diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", diagnostics)
+			}
+
+			switch {
+			case test.warning == "" && diagnostics.WarningsCount() != 0:
+				t.Errorf("unexpected warnings: %v", diagnostics)
+			case test.warning != "" && (diagnostics.WarningsCount() != 1 || diagnostics.Warnings()[0].Summary() != test.warning):
+				t.Errorf("expected warning %q, got %v", test.warning, diagnostics)
+			}
+		})
+	}
+}
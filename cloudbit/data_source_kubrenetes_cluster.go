@@ -169,6 +169,9 @@ type kubernetesClusterDataSource struct {
 }
 
 func (k kubernetesClusterDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_kubernetes_cluster")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config kubernetesClusterDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -178,7 +181,7 @@ func (k kubernetesClusterDataSource) Read(ctx context.Context, request tfsdk.Rea
 
 	clusters, err := k.clusterService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list clusters: %s", err))
+		addClientError(&response.Diagnostics, "unable to list clusters", err)
 		return
 	}
 
@@ -2,7 +2,6 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 	"github.com/flowswiss/goclient/kubernetes"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
@@ -60,6 +59,9 @@ type kubernetesKubeConfigDataSource struct {
 }
 
 func (k kubernetesKubeConfigDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_kubernetes_kube_config")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config kubernetesKubeConfigDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -69,7 +71,7 @@ func (k kubernetesKubeConfigDataSource) Read(ctx context.Context, request tfsdk.
 
 	kubeConfig, err := k.clusterService.GetKubeConfig(ctx, int(config.ClusterID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get cluster: %s", err))
+		addClientError(&response.Diagnostics, "unable to get cluster", err)
 		return
 	}
 
@@ -121,6 +121,9 @@ type locationDataSource struct {
 }
 
 func (l locationDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_location")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config locationDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -130,7 +133,7 @@ func (l locationDataSource) Read(ctx context.Context, request tfsdk.ReadDataSour
 
 	list, err := common.NewLocationService(l.client).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get locations: %s", err))
+		addClientError(&response.Diagnostics, "unable to get locations", err)
 		return
 	}
 
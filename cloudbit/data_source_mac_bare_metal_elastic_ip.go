@@ -89,6 +89,9 @@ type macBareMetalElasticIPDataSource struct {
 }
 
 func (c macBareMetalElasticIPDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_mac_bare_metal_elastic_ip")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config macBareMetalElasticIPDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -98,7 +101,7 @@ func (c macBareMetalElasticIPDataSource) Read(ctx context.Context, request tfsdk
 
 	list, err := c.elasticIPService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list elastic ips: %s", err))
+		addClientError(&response.Diagnostics, "unable to list elastic ips", err)
 		return
 	}
 
@@ -139,6 +139,9 @@ type macBareMetalNetworkDataSource struct {
 }
 
 func (c macBareMetalNetworkDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_mac_bare_metal_network")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config macBareMetalNetworkDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -148,7 +151,7 @@ func (c macBareMetalNetworkDataSource) Read(ctx context.Context, request tfsdk.R
 
 	list, err := c.networkService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list networks: %s", err))
+		addClientError(&response.Diagnostics, "unable to list networks", err)
 		return
 	}
 
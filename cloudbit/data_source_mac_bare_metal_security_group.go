@@ -89,6 +89,9 @@ type macBareMetalSecurityGroupDataSource struct {
 }
 
 func (c macBareMetalSecurityGroupDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_mac_bare_metal_security_group")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config macBareMetalSecurityGroupDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -98,7 +101,7 @@ func (c macBareMetalSecurityGroupDataSource) Read(ctx context.Context, request t
 
 	list, err := c.securityGroupService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list security groups: %s", err))
+		addClientError(&response.Diagnostics, "unable to list security groups", err)
 		return
 	}
 
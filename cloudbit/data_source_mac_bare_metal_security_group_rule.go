@@ -181,6 +181,9 @@ type macBareMetalSecurityGroupRuleDataSource struct {
 }
 
 func (c macBareMetalSecurityGroupRuleDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_mac_bare_metal_security_group_rule")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config macBareMetalSecurityGroupRuleDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -193,7 +196,7 @@ func (c macBareMetalSecurityGroupRuleDataSource) Read(ctx context.Context, reque
 
 	list, err := c.securityGroupService.Rules(securityGroupID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list security group rules: %s", err))
+		addClientError(&response.Diagnostics, "unable to list security group rules", err)
 		return
 	}
 
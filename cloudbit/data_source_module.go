@@ -109,6 +109,9 @@ type moduleDataSource struct {
 }
 
 func (l moduleDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_module")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config moduleDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -118,7 +121,7 @@ func (l moduleDataSource) Read(ctx context.Context, request tfsdk.ReadDataSource
 
 	list, err := common.NewModuleService(l.client).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get modules: %s", err))
+		addClientError(&response.Diagnostics, "unable to get modules", err)
 		return
 	}
 
@@ -3,6 +3,7 @@ package cloudbit
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/common"
@@ -16,16 +17,57 @@ import (
 var _ tfsdk.DataSourceType = (*productDataSourceType)(nil)
 var _ tfsdk.DataSource = (*productDataSource)(nil)
 
+// hoursPerMonth is the number of hours the product catalog uses for monthly usage cycles.
+const hoursPerMonth = 730
+
+// processorItemNames are the names of the product items which contain the number of CPU cores of a product. Virtual
+// products use `vCPU` and bare metal devices `Processor`, as in the recorded responses of the goclient
+// (common/tests/data.go).
+var processorItemNames = []string{"vCPU", "Processor"}
+
+type productAvailabilityData struct {
+	LocationID types.Int64 `tfsdk:"location_id"`
+	Available  types.Int64 `tfsdk:"available"`
+}
+
 type productDataSourceData struct {
 	ID   types.Int64  `tfsdk:"id"`
 	Name types.String `tfsdk:"name"`
 	Type types.String `tfsdk:"type"`
+
+	LocationID types.Int64 `tfsdk:"location_id"`
+	MinCPU     types.Int64 `tfsdk:"min_cpu"`
+	MinMemory  types.Int64 `tfsdk:"min_memory"`
+	MinDisk    types.Int64 `tfsdk:"min_disk"`
+	Cheapest   types.Bool  `tfsdk:"cheapest"`
+
+	CPU           types.Int64               `tfsdk:"cpu"`
+	Memory        types.Int64               `tfsdk:"memory"`
+	Disk          types.Int64               `tfsdk:"disk"`
+	Availability  []productAvailabilityData `tfsdk:"availability"`
+	PricePerHour  types.Float64             `tfsdk:"price_per_hour"`
+	PricePerMonth types.Float64             `tfsdk:"price_per_month"`
 }
 
 func (p *productDataSourceData) FromEntity(product common.Product) {
 	p.ID = types.Int64{Value: int64(product.ID)}
 	p.Name = types.String{Value: product.Name}
 	p.Type = types.String{Value: product.Type.Key}
+
+	p.CPU = types.Int64{Value: int64(productCPUCount(product))}
+	p.Memory = types.Int64{Value: int64(productItemAmount(product, "Memory"))}
+	p.Disk = types.Int64{Value: int64(productItemAmount(product, "Storage"))}
+
+	p.Availability = make([]productAvailabilityData, len(product.Availability))
+	for i, availability := range product.Availability {
+		p.Availability[i] = productAvailabilityData{
+			LocationID: types.Int64{Value: int64(availability.Location.ID)},
+			Available:  types.Int64{Value: int64(availability.Available)},
+		}
+	}
+
+	p.PricePerHour = types.Float64{Value: productPricePerHour(product)}
+	p.PricePerMonth = types.Float64{Value: productPricePerHour(product) * hoursPerMonth}
 }
 
 func (p productDataSourceData) AppliesTo(product common.Product) bool {
@@ -41,6 +83,22 @@ func (p productDataSourceData) AppliesTo(product common.Product) bool {
 		return false
 	}
 
+	if !p.LocationID.Null && !productAvailableIn(product, int(p.LocationID.Value)) {
+		return false
+	}
+
+	if !p.MinCPU.Null && int64(productCPUCount(product)) < p.MinCPU.Value {
+		return false
+	}
+
+	if !p.MinMemory.Null && int64(productItemAmount(product, "Memory")) < p.MinMemory.Value {
+		return false
+	}
+
+	if !p.MinDisk.Null && int64(productItemAmount(product, "Storage")) < p.MinDisk.Value {
+		return false
+	}
+
 	return true
 }
 
@@ -63,8 +121,74 @@ func (productDataSourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.
 			},
 			"type": {
 				Type:                types.StringType,
-				MarkdownDescription: "type of the product",
+				MarkdownDescription: "type of the product (e.g. `compute-engine`)",
+				Optional:            true,
+				Computed:            true,
+			},
+			"location_id": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "only consider products available in the location with this identifier",
+				Optional:            true,
+			},
+			"min_cpu": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "minimal number of CPU cores of the product",
 				Optional:            true,
+			},
+			"min_memory": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "minimal memory of the product in GiB",
+				Optional:            true,
+			},
+			"min_disk": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "minimal disk size of the product in GiB",
+				Optional:            true,
+			},
+			"cheapest": {
+				Type:                types.BoolType,
+				MarkdownDescription: "select the cheapest product if the filters match more than one product",
+				Optional:            true,
+			},
+			"cpu": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "number of CPU cores of the product",
+				Computed:            true,
+			},
+			"memory": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "memory of the product in GiB",
+				Computed:            true,
+			},
+			"disk": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "disk size of the product in GiB",
+				Computed:            true,
+			},
+			"availability": {
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"location_id": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "unique identifier of the location",
+						Computed:            true,
+					},
+					"available": {
+						Type:                types.Int64Type,
+						MarkdownDescription: "number of available units in the location, `-1` if unlimited",
+						Computed:            true,
+					},
+				}),
+				MarkdownDescription: "availability of the product per location",
+				Computed:            true,
+			},
+			"price_per_hour": {
+				Type:                types.Float64Type,
+				MarkdownDescription: "price of the product per hour",
+				Computed:            true,
+			},
+			"price_per_month": {
+				Type:                types.Float64Type,
+				MarkdownDescription: fmt.Sprintf("price of the product per month, based on %d hours", hoursPerMonth),
 				Computed:            true,
 			},
 		},
@@ -87,6 +211,9 @@ type productDataSource struct {
 }
 
 func (p productDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourceRequest, response *tfsdk.ReadDataSourceResponse) {
+	ctx, span := startDataSourceSpan(ctx, "cloudbit_product")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config productDataSourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -96,11 +223,17 @@ func (p productDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourc
 
 	list, err := p.productService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get products: %s", err))
+		addClientError(&response.Diagnostics, "unable to get products", err)
 		return
 	}
 
-	product, err := filter.FindOne(config, list.Items)
+	var product common.Product
+	if config.Cheapest.Value {
+		product, err = findCheapestProduct(config, list.Items)
+	} else {
+		product, err = filter.FindOne(config, list.Items)
+	}
+
 	if err != nil {
 		response.Diagnostics.AddError("Not Found", fmt.Sprintf("unable to find product: %s", err))
 		return
@@ -108,7 +241,71 @@ func (p productDataSource) Read(ctx context.Context, request tfsdk.ReadDataSourc
 
 	var state productDataSourceData
 	state.FromEntity(product)
+	state.LocationID = config.LocationID
+	state.MinCPU = config.MinCPU
+	state.MinMemory = config.MinMemory
+	state.MinDisk = config.MinDisk
+	state.Cheapest = config.Cheapest
 
 	diagnostics = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diagnostics...)
 }
+
+func findCheapestProduct(config productDataSourceData, products []common.Product) (common.Product, error) {
+	filtered := filter.Find(config, products)
+	if len(filtered) == 0 {
+		return common.Product{}, filter.ErrNoResults
+	}
+
+	cheapest := filtered[0]
+	for _, product := range filtered[1:] {
+		if productPricePerHour(product) < productPricePerHour(cheapest) {
+			cheapest = product
+		}
+	}
+
+	return cheapest, nil
+}
+
+func productAvailableIn(product common.Product, locationID int) bool {
+	for _, availability := range product.Availability {
+		if availability.Location.ID == locationID && availability.Available != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// productCPUCount returns the number of CPU cores included in the product.
+func productCPUCount(product common.Product) int {
+	for _, name := range processorItemNames {
+		if amount := productItemAmount(product, name); amount != 0 {
+			return amount
+		}
+	}
+
+	return 0
+}
+
+// productItemAmount returns the amount of the item with the given name included in the product.
+func productItemAmount(product common.Product, name string) int {
+	for _, item := range product.Items {
+		if strings.EqualFold(item.Name, name) {
+			return item.Amount
+		}
+	}
+
+	return 0
+}
+
+// productPricePerHour normalizes the price of the product to a single hour of usage. The duration of a usage cycle is
+// given in hours by the api, e.g. `{"name": "Hour", "duration": 1}` and `{"name": "Monthly", "duration": 730}` in the
+// product responses recorded by the goclient (flow/product_test.go).
+func productPricePerHour(product common.Product) float64 {
+	if product.UsageCycle.Duration <= 0 {
+		return product.Price
+	}
+
+	return product.Price / float64(product.UsageCycle.Duration)
+}
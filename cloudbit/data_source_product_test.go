@@ -0,0 +1,205 @@
+package cloudbit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/filter"
+)
+
+var (
+	testHourlyCycle  = common.ProductUsageCycle{ID: 2, Name: "Hour", Duration: 1}
+	testMonthlyCycle = common.ProductUsageCycle{ID: 3, Name: "Monthly", Duration: hoursPerMonth}
+)
+
+func testProduct(id int, productType string, price float64, cycle common.ProductUsageCycle, cpu, memory, disk int, locations ...int) common.Product {
+	product := common.Product{
+		ID:         id,
+		Name:       fmt.Sprintf("product-%d", id),
+		Type:       common.ProductType{Key: productType},
+		UsageCycle: cycle,
+		Price:      price,
+		Items: []common.ProductItem{
+			{Name: "vCPU", Amount: cpu},
+			{Name: "Memory", Amount: memory},
+			{Name: "Storage", Amount: disk},
+		},
+	}
+
+	for _, location := range locations {
+		product.Availability = append(product.Availability, common.ProductAvailability{Location: common.Location{ID: location}, Available: -1})
+	}
+
+	return product
+}
+
+func testProductFilter() productDataSourceData {
+	return productDataSourceData{
+		ID:         types.Int64{Null: true},
+		Name:       types.String{Null: true},
+		Type:       types.String{Null: true},
+		LocationID: types.Int64{Null: true},
+		MinCPU:     types.Int64{Null: true},
+		MinMemory:  types.Int64{Null: true},
+		MinDisk:    types.Int64{Null: true},
+	}
+}
+
+func TestProductDataSourceData_AppliesTo(t *testing.T) {
+	product := testProduct(1, "compute-engine", 0.0411, testHourlyCycle, 2, 4, 50, 1)
+
+	tests := []struct {
+		name    string
+		modify  func(config *productDataSourceData)
+		applies bool
+	}{
+		{name: "no filter", modify: func(config *productDataSourceData) {}, applies: true},
+		{name: "id", modify: func(config *productDataSourceData) { config.ID = types.Int64{Value: 1} }, applies: true},
+		{name: "other id", modify: func(config *productDataSourceData) { config.ID = types.Int64{Value: 2} }},
+		{name: "name", modify: func(config *productDataSourceData) { config.Name = types.String{Value: product.Name} }, applies: true},
+		{name: "other name", modify: func(config *productDataSourceData) { config.Name = types.String{Value: "other"} }},
+		{name: "type", modify: func(config *productDataSourceData) { config.Type = types.String{Value: "compute-engine"} }, applies: true},
+		{name: "other type", modify: func(config *productDataSourceData) { config.Type = types.String{Value: "compute-engine-volume"} }},
+		{name: "location", modify: func(config *productDataSourceData) { config.LocationID = types.Int64{Value: 1} }, applies: true},
+		{name: "other location", modify: func(config *productDataSourceData) { config.LocationID = types.Int64{Value: 2} }},
+		{name: "min cpu equal", modify: func(config *productDataSourceData) { config.MinCPU = types.Int64{Value: 2} }, applies: true},
+		{name: "min cpu larger", modify: func(config *productDataSourceData) { config.MinCPU = types.Int64{Value: 3} }},
+		{name: "min memory equal", modify: func(config *productDataSourceData) { config.MinMemory = types.Int64{Value: 4} }, applies: true},
+		{name: "min memory larger", modify: func(config *productDataSourceData) { config.MinMemory = types.Int64{Value: 8} }},
+		{name: "min disk smaller", modify: func(config *productDataSourceData) { config.MinDisk = types.Int64{Value: 20} }, applies: true},
+		{name: "min disk larger", modify: func(config *productDataSourceData) { config.MinDisk = types.Int64{Value: 100} }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := testProductFilter()
+			test.modify(&config)
+
+			if applies := config.AppliesTo(product); applies != test.applies {
+				t.Errorf("expected %t, got %t", test.applies, applies)
+			}
+		})
+	}
+}
+
+func TestProductAvailableIn(t *testing.T) {
+	product := testProduct(1, "compute-engine", 1, testHourlyCycle, 1, 1, 1, 1)
+	product.Availability = append(product.Availability, common.ProductAvailability{Location: common.Location{ID: 2}, Available: 0})
+
+	if !productAvailableIn(product, 1) {
+		t.Error("expected the product to be available in location 1")
+	}
+
+	if productAvailableIn(product, 2) {
+		t.Error("expected the product to be sold out in location 2")
+	}
+
+	if productAvailableIn(product, 3) {
+		t.Error("expected the product not to be offered in location 3")
+	}
+}
+
+func TestFindCheapestProduct(t *testing.T) {
+	products := []common.Product{
+		testProduct(1, "compute-engine", 0.0822, testHourlyCycle, 4, 8, 50, 1, 2),
+		testProduct(2, "compute-engine", 0.0411, testHourlyCycle, 2, 4, 50, 1),
+		// billed monthly, 35 / 730 is more expensive per hour than product 2, but cheaper than product 1
+		testProduct(3, "compute-engine", 35, testMonthlyCycle, 2, 4, 50, 1, 2),
+		testProduct(4, "compute-engine", 0.0137, testHourlyCycle, 1, 1, 20, 2),
+		testProduct(5, "compute-engine-volume", 0.0001, testHourlyCycle, 0, 0, 1, 1, 2),
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(config *productDataSourceData)
+		product int
+	}{
+		{name: "any", modify: func(config *productDataSourceData) {}, product: 5},
+		{name: "type", modify: func(config *productDataSourceData) { config.Type = types.String{Value: "compute-engine"} }, product: 4},
+		{name: "min cpu", modify: func(config *productDataSourceData) { config.MinCPU = types.Int64{Value: 2} }, product: 2},
+		{name: "min cpu in location", modify: func(config *productDataSourceData) {
+			config.MinCPU = types.Int64{Value: 2}
+			config.LocationID = types.Int64{Value: 2}
+		}, product: 3},
+		{name: "min memory", modify: func(config *productDataSourceData) { config.MinMemory = types.Int64{Value: 5} }, product: 1},
+		{name: "no match", modify: func(config *productDataSourceData) { config.MinCPU = types.Int64{Value: 16} }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := testProductFilter()
+			test.modify(&config)
+
+			product, err := findCheapestProduct(config, products)
+			if test.product == 0 {
+				if !errors.Is(err, filter.ErrNoResults) {
+					t.Fatalf("expected no results, got %v (%v)", product.ID, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if product.ID != test.product {
+				t.Errorf("expected product %d, got %d", test.product, product.ID)
+			}
+		})
+	}
+}
+
+func TestProductPricePerHour(t *testing.T) {
+	tests := []struct {
+		name  string
+		price float64
+		cycle common.ProductUsageCycle
+		want  float64
+	}{
+		{name: "hourly", price: 0.5, cycle: testHourlyCycle, want: 0.5},
+		{name: "monthly", price: 73, cycle: testMonthlyCycle, want: 0.1},
+		{name: "missing duration", price: 2, cycle: common.ProductUsageCycle{}, want: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			product := common.Product{Price: test.price, UsageCycle: test.cycle}
+
+			if got := productPricePerHour(product); got < test.want-1e-9 || got > test.want+1e-9 {
+				t.Errorf("expected %f, got %f", test.want, got)
+			}
+		})
+	}
+}
+
+func TestProductItemAmounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		items  []common.ProductItem
+		cpu    int
+		memory int
+	}{
+		{name: "virtual", items: []common.ProductItem{{Name: "vCPU", Amount: 2}, {Name: "Memory", Amount: 4}}, cpu: 2, memory: 4},
+		{name: "bare metal", items: []common.ProductItem{{Name: "Processor", Amount: 6}, {Name: "memory", Amount: 64}}, cpu: 6, memory: 64},
+		{name: "no items", items: nil},
+		{name: "unrelated items", items: []common.ProductItem{{Name: "IPv4 Address", Amount: 1}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			product := common.Product{Items: test.items}
+
+			if cpu := productCPUCount(product); cpu != test.cpu {
+				t.Errorf("expected %d cpu cores, got %d", test.cpu, cpu)
+			}
+
+			if memory := productItemAmount(product, "Memory"); memory != test.memory {
+				t.Errorf("expected %d GiB of memory, got %d", test.memory, memory)
+			}
+		})
+	}
+}
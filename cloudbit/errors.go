@@ -0,0 +1,195 @@
+package cloudbit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/flowswiss/goclient"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// apiErrorCodeSummaries maps the codes of api errors to the summary of their diagnostic. They take precedence over the
+// summaries derived from the http status, as e.g. an exceeded quota is reported using a generic status.
+var apiErrorCodeSummaries = map[string]string{
+	"quota_exceeded":    "Quota Exceeded",
+	"validation_failed": "Validation Failed",
+}
+
+var apiStatusSummaries = map[int]string{
+	http.StatusBadRequest:          "Validation Failed",
+	http.StatusUnauthorized:        "Unauthorized",
+	http.StatusPaymentRequired:     "Quota Exceeded",
+	http.StatusForbidden:           "Forbidden",
+	http.StatusNotFound:            "Not Found",
+	http.StatusConflict:            "Conflict",
+	http.StatusUnprocessableEntity: "Validation Failed",
+	http.StatusTooManyRequests:     "Too Many Requests",
+}
+
+// apiErrorBody is the body of an error response of the api.
+type apiErrorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message struct {
+			En string `json:"en"`
+		} `json:"message"`
+		Fields map[string]struct {
+			En string `json:"en"`
+		} `json:"fields"`
+	} `json:"error"`
+}
+
+// clientError is an error returned by the client, translated into the information shown in its diagnostic.
+type clientError struct {
+	summary   string
+	message   string
+	requestID string
+
+	// fields contains the validation errors of the request by the name of the field
+	fields map[string]string
+}
+
+func newClientError(message string, err error) clientError {
+	c := clientError{
+		summary: "Client Error",
+		message: fmt.Sprintf("%s: %s", message, err),
+	}
+
+	var apiErr goclient.APIError
+	if !errors.As(err, &apiErr) || apiErr.Response() == nil {
+		return c
+	}
+
+	c.requestID = apiErr.RequestID()
+
+	status := apiErr.Response().StatusCode
+	if summary, ok := apiStatusSummaries[status]; ok {
+		c.summary = summary
+	} else if status >= 500 {
+		c.summary = "API Error"
+	}
+
+	body, ok := apiErr.Response().Body.(*retainedBody)
+	if !ok {
+		return c
+	}
+
+	var decoded apiErrorBody
+	if json.Unmarshal(body.data, &decoded) != nil {
+		return c
+	}
+
+	if summary, ok := apiErrorCodeSummaries[decoded.Error.Code]; ok {
+		c.summary = summary
+	}
+
+	if len(decoded.Error.Fields) != 0 {
+		c.fields = make(map[string]string, len(decoded.Error.Fields))
+		for field, message := range decoded.Error.Fields {
+			c.fields[field] = message.En
+		}
+	}
+
+	return c
+}
+
+func (c clientError) detail(fields []string) string {
+	var detail strings.Builder
+	detail.WriteString(c.message)
+
+	if len(fields) != 0 {
+		detail.WriteString("\n")
+		for _, field := range fields {
+			detail.WriteString(fmt.Sprintf("\n- %s: %s", field, c.fields[field]))
+		}
+	}
+
+	if c.requestID != "" {
+		detail.WriteString(fmt.Sprintf("\n\nRequest ID: %s", c.requestID))
+	}
+
+	return detail.String()
+}
+
+// addClientError adds a diagnostic for an error returned by the client. The summary of the diagnostic depends on the
+// kind of error, and the request id is included so that the error can be referenced in support requests.
+func addClientError(diagnostics *diag.Diagnostics, message string, err error) {
+	c := newClientError(message, err)
+	diagnostics.AddError(c.summary, c.detail(sortedKeys(c.fields)))
+}
+
+// addClientErrorWithAttributes is the same as addClientError, but attaches the validation errors of fields, which
+// correspond to an attribute of the schema, to that attribute.
+func addClientErrorWithAttributes(diagnostics *diag.Diagnostics, schema tfsdk.Schema, message string, err error) {
+	c := newClientError(message, err)
+
+	var unmatched []string
+	for _, field := range sortedKeys(c.fields) {
+		if _, err := schema.AttributeAtPath(tftypes.NewAttributePath().WithAttributeName(field)); err != nil {
+			unmatched = append(unmatched, field)
+			continue
+		}
+
+		diagnostics.AddAttributeError(path.Root(field), c.summary, c.detail([]string{field}))
+	}
+
+	if len(unmatched) != 0 || len(c.fields) == 0 {
+		diagnostics.AddError(c.summary, c.detail(unmatched))
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// retainedBody keeps the content of a response body after it has been read, so that the body of an error response is
+// still available when translating the error returned by the client.
+type retainedBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (r *retainedBody) Close() error {
+	return nil
+}
+
+// errorBodyTransport retains the body of error responses of the api.
+type errorBodyTransport struct {
+	base http.RoundTripper
+}
+
+func (e errorBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := e.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	res, err := base.RoundTrip(req)
+	if err != nil || res.StatusCode < 400 {
+		return res, err
+	}
+
+	data, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = &retainedBody{Reader: bytes.NewReader(data), data: data}
+	return res, nil
+}
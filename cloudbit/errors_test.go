@@ -0,0 +1,109 @@
+package cloudbit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+func testErrorClient(api *fakeapi.Server, token string) goclient.Client {
+	return goclient.NewClient(
+		goclient.WithToken(token),
+		goclient.WithBase(api.URL()),
+		goclient.WithHTTPClientOption(func(c *http.Client) {
+			c.Transport = errorBodyTransport{base: c.Transport}
+		}),
+	)
+}
+
+func TestAddClientError(t *testing.T) {
+	ctx := context.Background()
+
+	api := fakeapi.New()
+	defer api.Close()
+
+	client := testErrorClient(api, "token")
+
+	_, notFoundErr := compute.NewNetworkService(client).Get(ctx, 1000)
+	_, unauthorizedErr := compute.NewNetworkService(testErrorClient(api, "")).Get(ctx, 1000)
+
+	tests := []struct {
+		name      string
+		err       error
+		summary   string
+		requestID bool
+	}{
+		{name: "not found", err: notFoundErr, summary: "Not Found", requestID: true},
+		{name: "unauthorized", err: unauthorizedErr, summary: "Unauthorized", requestID: true},
+		{name: "other", err: errors.New("connection refused"), summary: "Client Error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var diagnostics diag.Diagnostics
+			addClientError(&diagnostics, "unable to get network", test.err)
+
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected a single diagnostic, got %v", diagnostics)
+			}
+
+			if diagnostics[0].Summary() != test.summary {
+				t.Errorf("expected summary %q, got %q", test.summary, diagnostics[0].Summary())
+			}
+
+			detail := diagnostics[0].Detail()
+			if !strings.HasPrefix(detail, "unable to get network: ") {
+				t.Errorf("expected detail to start with the message, got %q", detail)
+			}
+
+			if strings.Contains(detail, "Request ID: fake-") != test.requestID {
+				t.Errorf("expected request id in detail to be %t, got %q", test.requestID, detail)
+			}
+		})
+	}
+}
+
+func TestAddClientErrorWithAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	api := fakeapi.New()
+	defer api.Close()
+
+	client := testErrorClient(api, "token")
+
+	_, err := compute.NewKeyPairService(client).Create(ctx, compute.KeyPairCreate{Name: "test", PublicKey: "invalid"})
+	if err == nil {
+		t.Fatal("expected creating a key pair with an invalid public key to fail")
+	}
+
+	schema, _ := computeKeyPairResourceType{}.GetSchema(ctx)
+
+	var diagnostics diag.Diagnostics
+	addClientErrorWithAttributes(&diagnostics, schema, "unable to create key pair", err)
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected a single diagnostic, got %v", diagnostics)
+	}
+
+	withPath, ok := diagnostics[0].(diag.DiagnosticWithPath)
+	if !ok || !withPath.Path().Equal(path.Root("public_key")) {
+		t.Errorf("expected diagnostic to be attached to public_key, got %v", diagnostics[0])
+	}
+
+	if diagnostics[0].Summary() != "Validation Failed" {
+		t.Errorf("expected summary %q, got %q", "Validation Failed", diagnostics[0].Summary())
+	}
+
+	if !strings.Contains(diagnostics[0].Detail(), "- public_key: invalid public key") {
+		t.Errorf("expected detail to contain the field error, got %q", diagnostics[0].Detail())
+	}
+}
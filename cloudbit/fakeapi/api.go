@@ -0,0 +1,363 @@
+// Package fakeapi implements an in-memory fake of the Cloudbit API, which allows running the acceptance tests of the
+// provider without credentials and without creating real (and billed) infrastructure.
+//
+// The fake implements the endpoints of the goclient used by the provider for compute, kubernetes, orders and the
+// product catalog. Orders are processed immediately and the catalog is seeded with a small set of locations, modules,
+// products and images (see catalog.go). The behaviour of the real api is only approximated: requests are validated
+// as far as the provider depends on it, but for example quotas, billing and the mac bare metal module are not
+// implemented.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/flowswiss/goclient/kubernetes"
+)
+
+// Server is an in-memory fake of the Cloudbit API served over HTTP. All methods are safe for concurrent use.
+type Server struct {
+	server   *httptest.Server
+	handlers []handler
+
+	mu         sync.Mutex
+	lastID     int
+	requestID  int
+	holdOrders bool
+
+	locations        []common.Location
+	modules          []common.Module
+	products         []common.Product
+	images           []compute.Image
+	algorithms       []compute.LoadBalancerAlgorithm
+	protocols        []compute.LoadBalancerProtocol
+	healthCheckTypes []compute.LoadBalancerHealthCheckType
+	clusterVersion   kubernetes.ClusterVersion
+
+	orders             map[int]*common.Order
+	networks           map[int]*compute.Network
+	servers            map[int]*compute.Server
+	networkInterfaces  map[int]*networkInterface
+	elasticIPs         map[int]*elasticIP
+	routers            map[int]*compute.Router
+	routerInterfaces   map[int]*routerInterface
+	routerRoutes       map[int]*routerRoute
+	keyPairs           map[int]*compute.KeyPair
+	certificates       map[int]*compute.Certificate
+	securityGroups     map[int]*compute.SecurityGroup
+	securityGroupRules map[int]*securityGroupRule
+	volumes            map[int]*volume
+	snapshots          map[int]*snapshot
+	loadBalancers      map[int]*compute.LoadBalancer
+	pools              map[int]*pool
+	members            map[int]*member
+	clusters           map[int]*kubernetes.Cluster
+}
+
+// New starts a new fake api seeded with the default catalog. The server must be closed after use.
+func New() *Server {
+	s := &Server{
+		orders:             map[int]*common.Order{},
+		networks:           map[int]*compute.Network{},
+		servers:            map[int]*compute.Server{},
+		networkInterfaces:  map[int]*networkInterface{},
+		elasticIPs:         map[int]*elasticIP{},
+		routers:            map[int]*compute.Router{},
+		routerInterfaces:   map[int]*routerInterface{},
+		routerRoutes:       map[int]*routerRoute{},
+		keyPairs:           map[int]*compute.KeyPair{},
+		certificates:       map[int]*compute.Certificate{},
+		securityGroups:     map[int]*compute.SecurityGroup{},
+		securityGroupRules: map[int]*securityGroupRule{},
+		volumes:            map[int]*volume{},
+		snapshots:          map[int]*snapshot{},
+		loadBalancers:      map[int]*compute.LoadBalancer{},
+		pools:              map[int]*pool{},
+		members:            map[int]*member{},
+		clusters:           map[int]*kubernetes.Cluster{},
+	}
+
+	s.seedCatalog()
+
+	s.registerCatalog()
+	s.registerOrders()
+	s.registerNetworks()
+	s.registerServers()
+	s.registerElasticIPs()
+	s.registerRouters()
+	s.registerKeyPairs()
+	s.registerCertificates()
+	s.registerSecurityGroups()
+	s.registerVolumes()
+	s.registerLoadBalancers()
+	s.registerClusters()
+
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+// URL returns the base url of the fake api, which can be passed to the provider using WithDefaultEndpoint.
+func (s *Server) URL() string {
+	return s.server.URL + "/"
+}
+
+// Close shuts down the fake api.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requestID++
+	w.Header().Set("X-Request-Id", fmt.Sprintf("fake-%d", s.requestID))
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, apiError{status: http.StatusUnauthorized, message: "missing authentication token"})
+		return
+	}
+
+	segments := splitPath(r.URL.Path)
+	for _, h := range s.handlers {
+		params, ok := h.match(r.Method, segments)
+		if !ok {
+			continue
+		}
+
+		body, err := h.fn(&request{Request: r, params: params})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeResponse(w, r, body)
+		return
+	}
+
+	writeError(w, apiError{status: http.StatusNotFound, message: fmt.Sprintf("%s %s is not implemented by the fake api", r.Method, r.URL.Path)})
+}
+
+func (s *Server) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+type handlerFunc func(r *request) (interface{}, error)
+
+type handler struct {
+	method   string
+	segments []string
+	fn       handlerFunc
+}
+
+// handle registers a handler for the given method and path. Segments of the path in braces (e.g. `{id}`) match any
+// value, which is then available as parameter of the request.
+func (s *Server) handle(method string, path string, fn handlerFunc) {
+	s.handlers = append(s.handlers, handler{
+		method:   method,
+		segments: splitPath(path),
+		fn:       fn,
+	})
+}
+
+func (h handler) match(method string, segments []string) ([]string, bool) {
+	if h.method != method || len(h.segments) != len(segments) {
+		return nil, false
+	}
+
+	var params []string
+	for i, segment := range h.segments {
+		if strings.HasPrefix(segment, "{") {
+			params = append(params, segments[i])
+			continue
+		}
+
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+type request struct {
+	*http.Request
+	params []string
+}
+
+// id returns the path parameter at the given index as identifier.
+func (r *request) id(index int) (int, error) {
+	id, err := strconv.Atoi(r.params[index])
+	if err != nil {
+		return 0, apiError{status: http.StatusNotFound, message: fmt.Sprintf("invalid identifier %q", r.params[index])}
+	}
+
+	return id, nil
+}
+
+func (r *request) decode(body interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(body)
+	if err != nil {
+		return badRequest("invalid request body: %s", err)
+	}
+
+	return nil
+}
+
+// list marks a slice which is returned as paginated list.
+type list struct {
+	items interface{}
+}
+
+type apiError struct {
+	status  int
+	message string
+
+	// fields contains the validation errors by the name of the invalid field
+	fields map[string]string
+}
+
+func (e apiError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return apiError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// invalidField returns a validation error of a single field of the request body.
+func invalidField(field string, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	return apiError{status: http.StatusBadRequest, message: message, fields: map[string]string{field: message}}
+}
+
+func notFound(kind string, id int) error {
+	return apiError{status: http.StatusNotFound, message: fmt.Sprintf("%s with id %d not found", kind, id)}
+}
+
+func conflict(format string, args ...interface{}) error {
+	return apiError{status: http.StatusConflict, message: fmt.Sprintf(format, args...)}
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, body interface{}) {
+	if body == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if l, ok := body.(list); ok {
+		body = paginate(w, r, l)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodPost {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "validation_failed",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusInternalServerError: "internal_error",
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	apiErr, ok := err.(apiError)
+	if !ok {
+		apiErr = apiError{status: http.StatusInternalServerError, message: err.Error()}
+	}
+
+	content := map[string]interface{}{
+		"code": errorCodes[apiErr.status],
+		"message": map[string]string{
+			"en": apiErr.message,
+		},
+	}
+
+	if len(apiErr.fields) != 0 {
+		fields := make(map[string]interface{}, len(apiErr.fields))
+		for field, message := range apiErr.fields {
+			fields[field] = map[string]string{"en": message}
+		}
+
+		content["fields"] = fields
+	}
+
+	body := map[string]interface{}{
+		"error": content,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// paginate applies the pagination of the request to the list and sets the pagination headers of the response.
+func paginate(w http.ResponseWriter, r *http.Request, l list) interface{} {
+	items := reflect.ValueOf(l.items)
+	total := items.Len()
+
+	page, perPage := 1, total
+	if r.URL.Query().Get("no_filter") != "1" {
+		perPage = 10
+		if value, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && value > 0 {
+			perPage = value
+		}
+
+		if value, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && value > 0 {
+			page = value
+		}
+	}
+
+	pages := 1
+	if perPage > 0 {
+		pages = (total + perPage - 1) / perPage
+	}
+
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	w.Header().Set("X-Pagination-Current-Page", strconv.Itoa(page))
+	w.Header().Set("X-Pagination-Limit", strconv.Itoa(perPage))
+	w.Header().Set("X-Pagination-Count", strconv.Itoa(end-start))
+	w.Header().Set("X-Pagination-Total-Count", strconv.Itoa(total))
+	w.Header().Set("X-Pagination-Total-Pages", strconv.Itoa(pages))
+
+	return items.Slice(start, end).Interface()
+}
+
+// sortedIDs returns the keys of the map in ascending order, so that lists are returned in a stable order.
+func sortedIDs[T any](m map[int]T) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+	return ids
+}
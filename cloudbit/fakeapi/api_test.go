@@ -0,0 +1,191 @@
+package fakeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+)
+
+func newTestClient(t *testing.T) goclient.Client {
+	t.Helper()
+
+	api := New()
+	t.Cleanup(api.Close)
+
+	return goclient.NewClient(goclient.WithBase(api.URL()), goclient.WithToken("test"))
+}
+
+func TestServerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	network, err := compute.NewNetworkService(client).Create(ctx, compute.NetworkCreate{
+		Name:       "test",
+		LocationID: LocationALP1,
+		CIDR:       "192.168.1.0/24",
+	})
+	if err != nil {
+		t.Fatalf("create network: %s", err)
+	}
+
+	if network.GatewayIP != "192.168.1.1" || network.AllocationPoolStart != "192.168.1.2" || network.AllocationPoolEnd != "192.168.1.254" {
+		t.Errorf("unexpected network addressing: %+v", network)
+	}
+
+	servers := compute.NewServerService(client)
+	ordering, err := servers.Create(ctx, compute.ServerCreate{
+		Name:             "test",
+		LocationID:       LocationALP1,
+		ImageID:          ImageUbuntu,
+		ProductID:        ProductServerSmall,
+		NetworkID:        network.ID,
+		AttachExternalIP: true,
+		Password:         "secret",
+	})
+	if err != nil {
+		t.Fatalf("create server: %s", err)
+	}
+
+	order, err := common.NewOrderService(client).WaitUntilProcessed(ctx, ordering)
+	if err != nil {
+		t.Fatalf("wait for order: %s", err)
+	}
+
+	server, err := servers.Get(ctx, order.Product.ID)
+	if err != nil {
+		t.Fatalf("get server: %s", err)
+	}
+
+	if len(server.Networks) != 1 || len(server.Networks[0].Interfaces) != 1 {
+		t.Fatalf("expected one network interface, got %+v", server.Networks)
+	}
+
+	iface := server.Networks[0].Interfaces[0]
+	if iface.PrivateIP != "192.168.1.2" || iface.PublicIP == "" {
+		t.Errorf("unexpected network interface: %+v", iface)
+	}
+
+	elasticIPs, err := compute.NewElasticIPService(client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("list elastic ips: %s", err)
+	}
+
+	if len(elasticIPs.Items) != 1 || elasticIPs.Items[0].Attachment.ID != server.ID {
+		t.Errorf("expected the elastic ip to be attached to the server, got %+v", elasticIPs.Items)
+	}
+
+	err = compute.NewNetworkService(client).Delete(ctx, network.ID)
+	if !isStatus(err, http.StatusConflict) {
+		t.Errorf("expected a conflict when deleting a network in use, got %v", err)
+	}
+
+	if err := servers.Delete(ctx, server.ID, true); err != nil {
+		t.Fatalf("delete server: %s", err)
+	}
+
+	_, err = servers.Get(ctx, server.ID)
+	if !isStatus(err, http.StatusNotFound) {
+		t.Errorf("expected the server to be deleted, got %v", err)
+	}
+
+	if err := compute.NewNetworkService(client).Delete(ctx, network.ID); err != nil {
+		t.Errorf("delete network: %s", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	products := common.NewProductService(client)
+
+	all, err := products.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("list products: %s", err)
+	}
+
+	page, err := products.List(ctx, goclient.Cursor{Page: 2, PerPage: 3})
+	if err != nil {
+		t.Fatalf("list products: %s", err)
+	}
+
+	if len(page.Items) != 3 || page.Items[0].ID != all.Items[3].ID {
+		t.Errorf("unexpected second page: %+v", page.Items)
+	}
+
+	if page.Pagination.TotalCount != len(all.Items) {
+		t.Errorf("expected a total count of %d, got %d", len(all.Items), page.Pagination.TotalCount)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	api := New()
+	defer api.Close()
+
+	client := goclient.NewClient(goclient.WithBase(api.URL()))
+
+	_, err := common.NewLocationService(client).List(context.Background(), goclient.Cursor{NoFilter: 1})
+	if !isStatus(err, http.StatusUnauthorized) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestHoldOrders(t *testing.T) {
+	api := New()
+	defer api.Close()
+
+	ctx := context.Background()
+	client := goclient.NewClient(goclient.WithBase(api.URL()), goclient.WithToken("test"))
+	orders := common.NewOrderService(client)
+
+	networks, err := compute.NewNetworkService(client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("list networks: %s", err)
+	}
+
+	api.HoldOrders(true)
+
+	ordering, err := compute.NewServerService(client).Create(ctx, compute.ServerCreate{
+		Name:       "test",
+		LocationID: LocationALP1,
+		ImageID:    ImageUbuntu,
+		ProductID:  ProductServerSmall,
+		NetworkID:  networks.Items[0].ID,
+		Password:   "password",
+	})
+	if err != nil {
+		t.Fatalf("create server: %s", err)
+	}
+
+	id, err := ordering.ExtractIdentifier()
+	if err != nil {
+		t.Fatalf("extract order id: %s", err)
+	}
+
+	order, err := orders.Get(ctx, id)
+	if err != nil || order.Status.ID != common.OrderStatusProcessing {
+		t.Fatalf("expected the order to be processing, got %v (%v)", order.Status, err)
+	}
+
+	if err := api.SetOrderStatus(id, common.OrderStatusFailed); err != nil {
+		t.Fatalf("set order status: %s", err)
+	}
+
+	if _, err := orders.WaitUntilProcessed(ctx, ordering); !errors.Is(err, common.ErrOrderFailed) {
+		t.Errorf("expected the order to fail, got %v", err)
+	}
+
+	if err := api.SetOrderStatus(id+1000, common.OrderStatusSucceeded); err == nil {
+		t.Error("expected setting the status of an unknown order to fail")
+	}
+}
+
+func isStatus(err error, status int) bool {
+	var apiErr goclient.APIError
+	return errors.As(err, &apiErr) && apiErr.Response().StatusCode == status
+}
@@ -0,0 +1,357 @@
+package fakeapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/flowswiss/goclient/kubernetes"
+)
+
+// Identifiers of the seeded catalog, which can be used in test configurations.
+const (
+	LocationALP1 = 1
+	LocationZRH1 = 2
+
+	ProductServerSmall  = 1
+	ProductServerMedium = 2
+	ProductServerLarge  = 3
+
+	ProductElasticIP    = 10
+	ProductVolume       = 20
+	ProductSnapshot     = 21
+	ProductLoadBalancer = 30
+	ProductCluster      = 40
+	ProductClusterNode  = 44
+
+	ImageUbuntu  = 1
+	ImageDebian  = 2
+	ImageWindows = 3
+
+	// DefaultNetworkName is the name of the network which exists in every location of the fake api.
+	DefaultNetworkName = "default"
+)
+
+// The modules are identified by their name, their identifiers intentionally differ from the ones of the api.
+const (
+	moduleCompute           = 102
+	moduleObjectStorage     = 104
+	moduleComputeNetworking = 105
+	moduleKubernetes        = 107
+)
+
+func (s *Server) seedCatalog() {
+	compute_ := common.Module{ID: moduleCompute, Name: "Compute", Sorting: 1}
+	objectStorage := common.Module{ID: moduleObjectStorage, Name: "Object Storage", Sorting: 2}
+	networking := common.Module{ID: moduleComputeNetworking, Name: "Compute Networking", Parent: &compute_, Sorting: 3}
+	kube := common.Module{ID: moduleKubernetes, Name: "Kubernetes", Sorting: 4}
+
+	alp1 := common.Location{ID: LocationALP1, Name: "ALP1", Key: "ALP1", City: "Lucerne", Modules: []common.Module{compute_, objectStorage, networking, kube}}
+	zrh1 := common.Location{ID: LocationZRH1, Name: "ZRH1", Key: "ZRH1", City: "Zurich", Modules: []common.Module{compute_, objectStorage, networking}}
+	s.locations = []common.Location{alp1, zrh1}
+
+	brief := func(location common.Location) common.Location {
+		return common.Location{ID: location.ID, Name: location.Name, Key: location.Key, City: location.City}
+	}
+
+	for _, module := range []common.Module{compute_, objectStorage, networking, kube} {
+		for _, location := range s.locations {
+			for _, available := range location.Modules {
+				if available.ID == module.ID {
+					module.Locations = append(module.Locations, brief(location))
+				}
+			}
+		}
+
+		s.modules = append(s.modules, module)
+	}
+
+	hourly := common.ProductUsageCycle{ID: 2, Name: "Hour", Duration: 1}
+	availableIn := func(locations ...common.Location) (availability []common.ProductAvailability) {
+		for _, location := range locations {
+			availability = append(availability, common.ProductAvailability{Location: brief(location), Available: -1})
+		}
+		return
+	}
+
+	serverType := common.ProductType{ID: 1, Name: "Compute Engine", Key: "compute-engine"}
+	server := func(id int, name string, cpu, memory, storage int, price float64) common.Product {
+		return common.Product{
+			ID:         id,
+			Name:       name,
+			Type:       serverType,
+			Visibility: "public",
+			UsageCycle: hourly,
+			Items: []common.ProductItem{
+				{ID: 1, Name: "vCPU", Description: "Cores", Amount: cpu},
+				{ID: 2, Name: "Memory", Description: "GB", Amount: memory},
+				{ID: 3, Name: "Storage", Description: "GB", Amount: storage},
+			},
+			Price:        price,
+			Availability: availableIn(alp1, zrh1),
+		}
+	}
+
+	s.products = []common.Product{
+		server(ProductServerSmall, "b1.1x1", 1, 1, 20, 0.0137),
+		server(ProductServerMedium, "b1.2x4", 2, 4, 50, 0.0411),
+		server(ProductServerLarge, "b1.4x8", 4, 8, 50, 0.0822),
+		{
+			ID:           ProductElasticIP,
+			Name:         "Elastic IP",
+			Type:         common.ProductType{ID: 5, Name: "Elastic IP", Key: "compute-engine-elastic-ip"},
+			Visibility:   "public",
+			UsageCycle:   hourly,
+			Items:        []common.ProductItem{{ID: 4, Name: "IPv4 Address", Description: "IPv4 Address", Amount: 1}},
+			Price:        0.0069,
+			Availability: availableIn(alp1, zrh1),
+		},
+		{
+			ID:           ProductVolume,
+			Name:         "Volume",
+			Type:         common.ProductType{ID: 6, Name: "Volume", Key: "compute-engine-volume"},
+			Visibility:   "public",
+			UsageCycle:   hourly,
+			Items:        []common.ProductItem{{ID: 3, Name: "Storage", Description: "GB", Amount: 1}},
+			Price:        0.00014,
+			Availability: availableIn(alp1, zrh1),
+		},
+		{
+			ID:           ProductSnapshot,
+			Name:         "Snapshot",
+			Type:         common.ProductType{ID: 7, Name: "Snapshot", Key: "compute-engine-snapshot"},
+			Visibility:   "public",
+			UsageCycle:   hourly,
+			Items:        []common.ProductItem{{ID: 3, Name: "Storage", Description: "GB", Amount: 1}},
+			Price:        0.00007,
+			Availability: availableIn(alp1, zrh1),
+		},
+		{
+			ID:           ProductLoadBalancer,
+			Name:         "Load Balancer",
+			Type:         common.ProductType{ID: 8, Name: "Load Balancer", Key: "compute-engine-load-balancer"},
+			Visibility:   "public",
+			UsageCycle:   hourly,
+			Price:        0.0274,
+			Availability: availableIn(alp1, zrh1),
+		},
+		{
+			ID:           ProductCluster,
+			Name:         "Kubernetes Control Plane",
+			Type:         common.ProductType{ID: 9, Name: "Kubernetes Cluster", Key: "kubernetes-cluster"},
+			Visibility:   "public",
+			UsageCycle:   hourly,
+			Price:        0.1233,
+			Availability: availableIn(alp1),
+		},
+		{
+			ID:         ProductClusterNode,
+			Name:       "k8s.w.4x8",
+			Type:       common.ProductType{ID: 10, Name: "Kubernetes Node", Key: "kubernetes-node"},
+			Visibility: "public",
+			UsageCycle: hourly,
+			Items: []common.ProductItem{
+				{ID: 1, Name: "vCPU", Description: "Cores", Amount: 4},
+				{ID: 2, Name: "Memory", Description: "GB", Amount: 8},
+				{ID: 3, Name: "Storage", Description: "GB", Amount: 50},
+			},
+			Price:        0.0822,
+			Availability: availableIn(alp1),
+		},
+	}
+
+	s.images = []compute.Image{
+		{ID: ImageUbuntu, OperatingSystem: "Ubuntu", Version: "22.04 LTS", Key: "linux-ubuntu-22.04-lts", Category: "Linux", Type: "distribution", Username: "ubuntu", MinRootDiskSize: 10, Sorting: 1, AvailableLocations: []int{LocationALP1, LocationZRH1}},
+		{ID: ImageDebian, OperatingSystem: "Debian", Version: "11", Key: "linux-debian-11", Category: "Linux", Type: "distribution", Username: "debian", MinRootDiskSize: 10, Sorting: 2, AvailableLocations: []int{LocationALP1, LocationZRH1}},
+		{ID: ImageWindows, OperatingSystem: "Windows Server", Version: "2022 Standard", Key: "microsoft-windows-server-2022", Category: "Windows", Type: "distribution", Username: "Administrator", MinRootDiskSize: 40, Sorting: 3, AvailableLocations: []int{LocationALP1}},
+	}
+
+	s.algorithms = []compute.LoadBalancerAlgorithm{
+		{ID: 1, Name: "Round Robin", Key: "round_robin"},
+		{ID: 2, Name: "Least Connections", Key: "least_connections"},
+		{ID: 3, Name: "Source IP", Key: "source_ip"},
+	}
+
+	s.protocols = []compute.LoadBalancerProtocol{
+		{ID: 1, Name: "HTTP", Key: "http"},
+		{ID: 2, Name: "HTTPS", Key: "https"},
+		{ID: 3, Name: "TCP", Key: "tcp"},
+	}
+
+	s.healthCheckTypes = []compute.LoadBalancerHealthCheckType{
+		{ID: 1, Name: "HTTP", Key: "http"},
+		{ID: 2, Name: "HTTPS", Key: "https"},
+		{ID: 3, Name: "TCP", Key: "tcp"},
+		{ID: 4, Name: "Ping", Key: "ping"},
+	}
+
+	s.clusterVersion = kubernetes.ClusterVersion{
+		ID:    1,
+		Name:  "1.24",
+		Major: 1,
+		Minor: 24,
+		UpgradePaths: []kubernetes.ClusterVersion{
+			{ID: 2, Name: "1.25", Major: 1, Minor: 25},
+		},
+	}
+
+	for _, location := range s.locations {
+		s.createDefaultNetwork(location)
+	}
+}
+
+func (s *Server) registerCatalog() {
+	s.handle(http.MethodGet, "/v4/entities/locations", func(r *request) (interface{}, error) {
+		return list{s.locations}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/locations/{id}", func(r *request) (interface{}, error) {
+		return findByID(r, "location", s.locations, func(l common.Location) int { return l.ID })
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/modules", func(r *request) (interface{}, error) {
+		return list{s.modules}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/modules/{id}", func(r *request) (interface{}, error) {
+		return findByID(r, "module", s.modules, func(m common.Module) int { return m.ID })
+	})
+
+	s.handle(http.MethodGet, "/v4/products", func(r *request) (interface{}, error) {
+		return list{s.products}, nil
+	})
+
+	// the same path is used to get a single product or to list the products of a type
+	s.handle(http.MethodGet, "/v4/products/{id}", func(r *request) (interface{}, error) {
+		if _, err := strconv.Atoi(r.params[0]); err == nil {
+			return findByID(r, "product", s.products, func(p common.Product) int { return p.ID })
+		}
+
+		products := []common.Product{}
+		for _, product := range s.products {
+			if product.Type.Key == r.params[0] {
+				products = append(products, product)
+			}
+		}
+
+		return list{products}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/product-types", func(r *request) (interface{}, error) {
+		types := []common.ProductType{}
+		seen := map[int]bool{}
+		for _, product := range s.products {
+			if !seen[product.Type.ID] {
+				seen[product.Type.ID] = true
+				types = append(types, product.Type)
+			}
+		}
+
+		return list{types}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/compute/images", func(r *request) (interface{}, error) {
+		return list{s.images}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/compute/images/{id}", func(r *request) (interface{}, error) {
+		return findByID(r, "image", s.images, func(i compute.Image) int { return i.ID })
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/compute/load-balancer-algorithms", func(r *request) (interface{}, error) {
+		return list{s.algorithms}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/compute/load-balancer-protocols", func(r *request) (interface{}, error) {
+		return list{s.protocols}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/entities/compute/load-balancer-health-check-types", func(r *request) (interface{}, error) {
+		return list{s.healthCheckTypes}, nil
+	})
+}
+
+func (s *Server) location(id int) (common.Location, error) {
+	for _, location := range s.locations {
+		if location.ID == id {
+			return location, nil
+		}
+	}
+
+	return common.Location{}, badRequest("location %d does not exist", id)
+}
+
+func (s *Server) product(id int) (common.Product, error) {
+	for _, product := range s.products {
+		if product.ID == id {
+			return product, nil
+		}
+	}
+
+	return common.Product{}, badRequest("product %d does not exist", id)
+}
+
+// SetProductAvailability limits the product to the given locations, e.g. to test configurations using a product which
+// is not offered everywhere.
+func (s *Server) SetProductAvailability(productID int, locationIDs ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, product := range s.products {
+		if product.ID != productID {
+			continue
+		}
+
+		product.Availability = nil
+		for _, location := range s.locations {
+			if containsInt(locationIDs, location.ID) {
+				brief := common.Location{ID: location.ID, Name: location.Name, Key: location.Key, City: location.City}
+				product.Availability = append(product.Availability, common.ProductAvailability{Location: brief, Available: -1})
+			}
+		}
+
+		s.products[i] = product
+	}
+}
+
+// availableProduct returns the product if it is available in the location.
+func (s *Server) availableProduct(id int, location common.Location) (common.Product, error) {
+	product, err := s.product(id)
+	if err != nil {
+		return product, err
+	}
+
+	for _, availability := range product.Availability {
+		if availability.Location.ID == location.ID && availability.Available != 0 {
+			return product, nil
+		}
+	}
+
+	return product, badRequest("product %s is not available in location %s", product.Name, location.Name)
+}
+
+func (s *Server) image(id int) (compute.Image, error) {
+	for _, image := range s.images {
+		if image.ID == id {
+			return image, nil
+		}
+	}
+
+	return compute.Image{}, badRequest("image %d does not exist", id)
+}
+
+func findByID[T any](r *request, kind string, items []T, id func(T) int) (interface{}, error) {
+	wanted, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if id(item) == wanted {
+			return item, nil
+		}
+	}
+
+	return nil, notFound(kind, wanted)
+}
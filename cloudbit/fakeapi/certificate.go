@@ -0,0 +1,133 @@
+package fakeapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+)
+
+func (s *Server) registerCertificates() {
+	s.handle(http.MethodGet, "/v4/compute/certificates", func(r *request) (interface{}, error) {
+		certificates := []compute.Certificate{}
+		for _, id := range sortedIDs(s.certificates) {
+			certificates = append(certificates, *s.certificates[id])
+		}
+
+		return list{certificates}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/certificates/{id}", func(r *request) (interface{}, error) {
+		certificate, err := s.findCertificate(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return *certificate, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/certificates", func(r *request) (interface{}, error) {
+		var body compute.CertificateCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		certificatePEM, err := base64.StdEncoding.DecodeString(body.Certificate)
+		if err != nil {
+			return nil, badRequest("certificate is not base64 encoded")
+		}
+
+		privateKeyPEM, err := base64.StdEncoding.DecodeString(body.PrivateKey)
+		if err != nil {
+			return nil, badRequest("private key is not base64 encoded")
+		}
+
+		// verifies that the private key belongs to the leaf certificate
+		if _, err := tls.X509KeyPair(certificatePEM, privateKeyPEM); err != nil {
+			return nil, badRequest("invalid certificate or private key: %s", err)
+		}
+
+		block, _ := pem.Decode(certificatePEM)
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, badRequest("invalid certificate: %s", err)
+		}
+
+		certificate := &compute.Certificate{
+			ID:       s.nextID(),
+			Name:     body.Name,
+			Location: location,
+			Type:     "imported",
+			Details: compute.CertificateDetails{
+				Subject:   certificateName(leaf.Subject),
+				Issuer:    certificateName(leaf.Issuer),
+				ValidFrom: common.Time(leaf.NotBefore),
+				ValidTo:   common.Time(leaf.NotAfter),
+				Serial:    leaf.SerialNumber.String(),
+			},
+		}
+
+		s.certificates[certificate.ID] = certificate
+		return *certificate, nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/certificates/{id}", func(r *request) (interface{}, error) {
+		certificate, err := s.findCertificate(r)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pool := range s.pools {
+			if pool.CertificateID == certificate.ID {
+				return nil, conflict("certificate %s is still used by a balancing pool", certificate.Name)
+			}
+		}
+
+		delete(s.certificates, certificate.ID)
+		return nil, nil
+	})
+}
+
+func (s *Server) findCertificate(r *request) (*compute.Certificate, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, found := s.certificates[id]
+	if !found {
+		return nil, notFound("certificate", id)
+	}
+
+	return certificate, nil
+}
+
+func certificateName(name pkix.Name) map[string]string {
+	attributes := map[string]string{"CN": name.CommonName}
+
+	values := map[string][]string{
+		"OU": name.OrganizationalUnit,
+		"O":  name.Organization,
+		"L":  name.Locality,
+		"P":  name.Province,
+		"C":  name.Country,
+	}
+
+	for key, value := range values {
+		if len(value) != 0 {
+			attributes[key] = value[0]
+		}
+	}
+
+	return attributes
+}
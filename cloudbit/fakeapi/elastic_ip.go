@@ -0,0 +1,228 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+)
+
+const (
+	attachmentServer       = "server"
+	attachmentLoadBalancer = "load_balancer"
+)
+
+type elasticIP struct {
+	compute.ElasticIP
+
+	// InterfaceID is the network interface of the server the elastic ip is attached to.
+	InterfaceID int
+}
+
+func (s *Server) registerElasticIPs() {
+	s.handle(http.MethodGet, "/v4/compute/elastic-ips", func(r *request) (interface{}, error) {
+		elasticIPs := []compute.ElasticIP{}
+		for _, id := range sortedIDs(s.elasticIPs) {
+			elasticIPs = append(elasticIPs, s.renderElasticIP(id))
+		}
+
+		return list{elasticIPs}, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/elastic-ips", func(r *request) (interface{}, error) {
+		var body compute.ElasticIPCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		eip, err := s.createElasticIP(location)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderElasticIP(eip.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/elastic-ips/{id}", func(r *request) (interface{}, error) {
+		eip, err := s.findElasticIP(r, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if eip.InterfaceID != 0 {
+			return nil, conflict("elastic ip %s is still attached", eip.PublicIP)
+		}
+
+		delete(s.elasticIPs, eip.ID)
+		return nil, nil
+	})
+}
+
+func (s *Server) createElasticIP(location common.Location) (*elasticIP, error) {
+	product, err := s.availableProduct(ProductElasticIP, location)
+	if err != nil {
+		return nil, err
+	}
+
+	publicIP, err := s.allocatePublicIP()
+	if err != nil {
+		return nil, err
+	}
+
+	eip := &elasticIP{
+		ElasticIP: compute.ElasticIP{
+			ID:       s.nextID(),
+			Product:  briefProduct(product),
+			Location: location,
+			Price:    product.Price,
+			PublicIP: publicIP,
+		},
+	}
+
+	s.elasticIPs[eip.ID] = eip
+	return eip, nil
+}
+
+// allocatePublicIP returns an unused address of the documentation range 203.0.113.0/24.
+func (s *Server) allocatePublicIP() (string, error) {
+	used := map[string]bool{}
+	for _, eip := range s.elasticIPs {
+		used[eip.PublicIP] = true
+	}
+
+	for _, router := range s.routers {
+		used[router.PublicIP] = true
+	}
+
+	for _, cluster := range s.clusters {
+		used[cluster.PublicAddress] = true
+	}
+
+	for i := 1; i < 255; i++ {
+		ip := fmt.Sprintf("203.0.113.%d", i)
+		if !used[ip] {
+			return ip, nil
+		}
+	}
+
+	return "", conflict("no public ip address left")
+}
+
+func (s *Server) findElasticIP(r *request, index int) (*elasticIP, error) {
+	id, err := r.id(index)
+	if err != nil {
+		return nil, err
+	}
+
+	eip, found := s.elasticIPs[id]
+	if !found {
+		return nil, notFound("elastic ip", id)
+	}
+
+	return eip, nil
+}
+
+func (s *Server) renderElasticIP(id int) compute.ElasticIP {
+	eip := s.elasticIPs[id]
+	rendered := eip.ElasticIP
+
+	if iface, found := s.networkInterfaces[eip.InterfaceID]; found {
+		rendered.PrivateIP = iface.PrivateIP
+		rendered.Attachment = compute.ElasticIPAttachment{
+			ID:   iface.OwnerID,
+			Name: s.ownerName(iface),
+			Type: iface.OwnerKind,
+		}
+	}
+
+	return rendered
+}
+
+// elasticIPOfInterface returns the elastic ip attached to the network interface, if any.
+func (s *Server) elasticIPOfInterface(interfaceID int) *elasticIP {
+	for _, eip := range s.elasticIPs {
+		if eip.InterfaceID == interfaceID {
+			return eip
+		}
+	}
+
+	return nil
+}
+
+// attachElasticIP attaches an elastic ip to a network interface of a server. If no interface is
+// specified, the first interface without an elastic ip is used.
+func (s *Server) attachElasticIP(r *request, ownerKind string, ownerID int, location common.Location) (interface{}, error) {
+	var body compute.ElasticIPAttach
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+
+	eip, found := s.elasticIPs[body.ElasticIPID]
+	if !found {
+		return nil, badRequest("elastic ip %d does not exist", body.ElasticIPID)
+	}
+
+	if eip.InterfaceID != 0 {
+		return nil, conflict("elastic ip %s is already attached", eip.PublicIP)
+	}
+
+	if eip.Location.ID != location.ID {
+		return nil, badRequest("elastic ip %s is not in location %s", eip.PublicIP, location.Name)
+	}
+
+	interfaceID := body.NetworkInterfaceID
+	if interfaceID == 0 {
+		for _, id := range sortedIDs(s.networkInterfaces) {
+			iface := s.networkInterfaces[id]
+			if iface.OwnerKind == ownerKind && iface.OwnerID == ownerID && s.elasticIPOfInterface(id) == nil {
+				interfaceID = id
+				break
+			}
+		}
+	}
+
+	iface, found := s.networkInterfaces[interfaceID]
+	if !found || iface.OwnerKind != ownerKind || iface.OwnerID != ownerID {
+		return nil, badRequest("network interface %d does not exist", interfaceID)
+	}
+
+	if s.elasticIPOfInterface(interfaceID) != nil {
+		return nil, conflict("network interface %d already has an elastic ip attached", interfaceID)
+	}
+
+	eip.InterfaceID = interfaceID
+	return s.renderElasticIP(eip.ID), nil
+}
+
+func (s *Server) detachElasticIP(r *request, ownerKind string, ownerID int) (interface{}, error) {
+	eip, err := s.findElasticIP(r, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	iface, found := s.networkInterfaces[eip.InterfaceID]
+	if !found || iface.OwnerKind != ownerKind || iface.OwnerID != ownerID {
+		return nil, notFound("elastic ip", eip.ID)
+	}
+
+	eip.InterfaceID = 0
+	return nil, nil
+}
+
+func (s *Server) attachedElasticIPs(ownerKind string, ownerID int) list {
+	elasticIPs := []compute.ElasticIP{}
+	for _, id := range sortedIDs(s.elasticIPs) {
+		iface, found := s.networkInterfaces[s.elasticIPs[id].InterfaceID]
+		if found && iface.OwnerKind == ownerKind && iface.OwnerID == ownerID {
+			elasticIPs = append(elasticIPs, s.renderElasticIP(id))
+		}
+	}
+
+	return list{elasticIPs}
+}
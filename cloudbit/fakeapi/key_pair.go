@@ -0,0 +1,60 @@
+package fakeapi
+
+import (
+	"net/http"
+
+	"github.com/flowswiss/goclient/compute"
+	"golang.org/x/crypto/ssh"
+)
+
+func (s *Server) registerKeyPairs() {
+	s.handle(http.MethodGet, "/v4/compute/key-pairs", func(r *request) (interface{}, error) {
+		keyPairs := []compute.KeyPair{}
+		for _, id := range sortedIDs(s.keyPairs) {
+			keyPairs = append(keyPairs, *s.keyPairs[id])
+		}
+
+		return list{keyPairs}, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/key-pairs", func(r *request) (interface{}, error) {
+		var body compute.KeyPairCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(body.PublicKey))
+		if err != nil {
+			return nil, invalidField("public_key", "invalid public key: %s", err)
+		}
+
+		for _, keyPair := range s.keyPairs {
+			if keyPair.Name == body.Name {
+				return nil, conflict("key pair with name %s already exists", body.Name)
+			}
+		}
+
+		keyPair := &compute.KeyPair{
+			ID:          s.nextID(),
+			Name:        body.Name,
+			Fingerprint: ssh.FingerprintLegacyMD5(publicKey),
+		}
+
+		s.keyPairs[keyPair.ID] = keyPair
+		return *keyPair, nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/key-pairs/{id}", func(r *request) (interface{}, error) {
+		id, err := r.id(0)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, found := s.keyPairs[id]; !found {
+			return nil, notFound("key pair", id)
+		}
+
+		delete(s.keyPairs, id)
+		return nil, nil
+	})
+}
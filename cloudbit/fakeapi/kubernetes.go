@@ -0,0 +1,281 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/flowswiss/goclient/kubernetes"
+)
+
+const kubeConfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[2]s:6443
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+current-context: %[1]s
+users:
+- name: %[1]s
+  user:
+    token: fake
+`
+
+var clusterStatusHealthy = kubernetes.ClusterStatus{ID: compute.ClusterStatusHealthy, Key: "healthy", Name: "Healthy"}
+
+func (s *Server) registerClusters() {
+	s.handle(http.MethodGet, "/v4/kubernetes/clusters", func(r *request) (interface{}, error) {
+		clusters := []kubernetes.Cluster{}
+		for _, id := range sortedIDs(s.clusters) {
+			clusters = append(clusters, s.renderCluster(id))
+		}
+
+		return list{clusters}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/kubernetes/clusters/{id}", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderCluster(cluster.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/kubernetes/clusters", func(r *request) (interface{}, error) {
+		var body kubernetes.ClusterCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		product, err := s.availableProduct(ProductCluster, location)
+		if err != nil {
+			return nil, err
+		}
+
+		network, err := s.network(body.NetworkID)
+		if err != nil || network.Location.ID != location.ID {
+			return nil, badRequest("network %d does not exist in location %s", body.NetworkID, location.Name)
+		}
+
+		worker, err := s.workerProduct(body.Worker.ProductID, body.Worker.Count, location)
+		if err != nil {
+			return nil, err
+		}
+
+		publicAddress, err := s.allocatePublicIP()
+		if err != nil {
+			return nil, err
+		}
+
+		cluster := &kubernetes.Cluster{
+			ID:            s.nextID(),
+			Name:          body.Name,
+			Location:      location,
+			Product:       product,
+			PublicAddress: publicAddress,
+			Version:       s.clusterVersion,
+			Status:        clusterStatusHealthy,
+		}
+
+		cluster.DNSName = fmt.Sprintf("k8s-%d.%s.fake.cloudbit.ch", cluster.ID, location.Key)
+		cluster.NodeCount.Current.ControlPlane = 3
+		cluster.NodeCount.Expected.ControlPlane = 3
+		cluster.ExpectedPreset.ControlPlane = product
+		setClusterWorkers(cluster, worker, body.Worker.Count)
+
+		securityGroup := &compute.SecurityGroup{
+			ID:          s.nextID(),
+			Name:        fmt.Sprintf("kubernetes-%s", body.Name),
+			Description: fmt.Sprintf("security group of kubernetes cluster %s", body.Name),
+			Location:    location,
+			Immutable:   true,
+		}
+
+		s.securityGroups[securityGroup.ID] = securityGroup
+		cluster.SecurityGroup = *securityGroup
+		cluster.Network = *network
+
+		now := time.Now()
+		cluster.KubeConfig.UpdatedAt = common.Time(now)
+		cluster.KubeConfig.ExpiresAt = common.Time(now.AddDate(1, 0, 0))
+
+		s.clusters[cluster.ID] = cluster
+		return s.order(product, cluster.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/kubernetes/clusters/{id}", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body kubernetes.ClusterUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		if body.Name != "" {
+			cluster.Name = body.Name
+		}
+
+		return s.renderCluster(cluster.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/kubernetes/clusters/{id}", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		delete(s.securityGroups, cluster.SecurityGroup.ID)
+		delete(s.clusters, cluster.ID)
+		return nil, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/kubernetes/clusters/{id}/kube-config", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return kubernetes.ClusterKubeConfig{
+			KubeConfig: fmt.Sprintf(kubeConfigTemplate, cluster.Name, cluster.DNSName),
+		}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/kubernetes/clusters/{id}/configuration", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return kubernetes.ClusterConfiguration{
+			VersionID: cluster.Version.ID,
+			Variables: json.RawMessage(`{}`),
+		}, nil
+	})
+
+	s.handle(http.MethodPut, "/v4/kubernetes/clusters/{id}/configuration", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body kubernetes.ClusterConfiguration
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		version, err := findEntity(s.clusterVersions(cluster.Version), body.VersionID, "kubernetes version", func(v kubernetes.ClusterVersion) int { return v.ID })
+		if err != nil {
+			return nil, err
+		}
+
+		cluster.Version = version
+		return kubernetes.ClusterConfiguration{
+			VersionID: version.ID,
+			Variables: json.RawMessage(`{}`),
+		}, nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/kubernetes/clusters/{id}/flavor", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body kubernetes.ClusterUpdateFlavor
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		worker, err := s.workerProduct(body.Worker.ProductID, body.Worker.Count, cluster.Location)
+		if err != nil {
+			return nil, err
+		}
+
+		setClusterWorkers(cluster, worker, body.Worker.Count)
+		return s.renderCluster(cluster.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/kubernetes/clusters/{id}/action", func(r *request) (interface{}, error) {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body kubernetes.ClusterPerformAction
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		return s.renderCluster(cluster.ID), nil
+	})
+}
+
+func (s *Server) findCluster(r *request) (*kubernetes.Cluster, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, found := s.clusters[id]
+	if !found {
+		return nil, notFound("cluster", id)
+	}
+
+	return cluster, nil
+}
+
+func (s *Server) workerProduct(productID int, count int, location common.Location) (common.Product, error) {
+	product, err := s.availableProduct(productID, location)
+	if err != nil {
+		return product, err
+	}
+
+	if product.Type.Key != "kubernetes-node" {
+		return product, badRequest("product %s can not be used for worker nodes", product.Name)
+	}
+
+	if count < 1 {
+		return product, badRequest("at least one worker node is required")
+	}
+
+	return product, nil
+}
+
+// clusterVersions returns the versions a cluster can be configured with, which are the current version and its
+// upgrade paths.
+func (s *Server) clusterVersions(current kubernetes.ClusterVersion) []kubernetes.ClusterVersion {
+	return append([]kubernetes.ClusterVersion{current}, current.UpgradePaths...)
+}
+
+func setClusterWorkers(cluster *kubernetes.Cluster, product common.Product, count int) {
+	cluster.ExpectedPreset.Worker = product
+	cluster.NodeCount.Current.Worker = count
+	cluster.NodeCount.Expected.Worker = count
+}
+
+func (s *Server) renderCluster(id int) kubernetes.Cluster {
+	cluster := *s.clusters[id]
+
+	if network, found := s.networks[cluster.Network.ID]; found {
+		cluster.Network = s.renderNetwork(network.ID)
+	}
+
+	return cluster
+}
@@ -0,0 +1,475 @@
+package fakeapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flowswiss/goclient/compute"
+)
+
+var loadBalancerStatusActive = compute.LoadBalancerStatus{ID: compute.LoadBalancerStatusActive, Name: "Active", Key: "active"}
+
+type pool struct {
+	compute.LoadBalancerPool
+	LoadBalancerID int
+	CertificateID  int
+}
+
+type member struct {
+	compute.LoadBalancerMember
+	PoolID int
+}
+
+func (s *Server) registerLoadBalancers() {
+	s.handle(http.MethodGet, "/v4/compute/load-balancers", func(r *request) (interface{}, error) {
+		loadBalancers := []compute.LoadBalancer{}
+		for _, id := range sortedIDs(s.loadBalancers) {
+			loadBalancers = append(loadBalancers, s.renderLoadBalancer(id))
+		}
+
+		return list{loadBalancers}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/load-balancers/{id}", func(r *request) (interface{}, error) {
+		loadBalancer, err := s.findLoadBalancer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderLoadBalancer(loadBalancer.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/load-balancers", func(r *request) (interface{}, error) {
+		var body compute.LoadBalancerCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		product, err := s.availableProduct(ProductLoadBalancer, location)
+		if err != nil {
+			return nil, err
+		}
+
+		network, err := s.network(body.NetworkID)
+		if err != nil || network.Location.ID != location.ID {
+			return nil, badRequest("network %d does not exist in location %s", body.NetworkID, location.Name)
+		}
+
+		privateIP, err := s.allocateAddress(network, body.PrivateIP, false)
+		if err != nil {
+			return nil, err
+		}
+
+		loadBalancer := &compute.LoadBalancer{
+			ID:       s.nextID(),
+			Name:     body.Name,
+			Location: location,
+			Product:  product,
+			Status:   loadBalancerStatusActive,
+		}
+
+		s.loadBalancers[loadBalancer.ID] = loadBalancer
+		iface := s.createNetworkInterface(attachmentLoadBalancer, loadBalancer.ID, network, privateIP)
+
+		if body.AttachExternalIP {
+			eip, err := s.createElasticIP(location)
+			if err != nil {
+				return nil, err
+			}
+
+			eip.InterfaceID = iface.ID
+		}
+
+		return s.order(product, loadBalancer.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/load-balancers/{id}", func(r *request) (interface{}, error) {
+		loadBalancer, err := s.findLoadBalancer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.LoadBalancerUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		if body.Name != "" {
+			loadBalancer.Name = body.Name
+		}
+
+		return s.renderLoadBalancer(loadBalancer.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/load-balancers/{id}/action", func(r *request) (interface{}, error) {
+		loadBalancer, err := s.findLoadBalancer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.LoadBalancerPerform
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		return s.renderLoadBalancer(loadBalancer.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/load-balancers/{id}", func(r *request) (interface{}, error) {
+		loadBalancer, err := s.findLoadBalancer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		for poolID, p := range s.pools {
+			if p.LoadBalancerID != loadBalancer.ID {
+				continue
+			}
+
+			for memberID, m := range s.members {
+				if m.PoolID == poolID {
+					delete(s.members, memberID)
+				}
+			}
+
+			delete(s.pools, poolID)
+		}
+
+		for _, id := range sortedIDs(s.networkInterfaces) {
+			iface := s.networkInterfaces[id]
+			if iface.OwnerKind == attachmentLoadBalancer && iface.OwnerID == loadBalancer.ID {
+				s.deleteNetworkInterface(iface, false)
+			}
+		}
+
+		delete(s.loadBalancers, loadBalancer.ID)
+		return nil, nil
+	})
+
+	s.registerLoadBalancerPools()
+	s.registerLoadBalancerMembers()
+}
+
+func (s *Server) registerLoadBalancerPools() {
+	s.handle(http.MethodGet, "/v4/compute/load-balancers/{id}/balancing-pools", func(r *request) (interface{}, error) {
+		loadBalancer, err := s.findLoadBalancer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		pools := []compute.LoadBalancerPool{}
+		for _, id := range sortedIDs(s.pools) {
+			if s.pools[id].LoadBalancerID == loadBalancer.ID {
+				pools = append(pools, s.renderPool(id))
+			}
+		}
+
+		return list{pools}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/load-balancers/{id}/balancing-pools/{pid}", func(r *request) (interface{}, error) {
+		p, err := s.findPool(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderPool(p.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/load-balancers/{id}/balancing-pools", func(r *request) (interface{}, error) {
+		loadBalancer, err := s.findLoadBalancer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.LoadBalancerPoolCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		for _, other := range s.pools {
+			if other.LoadBalancerID == loadBalancer.ID && other.EntryPort == body.EntryPort {
+				return nil, conflict("entry port %d is already used by another pool", body.EntryPort)
+			}
+		}
+
+		entryProtocol, err := findEntity(s.protocols, body.EntryProtocolID, "entry protocol", func(p compute.LoadBalancerProtocol) int { return p.ID })
+		if err != nil {
+			return nil, err
+		}
+
+		targetProtocol, err := findEntity(s.protocols, body.TargetProtocolID, "target protocol", func(p compute.LoadBalancerProtocol) int { return p.ID })
+		if err != nil {
+			return nil, err
+		}
+
+		p := &pool{
+			LoadBalancerPool: compute.LoadBalancerPool{
+				ID:             s.nextID(),
+				Status:         loadBalancerStatusActive,
+				EntryProtocol:  entryProtocol,
+				TargetProtocol: targetProtocol,
+				EntryPort:      body.EntryPort,
+				StickySession:  body.StickySession,
+			},
+			LoadBalancerID: loadBalancer.ID,
+		}
+
+		p.Name = entryProtocol.Name + " " + strconv.Itoa(body.EntryPort)
+
+		if err := s.applyPoolOptions(p, body.BalancingAlgorithmID, body.CertificateID, body.HealthCheck); err != nil {
+			return nil, err
+		}
+
+		if entryProtocol.Key == "https" && p.CertificateID == 0 {
+			return nil, badRequest("a certificate is required for the https entry protocol")
+		}
+
+		s.pools[p.ID] = p
+
+		for _, create := range body.Members {
+			s.createMember(p, create)
+		}
+
+		return s.renderPool(p.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/load-balancers/{id}/balancing-pools/{pid}", func(r *request) (interface{}, error) {
+		p, err := s.findPool(r)
+		if err != nil {
+			return nil, err
+		}
+
+		// sticky session is omitted when it is false, so it has to be distinguished from an absent field
+		var body struct {
+			CertificateID        int                                    `json:"certificate_id"`
+			BalancingAlgorithmID int                                    `json:"balancing_algorithm_id"`
+			StickySession        *bool                                  `json:"sticky_session"`
+			HealthCheck          compute.LoadBalancerHealthCheckOptions `json:"health_check"`
+		}
+
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		algorithmID := body.BalancingAlgorithmID
+		if algorithmID == 0 {
+			algorithmID = p.Algorithm.ID
+		}
+
+		certificateID := body.CertificateID
+		if certificateID == 0 {
+			certificateID = p.CertificateID
+		}
+
+		updated := *p
+		if err := s.applyPoolOptions(&updated, algorithmID, certificateID, body.HealthCheck); err != nil {
+			return nil, err
+		}
+
+		updated.StickySession = body.StickySession != nil && *body.StickySession
+
+		*p = updated
+		return s.renderPool(p.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/load-balancers/{id}/balancing-pools/{pid}", func(r *request) (interface{}, error) {
+		p, err := s.findPool(r)
+		if err != nil {
+			return nil, err
+		}
+
+		for id, m := range s.members {
+			if m.PoolID == p.ID {
+				delete(s.members, id)
+			}
+		}
+
+		delete(s.pools, p.ID)
+		return nil, nil
+	})
+}
+
+func (s *Server) registerLoadBalancerMembers() {
+	s.handle(http.MethodGet, "/v4/compute/load-balancers/{id}/balancing-pools/{pid}/members", func(r *request) (interface{}, error) {
+		p, err := s.findPool(r)
+		if err != nil {
+			return nil, err
+		}
+
+		members := []compute.LoadBalancerMember{}
+		for _, id := range sortedIDs(s.members) {
+			if m := s.members[id]; m.PoolID == p.ID {
+				members = append(members, m.LoadBalancerMember)
+			}
+		}
+
+		return list{members}, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/load-balancers/{id}/balancing-pools/{pid}/members", func(r *request) (interface{}, error) {
+		p, err := s.findPool(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.LoadBalancerMemberCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		for _, other := range s.members {
+			if other.PoolID == p.ID && other.Address == body.Address && other.Port == body.Port {
+				return nil, conflict("member %s:%d already exists", body.Address, body.Port)
+			}
+		}
+
+		return s.createMember(p, body).LoadBalancerMember, nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/load-balancers/{id}/balancing-pools/{pid}/members/{mid}", func(r *request) (interface{}, error) {
+		p, err := s.findPool(r)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := r.id(2)
+		if err != nil {
+			return nil, err
+		}
+
+		m, found := s.members[id]
+		if !found || m.PoolID != p.ID {
+			return nil, notFound("member", id)
+		}
+
+		delete(s.members, id)
+		return nil, nil
+	})
+}
+
+func (s *Server) findLoadBalancer(r *request) (*compute.LoadBalancer, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	loadBalancer, found := s.loadBalancers[id]
+	if !found {
+		return nil, notFound("load balancer", id)
+	}
+
+	return loadBalancer, nil
+}
+
+func (s *Server) findPool(r *request) (*pool, error) {
+	loadBalancer, err := s.findLoadBalancer(r)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := r.id(1)
+	if err != nil {
+		return nil, err
+	}
+
+	p, found := s.pools[id]
+	if !found || p.LoadBalancerID != loadBalancer.ID {
+		return nil, notFound("balancing pool", id)
+	}
+
+	return p, nil
+}
+
+func (s *Server) applyPoolOptions(p *pool, algorithmID int, certificateID int, options compute.LoadBalancerHealthCheckOptions) error {
+	algorithm, err := findEntity(s.algorithms, algorithmID, "balancing algorithm", func(a compute.LoadBalancerAlgorithm) int { return a.ID })
+	if err != nil {
+		return err
+	}
+
+	healthCheckType, err := findEntity(s.healthCheckTypes, options.TypeID, "health check type", func(t compute.LoadBalancerHealthCheckType) int { return t.ID })
+	if err != nil {
+		return err
+	}
+
+	if certificateID != 0 {
+		if _, found := s.certificates[certificateID]; !found {
+			return badRequest("certificate %d does not exist", certificateID)
+		}
+	}
+
+	p.Algorithm = algorithm
+	p.CertificateID = certificateID
+	p.HealthCheck = compute.LoadBalancerHealthCheck{
+		Type:               healthCheckType,
+		HTTPMethod:         options.HTTPMethod,
+		HTTPPath:           options.HTTPPath,
+		Interval:           defaultInt(options.Interval, 5),
+		Timeout:            defaultInt(options.Timeout, 5),
+		HealthyThreshold:   defaultInt(options.HealthyThreshold, 3),
+		UnhealthyThreshold: defaultInt(options.UnhealthyThreshold, 3),
+	}
+
+	return nil
+}
+
+func (s *Server) createMember(p *pool, create compute.LoadBalancerMemberCreate) *member {
+	m := &member{
+		LoadBalancerMember: compute.LoadBalancerMember{
+			ID:      s.nextID(),
+			Name:    create.Name,
+			Address: create.Address,
+			Port:    create.Port,
+			Status:  loadBalancerStatusActive,
+		},
+		PoolID: p.ID,
+	}
+
+	s.members[m.ID] = m
+	return m
+}
+
+func (s *Server) renderLoadBalancer(id int) compute.LoadBalancer {
+	loadBalancer := *s.loadBalancers[id]
+	loadBalancer.Networks = s.networkAttachments(attachmentLoadBalancer, id)
+	if loadBalancer.Networks == nil {
+		loadBalancer.Networks = []compute.LoadBalancerNetworkAttachment{}
+	}
+
+	return loadBalancer
+}
+
+func (s *Server) renderPool(id int) compute.LoadBalancerPool {
+	p := s.pools[id]
+	rendered := p.LoadBalancerPool
+
+	if certificate, found := s.certificates[p.CertificateID]; found {
+		rendered.Certificate = *certificate
+	}
+
+	return rendered
+}
+
+func findEntity[T any](entities []T, id int, kind string, identifier func(T) int) (T, error) {
+	for _, entity := range entities {
+		if identifier(entity) == id {
+			return entity, nil
+		}
+	}
+
+	var empty T
+	return empty, badRequest("%s %d does not exist", kind, id)
+}
+
+func defaultInt(value int, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+
+	return value
+}
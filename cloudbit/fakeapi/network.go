@@ -0,0 +1,290 @@
+package fakeapi
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+)
+
+var defaultDomainNameServers = []string{"1.1.1.1", "8.8.8.8"}
+
+func (s *Server) createDefaultNetwork(location common.Location) {
+	id := s.nextID()
+	s.networks[id] = &compute.Network{
+		ID:                  id,
+		Name:                DefaultNetworkName,
+		Location:            location,
+		CIDR:                "172.31.0.0/24",
+		DomainNameServers:   defaultDomainNameServers,
+		GatewayIP:           "172.31.0.1",
+		AllocationPoolStart: "172.31.0.2",
+		AllocationPoolEnd:   "172.31.0.254",
+	}
+}
+
+func (s *Server) registerNetworks() {
+	s.handle(http.MethodGet, "/v4/compute/networks", func(r *request) (interface{}, error) {
+		networks := []compute.Network{}
+		for _, id := range sortedIDs(s.networks) {
+			networks = append(networks, s.renderNetwork(id))
+		}
+
+		return list{networks}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/networks/{id}", func(r *request) (interface{}, error) {
+		network, err := s.findNetwork(r, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderNetwork(network.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/networks", func(r *request) (interface{}, error) {
+		var body compute.NetworkCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix, err := netip.ParsePrefix(body.CIDR)
+		if err != nil || prefix.Masked() != prefix || !prefix.Addr().Is4() {
+			return nil, invalidField("cidr", "invalid cidr %q", body.CIDR)
+		}
+
+		network := &compute.Network{
+			ID:                  s.nextID(),
+			Name:                body.Name,
+			Description:         body.Description,
+			Location:            location,
+			CIDR:                prefix.String(),
+			DomainNameServers:   body.DomainNameServers,
+			GatewayIP:           body.GatewayIP,
+			AllocationPoolStart: body.AllocationPoolStart,
+			AllocationPoolEnd:   body.AllocationPoolEnd,
+		}
+
+		first := prefix.Addr().Next()
+		last := lastAddress(prefix).Prev()
+
+		if network.GatewayIP == "" {
+			network.GatewayIP = first.String()
+		}
+
+		if network.AllocationPoolStart == "" {
+			network.AllocationPoolStart = first.Next().String()
+		}
+
+		if network.AllocationPoolEnd == "" {
+			network.AllocationPoolEnd = last.String()
+		}
+
+		if network.DomainNameServers == nil {
+			network.DomainNameServers = defaultDomainNameServers
+		}
+
+		if err := validateNetworkAddressing(network); err != nil {
+			return nil, err
+		}
+
+		s.networks[network.ID] = network
+		return s.renderNetwork(network.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/networks/{id}", func(r *request) (interface{}, error) {
+		network, err := s.findNetwork(r, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.NetworkUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		updated := *network
+		if body.Name != "" {
+			updated.Name = body.Name
+		}
+
+		if body.Description != "" {
+			updated.Description = body.Description
+		}
+
+		if body.DomainNameServers != nil {
+			updated.DomainNameServers = body.DomainNameServers
+		}
+
+		if body.GatewayIP != "" {
+			updated.GatewayIP = body.GatewayIP
+		}
+
+		if body.AllocationPoolStart != "" {
+			updated.AllocationPoolStart = body.AllocationPoolStart
+		}
+
+		if body.AllocationPoolEnd != "" {
+			updated.AllocationPoolEnd = body.AllocationPoolEnd
+		}
+
+		if err := validateNetworkAddressing(&updated); err != nil {
+			return nil, err
+		}
+
+		*network = updated
+		return s.renderNetwork(network.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/networks/{id}", func(r *request) (interface{}, error) {
+		network, err := s.findNetwork(r, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(s.usedAddresses(network.ID)) != 0 {
+			return nil, conflict("network %s is still in use", network.Name)
+		}
+
+		for _, cluster := range s.clusters {
+			if cluster.Network.ID == network.ID {
+				return nil, conflict("network %s is still in use by cluster %s", network.Name, cluster.Name)
+			}
+		}
+
+		delete(s.networks, network.ID)
+		return nil, nil
+	})
+}
+
+func (s *Server) findNetwork(r *request, index int) (*compute.Network, error) {
+	id, err := r.id(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.network(id)
+}
+
+func (s *Server) network(id int) (*compute.Network, error) {
+	network, found := s.networks[id]
+	if !found {
+		return nil, notFound("network", id)
+	}
+
+	return network, nil
+}
+
+func (s *Server) renderNetwork(id int) compute.Network {
+	network := *s.networks[id]
+
+	start, _ := netip.ParseAddr(network.AllocationPoolStart)
+	end, _ := netip.ParseAddr(network.AllocationPoolEnd)
+	for addr := start; addr.IsValid() && addr.Compare(end) <= 0; addr = addr.Next() {
+		network.TotalIPs++
+	}
+
+	network.UsedIPs = len(s.usedAddresses(id))
+	return network
+}
+
+// usedAddresses returns all addresses of the network which are assigned to an interface.
+func (s *Server) usedAddresses(networkID int) map[string]bool {
+	used := map[string]bool{}
+
+	for _, iface := range s.networkInterfaces {
+		if iface.NetworkID == networkID {
+			used[iface.PrivateIP] = true
+		}
+	}
+
+	for _, iface := range s.routerInterfaces {
+		if iface.NetworkID == networkID {
+			used[iface.PrivateIP] = true
+		}
+	}
+
+	return used
+}
+
+// allocateAddress validates the requested address or, if none is requested, returns the first free address of the
+// allocation pool. The gateway address can only be assigned to router interfaces.
+func (s *Server) allocateAddress(network *compute.Network, requested string, router bool) (string, error) {
+	used := s.usedAddresses(network.ID)
+
+	if requested != "" {
+		addr, err := netip.ParseAddr(requested)
+		if err != nil {
+			return "", badRequest("invalid ip address %q", requested)
+		}
+
+		prefix := netip.MustParsePrefix(network.CIDR)
+		if !prefix.Contains(addr) || addr == prefix.Addr() || addr == lastAddress(prefix) {
+			return "", badRequest("ip address %s is not a usable address of network %s", requested, network.CIDR)
+		}
+
+		if requested == network.GatewayIP && !router {
+			return "", badRequest("ip address %s is the gateway of network %s", requested, network.Name)
+		}
+
+		if used[addr.String()] {
+			return "", conflict("ip address %s is already in use", requested)
+		}
+
+		return addr.String(), nil
+	}
+
+	if router && !used[network.GatewayIP] {
+		return network.GatewayIP, nil
+	}
+
+	start := netip.MustParseAddr(network.AllocationPoolStart)
+	end := netip.MustParseAddr(network.AllocationPoolEnd)
+	for addr := start; addr.Compare(end) <= 0; addr = addr.Next() {
+		if addr.String() != network.GatewayIP && !used[addr.String()] {
+			return addr.String(), nil
+		}
+	}
+
+	return "", conflict("no free ip address left in network %s", network.Name)
+}
+
+func validateNetworkAddressing(network *compute.Network) error {
+	prefix := netip.MustParsePrefix(network.CIDR)
+
+	addresses := map[string]string{
+		"gateway ip":            network.GatewayIP,
+		"allocation pool start": network.AllocationPoolStart,
+		"allocation pool end":   network.AllocationPoolEnd,
+	}
+
+	for name, value := range addresses {
+		addr, err := netip.ParseAddr(value)
+		if err != nil || !prefix.Contains(addr) {
+			return badRequest("%s %q is not part of network %s", name, value, network.CIDR)
+		}
+	}
+
+	start := netip.MustParseAddr(network.AllocationPoolStart)
+	end := netip.MustParseAddr(network.AllocationPoolEnd)
+	if start.Compare(end) > 0 {
+		return badRequest("allocation pool start %s is after allocation pool end %s", start, end)
+	}
+
+	return nil
+}
+
+func lastAddress(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Addr().As4()
+	for bit := prefix.Bits(); bit < 32; bit++ {
+		addr[bit/8] |= 1 << (7 - bit%8)
+	}
+
+	return netip.AddrFrom4(addr)
+}
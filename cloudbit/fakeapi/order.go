@@ -0,0 +1,84 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flowswiss/goclient/common"
+)
+
+var orderStatuses = map[int]common.OrderStatus{
+	common.OrderStatusCreated:    {ID: common.OrderStatusCreated, Name: "Created"},
+	common.OrderStatusProcessing: {ID: common.OrderStatusProcessing, Name: "Processing"},
+	common.OrderStatusSucceeded:  {ID: common.OrderStatusSucceeded, Name: "Succeeded"},
+	common.OrderStatusFailed:     {ID: common.OrderStatusFailed, Name: "Failed"},
+}
+
+// order records a successfully processed order for the entity with the given id. As the fake processes everything
+// synchronously, the entity already exists when the order is returned. With HoldOrders, the order is left processing
+// instead.
+func (s *Server) order(product common.Product, entityID int) common.Ordering {
+	instance := product
+	instance.ID = entityID
+
+	status := orderStatuses[common.OrderStatusSucceeded]
+	if s.holdOrders {
+		status = orderStatuses[common.OrderStatusProcessing]
+	}
+
+	id := s.nextID()
+	s.orders[id] = &common.Order{
+		ID:        id,
+		Status:    status,
+		Product:   instance,
+		CreatedAt: common.Time(time.Now()),
+	}
+
+	return common.Ordering{Ref: fmt.Sprintf("/v4/orders/%d", id)}
+}
+
+func (s *Server) registerOrders() {
+	s.handle(http.MethodGet, "/v4/orders/{id}", func(r *request) (interface{}, error) {
+		id, err := r.id(0)
+		if err != nil {
+			return nil, err
+		}
+
+		order, found := s.orders[id]
+		if !found {
+			return nil, notFound("order", id)
+		}
+
+		return order, nil
+	})
+}
+
+// HoldOrders makes new orders stay in processing until their status is changed using SetOrderStatus, e.g. to test
+// waiting for an order which does not complete in time.
+func (s *Server) HoldOrders(hold bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.holdOrders = hold
+}
+
+// SetOrderStatus changes the status of an order (e.g. common.OrderStatusFailed). The ordered entity is left as is, as
+// the fake creates it when the order is submitted.
+func (s *Server) SetOrderStatus(orderID int, status int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, found := s.orders[orderID]
+	if !found {
+		return fmt.Errorf("order %d does not exist", orderID)
+	}
+
+	orderStatus, found := orderStatuses[status]
+	if !found {
+		return fmt.Errorf("invalid order status %d", status)
+	}
+
+	order.Status = orderStatus
+	return nil
+}
@@ -0,0 +1,329 @@
+package fakeapi
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/flowswiss/goclient/compute"
+)
+
+type routerInterface struct {
+	ID        int
+	RouterID  int
+	NetworkID int
+	PrivateIP string
+}
+
+type routerRoute struct {
+	compute.Route
+	RouterID int
+}
+
+func (s *Server) registerRouters() {
+	s.handle(http.MethodGet, "/v4/compute/routers", func(r *request) (interface{}, error) {
+		routers := []compute.Router{}
+		for _, id := range sortedIDs(s.routers) {
+			routers = append(routers, s.renderRouter(id))
+		}
+
+		return list{routers}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/routers/{id}", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderRouter(router.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/routers", func(r *request) (interface{}, error) {
+		var body compute.RouterCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		router := &compute.Router{
+			ID:          s.nextID(),
+			Name:        body.Name,
+			Description: body.Description,
+			Location:    location,
+		}
+
+		if err := s.setRouterPublic(router, body.Public); err != nil {
+			return nil, err
+		}
+
+		s.routers[router.ID] = router
+		return s.renderRouter(router.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/routers/{id}", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		// the public flag is omitted when it is false, so it has to be distinguished from an absent field
+		var body struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Public      *bool  `json:"public"`
+		}
+
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		if body.Name != "" {
+			router.Name = body.Name
+		}
+
+		if body.Description != "" {
+			router.Description = body.Description
+		}
+
+		if body.Public != nil {
+			if err := s.setRouterPublic(router, *body.Public); err != nil {
+				return nil, err
+			}
+		}
+
+		return s.renderRouter(router.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/routers/{id}", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, iface := range s.routerInterfaces {
+			if iface.RouterID == router.ID {
+				return nil, conflict("router %s still has interfaces", router.Name)
+			}
+		}
+
+		for id, route := range s.routerRoutes {
+			if route.RouterID == router.ID {
+				delete(s.routerRoutes, id)
+			}
+		}
+
+		delete(s.routers, router.ID)
+		return nil, nil
+	})
+
+	s.registerRouterInterfaces()
+	s.registerRouterRoutes()
+}
+
+func (s *Server) registerRouterInterfaces() {
+	s.handle(http.MethodGet, "/v4/compute/routers/{id}/interfaces", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		interfaces := []compute.RouterInterface{}
+		for _, id := range sortedIDs(s.routerInterfaces) {
+			if s.routerInterfaces[id].RouterID == router.ID {
+				interfaces = append(interfaces, s.renderRouterInterface(id))
+			}
+		}
+
+		return list{interfaces}, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/routers/{id}/interfaces", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.RouterInterfaceCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		network, err := s.network(body.NetworkID)
+		if err != nil || network.Location.ID != router.Location.ID {
+			return nil, badRequest("network %d does not exist in location %s", body.NetworkID, router.Location.Name)
+		}
+
+		for _, iface := range s.routerInterfaces {
+			if iface.RouterID == router.ID && iface.NetworkID == network.ID {
+				return nil, conflict("router %s is already connected to network %s", router.Name, network.Name)
+			}
+		}
+
+		privateIP, err := s.allocateAddress(network, body.PrivateIP, true)
+		if err != nil {
+			return nil, err
+		}
+
+		iface := &routerInterface{
+			ID:        s.nextID(),
+			RouterID:  router.ID,
+			NetworkID: network.ID,
+			PrivateIP: privateIP,
+		}
+
+		s.routerInterfaces[iface.ID] = iface
+		return s.renderRouterInterface(iface.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/routers/{id}/interfaces/{iid}", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := r.id(1)
+		if err != nil {
+			return nil, err
+		}
+
+		iface, found := s.routerInterfaces[id]
+		if !found || iface.RouterID != router.ID {
+			return nil, notFound("router interface", id)
+		}
+
+		delete(s.routerInterfaces, id)
+		return nil, nil
+	})
+}
+
+func (s *Server) registerRouterRoutes() {
+	s.handle(http.MethodGet, "/v4/compute/routers/{id}/routes", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		routes := []compute.Route{}
+		for _, id := range sortedIDs(s.routerRoutes) {
+			if route := s.routerRoutes[id]; route.RouterID == router.ID {
+				routes = append(routes, route.Route)
+			}
+		}
+
+		return list{routes}, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/routers/{id}/routes", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.RouteCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		destination, err := netip.ParsePrefix(body.Destination)
+		if err != nil {
+			return nil, invalidField("destination", "invalid destination %q", body.Destination)
+		}
+
+		nextHop, err := netip.ParseAddr(body.NextHop)
+		if err != nil {
+			return nil, invalidField("next_hop", "invalid next hop %q", body.NextHop)
+		}
+
+		for _, route := range s.routerRoutes {
+			if route.RouterID == router.ID && route.Destination == destination.String() {
+				return nil, conflict("router %s already has a route to %s", router.Name, destination)
+			}
+		}
+
+		route := &routerRoute{
+			Route: compute.Route{
+				ID:          s.nextID(),
+				Destination: destination.String(),
+				NextHop:     nextHop.String(),
+			},
+			RouterID: router.ID,
+		}
+
+		s.routerRoutes[route.ID] = route
+		return route.Route, nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/routers/{id}/routes/{rid}", func(r *request) (interface{}, error) {
+		router, err := s.findRouter(r)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := r.id(1)
+		if err != nil {
+			return nil, err
+		}
+
+		route, found := s.routerRoutes[id]
+		if !found || route.RouterID != router.ID {
+			return nil, notFound("route", id)
+		}
+
+		delete(s.routerRoutes, id)
+		return nil, nil
+	})
+}
+
+func (s *Server) findRouter(r *request) (*compute.Router, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	router, found := s.routers[id]
+	if !found {
+		return nil, notFound("router", id)
+	}
+
+	return router, nil
+}
+
+// setRouterPublic allocates or releases the public ip used for source nat of the router.
+func (s *Server) setRouterPublic(router *compute.Router, public bool) error {
+	router.Public = public
+	router.SourceNAT = public
+
+	if !public {
+		router.PublicIP = ""
+		return nil
+	}
+
+	if router.PublicIP == "" {
+		publicIP, err := s.allocatePublicIP()
+		if err != nil {
+			return err
+		}
+
+		router.PublicIP = publicIP
+	}
+
+	return nil
+}
+
+func (s *Server) renderRouter(id int) compute.Router {
+	return *s.routers[id]
+}
+
+func (s *Server) renderRouterInterface(id int) compute.RouterInterface {
+	iface := s.routerInterfaces[id]
+
+	return compute.RouterInterface{
+		ID:        iface.ID,
+		PrivateIP: iface.PrivateIP,
+		Network:   s.renderNetwork(iface.NetworkID),
+	}
+}
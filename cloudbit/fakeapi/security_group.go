@@ -0,0 +1,262 @@
+package fakeapi
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/flowswiss/goclient/compute"
+)
+
+type securityGroupRule struct {
+	compute.SecurityGroupRule
+	SecurityGroupID       int
+	RemoteSecurityGroupID int
+}
+
+func (s *Server) registerSecurityGroups() {
+	s.handle(http.MethodGet, "/v4/compute/security-groups", func(r *request) (interface{}, error) {
+		securityGroups := []compute.SecurityGroup{}
+		for _, id := range sortedIDs(s.securityGroups) {
+			securityGroups = append(securityGroups, *s.securityGroups[id])
+		}
+
+		return list{securityGroups}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/security-groups/{id}", func(r *request) (interface{}, error) {
+		securityGroup, err := s.findSecurityGroup(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return *securityGroup, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/security-groups", func(r *request) (interface{}, error) {
+		var body compute.SecurityGroupCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		securityGroup := &compute.SecurityGroup{
+			ID:          s.nextID(),
+			Name:        body.Name,
+			Description: body.Description,
+			Location:    location,
+		}
+
+		s.securityGroups[securityGroup.ID] = securityGroup
+		return *securityGroup, nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/security-groups/{id}", func(r *request) (interface{}, error) {
+		securityGroup, err := s.findSecurityGroup(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if securityGroup.Immutable {
+			return nil, conflict("security group %s is immutable", securityGroup.Name)
+		}
+
+		var body compute.SecurityGroupUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		securityGroup.Name = body.Name
+		securityGroup.Description = body.Description
+		return *securityGroup, nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/security-groups/{id}", func(r *request) (interface{}, error) {
+		securityGroup, err := s.findSecurityGroup(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if securityGroup.Immutable {
+			return nil, conflict("security group %s is immutable", securityGroup.Name)
+		}
+
+		for _, iface := range s.networkInterfaces {
+			if containsInt(iface.SecurityGroupIDs, securityGroup.ID) {
+				return nil, conflict("security group %s is still in use", securityGroup.Name)
+			}
+		}
+
+		for _, rule := range s.securityGroupRules {
+			if rule.RemoteSecurityGroupID == securityGroup.ID && rule.SecurityGroupID != securityGroup.ID {
+				return nil, conflict("security group %s is still referenced by a rule", securityGroup.Name)
+			}
+		}
+
+		for id, rule := range s.securityGroupRules {
+			if rule.SecurityGroupID == securityGroup.ID {
+				delete(s.securityGroupRules, id)
+			}
+		}
+
+		delete(s.securityGroups, securityGroup.ID)
+		return nil, nil
+	})
+
+	s.registerSecurityGroupRules()
+}
+
+func (s *Server) registerSecurityGroupRules() {
+	s.handle(http.MethodGet, "/v4/compute/security-groups/{id}/rules", func(r *request) (interface{}, error) {
+		securityGroup, err := s.findSecurityGroup(r)
+		if err != nil {
+			return nil, err
+		}
+
+		rules := []compute.SecurityGroupRule{}
+		for _, id := range sortedIDs(s.securityGroupRules) {
+			if s.securityGroupRules[id].SecurityGroupID == securityGroup.ID {
+				rules = append(rules, s.renderSecurityGroupRule(id))
+			}
+		}
+
+		return list{rules}, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/security-groups/{id}/rules", func(r *request) (interface{}, error) {
+		securityGroup, err := s.findSecurityGroup(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.SecurityGroupRuleOptions
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		rule := &securityGroupRule{
+			SecurityGroupRule: compute.SecurityGroupRule{ID: s.nextID()},
+			SecurityGroupID:   securityGroup.ID,
+		}
+
+		if err := s.applySecurityGroupRuleOptions(rule, body); err != nil {
+			return nil, err
+		}
+
+		s.securityGroupRules[rule.ID] = rule
+		return s.renderSecurityGroupRule(rule.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/security-groups/{id}/rules/{rid}", func(r *request) (interface{}, error) {
+		rule, err := s.findSecurityGroupRule(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.SecurityGroupRuleOptions
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		updated := *rule
+		if err := s.applySecurityGroupRuleOptions(&updated, body); err != nil {
+			return nil, err
+		}
+
+		*rule = updated
+		return s.renderSecurityGroupRule(rule.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/security-groups/{id}/rules/{rid}", func(r *request) (interface{}, error) {
+		rule, err := s.findSecurityGroupRule(r)
+		if err != nil {
+			return nil, err
+		}
+
+		delete(s.securityGroupRules, rule.ID)
+		return nil, nil
+	})
+}
+
+func (s *Server) findSecurityGroup(r *request) (*compute.SecurityGroup, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	securityGroup, found := s.securityGroups[id]
+	if !found {
+		return nil, notFound("security group", id)
+	}
+
+	return securityGroup, nil
+}
+
+func (s *Server) findSecurityGroupRule(r *request) (*securityGroupRule, error) {
+	securityGroup, err := s.findSecurityGroup(r)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := r.id(1)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, found := s.securityGroupRules[id]
+	if !found || rule.SecurityGroupID != securityGroup.ID {
+		return nil, notFound("security group rule", id)
+	}
+
+	return rule, nil
+}
+
+func (s *Server) applySecurityGroupRuleOptions(rule *securityGroupRule, options compute.SecurityGroupRuleOptions) error {
+	if options.Direction != compute.DirectionIngress && options.Direction != compute.DirectionEgress {
+		return badRequest("invalid direction %q", options.Direction)
+	}
+
+	if options.IPRange != "" && options.RemoteSecurityGroupID != 0 {
+		return badRequest("ip range and remote security group are mutually exclusive")
+	}
+
+	if options.IPRange != "" {
+		if _, err := netip.ParsePrefix(options.IPRange); err != nil {
+			return invalidField("ip_range", "invalid ip range %q", options.IPRange)
+		}
+	}
+
+	if options.RemoteSecurityGroupID != 0 {
+		if _, found := s.securityGroups[options.RemoteSecurityGroupID]; !found {
+			return badRequest("security group %d does not exist", options.RemoteSecurityGroupID)
+		}
+	}
+
+	if options.FromPort > options.ToPort {
+		return badRequest("from port %d is greater than to port %d", options.FromPort, options.ToPort)
+	}
+
+	rule.Direction = options.Direction
+	rule.Protocol = options.Protocol
+	rule.FromPort = options.FromPort
+	rule.ToPort = options.ToPort
+	rule.ICMPType = options.ICMPType
+	rule.ICMPCode = options.ICMPCode
+	rule.IPRange = options.IPRange
+	rule.RemoteSecurityGroupID = options.RemoteSecurityGroupID
+	return nil
+}
+
+func (s *Server) renderSecurityGroupRule(id int) compute.SecurityGroupRule {
+	rule := s.securityGroupRules[id]
+	rendered := rule.SecurityGroupRule
+
+	if securityGroup, found := s.securityGroups[rule.RemoteSecurityGroupID]; found {
+		rendered.RemoteSecurityGroup = *securityGroup
+	}
+
+	return rendered
+}
@@ -0,0 +1,492 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+)
+
+var serverStatuses = map[int]compute.ServerStatus{
+	compute.ServerStatusRunning: {ID: compute.ServerStatusRunning, Name: "Running", Key: "running"},
+	compute.ServerStatusStopped: {ID: compute.ServerStatusStopped, Name: "Stopped", Key: "stopped"},
+}
+
+// networkInterface is a network interface of a server or load balancer.
+type networkInterface struct {
+	ID               int
+	OwnerKind        string
+	OwnerID          int
+	NetworkID        int
+	PrivateIP        string
+	MacAddress       string
+	Security         bool
+	SecurityGroupIDs []int
+}
+
+func (s *Server) registerServers() {
+	s.handle(http.MethodGet, "/v4/compute/instances", func(r *request) (interface{}, error) {
+		servers := []compute.Server{}
+		for _, id := range sortedIDs(s.servers) {
+			servers = append(servers, s.renderServer(id))
+		}
+
+		return list{servers}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/instances/{id}", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderServer(server.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/instances", func(r *request) (interface{}, error) {
+		var body compute.ServerCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		product, err := s.availableProduct(body.ProductID, location)
+		if err != nil {
+			return nil, err
+		}
+
+		image, err := s.image(body.ImageID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !containsInt(image.AvailableLocations, location.ID) {
+			return nil, badRequest("image %d is not available in location %s", image.ID, location.Name)
+		}
+
+		network, err := s.network(body.NetworkID)
+		if err != nil || network.Location.ID != location.ID {
+			return nil, badRequest("network %d does not exist in location %s", body.NetworkID, location.Name)
+		}
+
+		var keyPair compute.KeyPair
+		if body.KeyPairID != 0 {
+			found, exists := s.keyPairs[body.KeyPairID]
+			if !exists {
+				return nil, badRequest("key pair %d does not exist", body.KeyPairID)
+			}
+
+			keyPair = *found
+		} else if body.Password == "" {
+			return nil, badRequest("either a key pair or a password is required")
+		}
+
+		privateIP, err := s.allocateAddress(network, body.PrivateIP, false)
+		if err != nil {
+			return nil, err
+		}
+
+		server := &compute.Server{
+			ID:       s.nextID(),
+			Name:     body.Name,
+			Status:   serverStatuses[compute.ServerStatusRunning],
+			Image:    image,
+			Product:  product,
+			Location: location,
+			KeyPair:  keyPair,
+		}
+
+		s.servers[server.ID] = server
+		iface := s.createNetworkInterface(attachmentServer, server.ID, network, privateIP)
+
+		if body.AttachExternalIP {
+			eip, err := s.createElasticIP(location)
+			if err != nil {
+				return nil, err
+			}
+
+			eip.InterfaceID = iface.ID
+		}
+
+		return s.order(product, server.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/instances/{id}", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.ServerUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		if body.Name != "" {
+			server.Name = body.Name
+		}
+
+		return s.renderServer(server.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/instances/{id}/action", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.ServerPerform
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		switch body.Action {
+		case "start", "reboot":
+			server.Status = serverStatuses[compute.ServerStatusRunning]
+		case "stop":
+			server.Status = serverStatuses[compute.ServerStatusStopped]
+		default:
+			return nil, badRequest("unknown action %q", body.Action)
+		}
+
+		return s.renderServer(server.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/instances/{id}/upgrade", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.ServerUpgrade
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		product, err := s.availableProduct(body.ProductID, server.Location)
+		if err != nil {
+			return nil, err
+		}
+
+		if product.Type.ID != server.Product.Type.ID {
+			return nil, badRequest("product %s can not be used for servers", product.Name)
+		}
+
+		server.Product = product
+		return s.order(product, server.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/instances/{id}", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		deleteElasticIP, _ := strconv.ParseBool(r.URL.Query().Get("delete_elastic_ip"))
+
+		for _, id := range sortedIDs(s.networkInterfaces) {
+			iface := s.networkInterfaces[id]
+			if iface.OwnerKind == attachmentServer && iface.OwnerID == server.ID {
+				s.deleteNetworkInterface(iface, deleteElasticIP)
+			}
+		}
+
+		for _, volume := range s.volumes {
+			if volume.ServerID == server.ID {
+				volume.ServerID = 0
+			}
+		}
+
+		delete(s.servers, server.ID)
+		return nil, nil
+	})
+
+	s.registerServerNetworkInterfaces()
+
+	s.handle(http.MethodGet, "/v4/compute/instances/{id}/elastic-ips", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.attachedElasticIPs(attachmentServer, server.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/instances/{id}/elastic-ips", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.attachElasticIP(r, attachmentServer, server.ID, server.Location)
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/instances/{id}/elastic-ips/{eid}", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.detachElasticIP(r, attachmentServer, server.ID)
+	})
+}
+
+func (s *Server) registerServerNetworkInterfaces() {
+	s.handle(http.MethodGet, "/v4/compute/instances/{id}/network-interfaces", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		interfaces := []compute.NetworkInterface{}
+		for _, id := range sortedIDs(s.networkInterfaces) {
+			iface := s.networkInterfaces[id]
+			if iface.OwnerKind == attachmentServer && iface.OwnerID == server.ID {
+				interfaces = append(interfaces, s.renderNetworkInterface(id))
+			}
+		}
+
+		return list{interfaces}, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/instances/{id}/network-interfaces", func(r *request) (interface{}, error) {
+		server, err := s.findServer(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.NetworkInterfaceCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		network, err := s.network(body.NetworkID)
+		if err != nil || network.Location.ID != server.Location.ID {
+			return nil, badRequest("network %d does not exist in location %s", body.NetworkID, server.Location.Name)
+		}
+
+		privateIP, err := s.allocateAddress(network, body.PrivateIP, false)
+		if err != nil {
+			return nil, err
+		}
+
+		iface := s.createNetworkInterface(attachmentServer, server.ID, network, privateIP)
+		return s.renderNetworkInterface(iface.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/instances/{id}/network-interfaces/{nid}", func(r *request) (interface{}, error) {
+		iface, err := s.findServerNetworkInterface(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.elasticIPOfInterface(iface.ID) != nil {
+			return nil, conflict("network interface %d still has an elastic ip attached", iface.ID)
+		}
+
+		s.deleteNetworkInterface(iface, false)
+		return nil, nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/instances/{id}/network-interfaces/{nid}/security", func(r *request) (interface{}, error) {
+		iface, err := s.findServerNetworkInterface(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.NetworkInterfaceSecurityUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		iface.Security = body.Security
+		return s.renderNetworkInterface(iface.ID), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/instances/{id}/network-interfaces/{nid}/security-groups", func(r *request) (interface{}, error) {
+		iface, err := s.findServerNetworkInterface(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.NetworkInterfaceSecurityGroupUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		for _, id := range body.SecurityGroupIDs {
+			if _, found := s.securityGroups[id]; !found {
+				return nil, badRequest("security group %d does not exist", id)
+			}
+		}
+
+		iface.SecurityGroupIDs = body.SecurityGroupIDs
+		return s.renderNetworkInterface(iface.ID), nil
+	})
+}
+
+func (s *Server) findServer(r *request) (*compute.Server, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	server, found := s.servers[id]
+	if !found {
+		return nil, notFound("server", id)
+	}
+
+	return server, nil
+}
+
+func (s *Server) findServerNetworkInterface(r *request) (*networkInterface, error) {
+	server, err := s.findServer(r)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := r.id(1)
+	if err != nil {
+		return nil, err
+	}
+
+	iface, found := s.networkInterfaces[id]
+	if !found || iface.OwnerKind != attachmentServer || iface.OwnerID != server.ID {
+		return nil, notFound("network interface", id)
+	}
+
+	return iface, nil
+}
+
+func (s *Server) renderServer(id int) compute.Server {
+	server := *s.servers[id]
+	server.Networks = []compute.ServerNetworkAttachment{}
+
+	for _, attachment := range s.networkAttachments(attachmentServer, id) {
+		networkAttachment := compute.ServerNetworkAttachment{Network: attachment.Network}
+		for _, iface := range attachment.Interfaces {
+			networkAttachment.Interfaces = append(networkAttachment.Interfaces, compute.AttachedNetworkInterface(iface))
+		}
+
+		server.Networks = append(server.Networks, networkAttachment)
+	}
+
+	return server
+}
+
+// networkAttachments groups the network interfaces of a server or load balancer by their network.
+func (s *Server) networkAttachments(ownerKind string, ownerID int) []compute.LoadBalancerNetworkAttachment {
+	var attachments []compute.LoadBalancerNetworkAttachment
+	index := map[int]int{}
+
+	for _, id := range sortedIDs(s.networkInterfaces) {
+		iface := s.networkInterfaces[id]
+		if iface.OwnerKind != ownerKind || iface.OwnerID != ownerID {
+			continue
+		}
+
+		if _, found := index[iface.NetworkID]; !found {
+			index[iface.NetworkID] = len(attachments)
+			attachments = append(attachments, compute.LoadBalancerNetworkAttachment{Network: s.renderNetwork(iface.NetworkID)})
+		}
+
+		attached := compute.AttachedLoadBalancerInterface{ID: iface.ID, PrivateIP: iface.PrivateIP}
+		if eip := s.elasticIPOfInterface(iface.ID); eip != nil {
+			attached.PublicIP = eip.PublicIP
+		}
+
+		attachment := &attachments[index[iface.NetworkID]]
+		attachment.Interfaces = append(attachment.Interfaces, attached)
+	}
+
+	return attachments
+}
+
+func (s *Server) renderNetworkInterface(id int) compute.NetworkInterface {
+	iface := s.networkInterfaces[id]
+
+	rendered := compute.NetworkInterface{
+		ID:             iface.ID,
+		PrivateIP:      iface.PrivateIP,
+		MacAddress:     iface.MacAddress,
+		Network:        s.renderNetwork(iface.NetworkID),
+		SecurityGroups: []compute.SecurityGroup{},
+		Security:       iface.Security,
+	}
+
+	if eip := s.elasticIPOfInterface(id); eip != nil {
+		rendered.AttachedElasticIP = s.renderElasticIP(eip.ID)
+	}
+
+	for _, securityGroupID := range iface.SecurityGroupIDs {
+		if securityGroup, found := s.securityGroups[securityGroupID]; found {
+			rendered.SecurityGroups = append(rendered.SecurityGroups, *securityGroup)
+		}
+	}
+
+	return rendered
+}
+
+func (s *Server) createNetworkInterface(ownerKind string, ownerID int, network *compute.Network, privateIP string) *networkInterface {
+	id := s.nextID()
+	iface := &networkInterface{
+		ID:         id,
+		OwnerKind:  ownerKind,
+		OwnerID:    ownerID,
+		NetworkID:  network.ID,
+		PrivateIP:  privateIP,
+		MacAddress: fmt.Sprintf("fa:16:3e:%02x:%02x:%02x", id>>16&0xff, id>>8&0xff, id&0xff),
+		Security:   true,
+	}
+
+	s.networkInterfaces[id] = iface
+	return iface
+}
+
+// deleteNetworkInterface removes the interface and either deletes or detaches its elastic ip.
+func (s *Server) deleteNetworkInterface(iface *networkInterface, deleteElasticIP bool) {
+	if eip := s.elasticIPOfInterface(iface.ID); eip != nil {
+		eip.InterfaceID = 0
+		if deleteElasticIP {
+			delete(s.elasticIPs, eip.ID)
+		}
+	}
+
+	delete(s.networkInterfaces, iface.ID)
+}
+
+func (s *Server) ownerName(iface *networkInterface) string {
+	switch iface.OwnerKind {
+	case attachmentServer:
+		if server, found := s.servers[iface.OwnerID]; found {
+			return server.Name
+		}
+	case attachmentLoadBalancer:
+		if loadBalancer, found := s.loadBalancers[iface.OwnerID]; found {
+			return loadBalancer.Name
+		}
+	}
+
+	return ""
+}
+
+// briefProduct returns the reference to a product used in other entities.
+func briefProduct(product common.Product) common.BriefProduct {
+	return common.BriefProduct{ID: product.ID, Name: product.Name, Type: product.Type.Key}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
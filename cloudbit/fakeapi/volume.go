@@ -0,0 +1,368 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+)
+
+var (
+	volumeStatusAvailable   = compute.VolumeStatus{ID: compute.VolumeStatusAvailable, Name: "Available", Key: "available"}
+	volumeStatusInUse       = compute.VolumeStatus{ID: compute.VolumeStatusInUse, Name: "In Use", Key: "in-use"}
+	snapshotStatusAvailable = compute.SnapshotStatus{ID: compute.SnapshotStatusAvailable, Name: "Available", Key: "available"}
+)
+
+type volume struct {
+	compute.Volume
+	ServerID int
+}
+
+type snapshot struct {
+	compute.Snapshot
+	VolumeID int
+}
+
+func (s *Server) registerVolumes() {
+	s.handle(http.MethodGet, "/v4/compute/volumes", func(r *request) (interface{}, error) {
+		volumes := []compute.Volume{}
+		for _, id := range sortedIDs(s.volumes) {
+			volumes = append(volumes, s.renderVolume(id))
+		}
+
+		return list{volumes}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/volumes/{id}", func(r *request) (interface{}, error) {
+		vol, err := s.findVolume(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderVolume(vol.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/volumes", func(r *request) (interface{}, error) {
+		var body compute.VolumeCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		location, err := s.location(body.LocationID)
+		if err != nil {
+			return nil, err
+		}
+
+		product, err := s.availableProduct(ProductVolume, location)
+		if err != nil {
+			return nil, err
+		}
+
+		if body.Size <= 0 {
+			return nil, invalidField("size", "invalid size %d", body.Size)
+		}
+
+		if body.SnapshotID != 0 {
+			snap, found := s.snapshots[body.SnapshotID]
+			if !found {
+				return nil, badRequest("snapshot %d does not exist", body.SnapshotID)
+			}
+
+			if body.Size < snap.Size {
+				return nil, badRequest("size must be at least the size of the snapshot (%d)", snap.Size)
+			}
+		}
+
+		if body.InstanceID != 0 {
+			if err := s.checkVolumeAttachable(body.InstanceID, location); err != nil {
+				return nil, err
+			}
+		}
+
+		id := s.nextID()
+		s.volumes[id] = &volume{
+			Volume: compute.Volume{
+				ID:           id,
+				Product:      product,
+				Location:     location,
+				Name:         body.Name,
+				Size:         body.Size,
+				SerialNumber: fmt.Sprintf("fake-volume-%d", id),
+				CreatedAt:    common.Time(time.Now()),
+			},
+			ServerID: body.InstanceID,
+		}
+
+		return s.renderVolume(id), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/volumes/{id}", func(r *request) (interface{}, error) {
+		vol, err := s.findVolume(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.VolumeUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		vol.Name = body.Name
+		return s.renderVolume(vol.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/volumes/{id}", func(r *request) (interface{}, error) {
+		vol, err := s.findVolume(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if vol.ServerID != 0 {
+			return nil, conflict("volume %s is still attached", vol.Name)
+		}
+
+		for _, snap := range s.snapshots {
+			if snap.VolumeID == vol.ID {
+				return nil, conflict("volume %s still has snapshots", vol.Name)
+			}
+		}
+
+		delete(s.volumes, vol.ID)
+		return nil, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/volumes/{id}/instances", func(r *request) (interface{}, error) {
+		vol, err := s.findVolume(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.VolumeAttach
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		if vol.ServerID != 0 {
+			return nil, conflict("volume %s is already attached", vol.Name)
+		}
+
+		if err := s.checkVolumeAttachable(body.InstanceID, vol.Location); err != nil {
+			return nil, err
+		}
+
+		vol.ServerID = body.InstanceID
+		return s.renderVolume(vol.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/volumes/{id}/instances/{sid}", func(r *request) (interface{}, error) {
+		vol, err := s.findVolume(r)
+		if err != nil {
+			return nil, err
+		}
+
+		serverID, err := r.id(1)
+		if err != nil {
+			return nil, err
+		}
+
+		if vol.ServerID != serverID {
+			return nil, notFound("volume attachment", serverID)
+		}
+
+		vol.ServerID = 0
+		return nil, nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/volumes/{id}/revert", func(r *request) (interface{}, error) {
+		vol, err := s.findVolume(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.VolumeRevert
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		snap, found := s.snapshots[body.SnapshotID]
+		if !found || snap.VolumeID != vol.ID {
+			return nil, badRequest("snapshot %d does not belong to volume %s", body.SnapshotID, vol.Name)
+		}
+
+		return s.renderVolume(vol.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/volumes/{id}/upgrade", func(r *request) (interface{}, error) {
+		vol, err := s.findVolume(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.VolumeExpand
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		if body.Size <= vol.Size {
+			return nil, invalidField("size", "volumes can only be expanded, the size must be greater than %d", vol.Size)
+		}
+
+		vol.Size = body.Size
+		return s.renderVolume(vol.ID), nil
+	})
+
+	s.registerSnapshots()
+}
+
+func (s *Server) registerSnapshots() {
+	s.handle(http.MethodGet, "/v4/compute/snapshots", func(r *request) (interface{}, error) {
+		snapshots := []compute.Snapshot{}
+		for _, id := range sortedIDs(s.snapshots) {
+			snapshots = append(snapshots, s.renderSnapshot(id))
+		}
+
+		return list{snapshots}, nil
+	})
+
+	s.handle(http.MethodGet, "/v4/compute/snapshots/{id}", func(r *request) (interface{}, error) {
+		snap, err := s.findSnapshot(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.renderSnapshot(snap.ID), nil
+	})
+
+	s.handle(http.MethodPost, "/v4/compute/snapshots", func(r *request) (interface{}, error) {
+		var body compute.SnapshotCreate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		vol, found := s.volumes[body.VolumeID]
+		if !found {
+			return nil, badRequest("volume %d does not exist", body.VolumeID)
+		}
+
+		product, err := s.availableProduct(ProductSnapshot, vol.Location)
+		if err != nil {
+			return nil, err
+		}
+
+		id := s.nextID()
+		s.snapshots[id] = &snapshot{
+			Snapshot: compute.Snapshot{
+				ID:        id,
+				Name:      body.Name,
+				Size:      vol.Size,
+				Status:    snapshotStatusAvailable,
+				Product:   product,
+				CreatedAt: common.Time(time.Now()),
+			},
+			VolumeID: vol.ID,
+		}
+
+		return s.renderSnapshot(id), nil
+	})
+
+	s.handle(http.MethodPatch, "/v4/compute/snapshots/{id}", func(r *request) (interface{}, error) {
+		snap, err := s.findSnapshot(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var body compute.SnapshotUpdate
+		if err := r.decode(&body); err != nil {
+			return nil, err
+		}
+
+		if body.Name != "" {
+			snap.Name = body.Name
+		}
+
+		return s.renderSnapshot(snap.ID), nil
+	})
+
+	s.handle(http.MethodDelete, "/v4/compute/snapshots/{id}", func(r *request) (interface{}, error) {
+		snap, err := s.findSnapshot(r)
+		if err != nil {
+			return nil, err
+		}
+
+		delete(s.snapshots, snap.ID)
+		return nil, nil
+	})
+}
+
+func (s *Server) findVolume(r *request) (*volume, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	vol, found := s.volumes[id]
+	if !found {
+		return nil, notFound("volume", id)
+	}
+
+	return vol, nil
+}
+
+func (s *Server) findSnapshot(r *request) (*snapshot, error) {
+	id, err := r.id(0)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, found := s.snapshots[id]
+	if !found {
+		return nil, notFound("snapshot", id)
+	}
+
+	return snap, nil
+}
+
+func (s *Server) checkVolumeAttachable(serverID int, location common.Location) error {
+	server, found := s.servers[serverID]
+	if !found {
+		return badRequest("server %d does not exist", serverID)
+	}
+
+	if server.Location.ID != location.ID {
+		return badRequest("server %s is not in location %s", server.Name, location.Name)
+	}
+
+	return nil
+}
+
+func (s *Server) renderVolume(id int) compute.Volume {
+	vol := s.volumes[id]
+	rendered := vol.Volume
+
+	rendered.Status = volumeStatusAvailable
+	if _, found := s.servers[vol.ServerID]; found {
+		rendered.Status = volumeStatusInUse
+		rendered.AttachedTo = s.renderServer(vol.ServerID)
+	}
+
+	for _, snap := range s.snapshots {
+		if snap.VolumeID == id {
+			rendered.Snapshots++
+		}
+	}
+
+	return rendered
+}
+
+func (s *Server) renderSnapshot(id int) compute.Snapshot {
+	snap := s.snapshots[id]
+	rendered := snap.Snapshot
+
+	if _, found := s.volumes[snap.VolumeID]; found {
+		rendered.Volume = s.renderVolume(snap.VolumeID)
+	}
+
+	return rendered
+}
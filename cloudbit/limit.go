@@ -0,0 +1,110 @@
+package cloudbit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// limitTransport limits the number of concurrent requests and the rate at which requests are sent to the api. Requests
+// exceeding the limits are queued until they can be sent, instead of being throttled by the api.
+type limitTransport struct {
+	base http.RoundTripper
+
+	// semaphore limits the number of concurrent requests, it is nil if the number is unlimited
+	semaphore chan struct{}
+
+	// interval is the minimum time between two requests, it is zero if the rate is unlimited
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newLimitTransport(base http.RoundTripper, maxConcurrentRequests int, maxRequestsPerSecond float64) *limitTransport {
+	l := &limitTransport{base: base}
+
+	if maxConcurrentRequests > 0 {
+		l.semaphore = make(chan struct{}, maxConcurrentRequests)
+	}
+
+	if maxRequestsPerSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / maxRequestsPerSecond)
+	}
+
+	return l
+}
+
+func (l *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+
+	if l.semaphore != nil {
+		select {
+		case l.semaphore <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		defer func() { <-l.semaphore }()
+	}
+
+	if err := l.waitForRate(ctx); err != nil {
+		return nil, err
+	}
+
+	if wait := time.Since(start); wait >= time.Millisecond {
+		msg := fmt.Sprintf("request to `%s %s` was queued for %s", req.Method, req.URL.String(), wait)
+		tflog.Debug(ctx, msg, map[string]interface{}{
+			"method":              req.Method,
+			"url":                 req.URL.String(),
+			"queued_ms":           wait.Milliseconds(),
+			"concurrent_requests": len(l.semaphore),
+		})
+	}
+
+	return l.transport().RoundTrip(req)
+}
+
+// waitForRate reserves the next slot permitted by the rate limit and waits until it has been reached.
+func (l *limitTransport) waitForRate(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := time.Until(slot)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *limitTransport) transport() http.RoundTripper {
+	if l.base == nil {
+		return http.DefaultTransport
+	}
+
+	return l.base
+}
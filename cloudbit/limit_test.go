@@ -0,0 +1,108 @@
+package cloudbit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLimitTransport_Concurrency(t *testing.T) {
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := &http.Client{Transport: newLimitTransport(nil, 2, 0)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			res, err := client.Do(req)
+			if err != nil {
+				t.Errorf("unable to send request: %s", err)
+				return
+			}
+			_ = res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", peak)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unable to decode log entries: %s", err)
+	}
+
+	queued := 0
+	for _, entry := range entries {
+		if _, ok := entry["queued_ms"]; ok {
+			queued++
+		}
+	}
+
+	if queued == 0 {
+		t.Error("expected the queued requests to be logged")
+	}
+}
+
+func TestLimitTransport_Rate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newLimitTransport(nil, 0, 50)}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		res, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unable to send request: %s", err)
+		}
+		_ = res.Body.Close()
+	}
+
+	// the first request is sent immediately, the others are spaced by 20ms
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("expected the requests to be rate limited, took %s", elapsed)
+	}
+}
+
+func TestLimitTransport_Cancel(t *testing.T) {
+	transport := newLimitTransport(nil, 1, 0)
+	transport.semaphore <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.cloudbit.invalid/", nil)
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Error("expected the queued request to be cancelled")
+	}
+}
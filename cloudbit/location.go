@@ -0,0 +1,349 @@
+package cloudbit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/filter"
+)
+
+// Names of the modules as listed by the api (`GET /v4/entities/modules`). The names of the compute modules are taken
+// from the responses recorded in goclient (flow/location_test.go), the identifiers of the modules are looked up by
+// their name and are not hard-coded.
+const (
+	moduleCompute           = "Compute"
+	moduleComputeNetworking = "Compute Networking"
+	moduleKubernetes        = "Kubernetes"
+)
+
+const productTypeLoadBalancer = "compute-engine-load-balancer"
+
+var (
+	locationIDPath = path.Root("location_id")
+	locationPath   = path.Root("location")
+)
+
+func locationIDAttribute(description string) tfsdk.Attribute {
+	return tfsdk.Attribute{
+		Type:                types.Int64Type,
+		MarkdownDescription: description + ". Defaults to the `default_location` of the provider",
+		Optional:            true,
+		Computed:            true,
+	}
+}
+
+func locationAttribute() tfsdk.Attribute {
+	return tfsdk.Attribute{
+		Type:                types.StringType,
+		MarkdownDescription: "key of the location (e.g. `ALP1`), as an alternative to `location_id`",
+		Optional:            true,
+		Computed:            true,
+	}
+}
+
+// locationResolver resolves the location of a resource during planning. The location can be configured using either
+// `location_id`, `location` or the `default_location` of the provider. Both attributes are always known in the plan,
+// so that a change of the location replaces the resource.
+//
+// Additionally, the resolver verifies that the modules, products and images used by a resource are offered in the
+// resolved location, so that incompatible configurations are reported during planning instead of after an order has
+// been submitted.
+type locationResolver struct {
+	client          goclient.Client
+	defaultLocation string
+	listLocations   func(ctx context.Context) ([]common.Location, error)
+	listModules     func(ctx context.Context) ([]common.Module, error)
+}
+
+func newLocationResolver(prov *provider) locationResolver {
+	return locationResolver{
+		client:          prov.client,
+		defaultLocation: prov.defaultLocation,
+		listLocations:   prov.listLocations,
+		listModules:     prov.listModules,
+	}
+}
+
+// listLocations returns all locations. The list is shared by all resources of the provider once it has been requested
+// successfully, failed requests are retried by the next caller using its own context.
+func (p *provider) listLocations(ctx context.Context) ([]common.Location, error) {
+	p.locationsMutex.Lock()
+	defer p.locationsMutex.Unlock()
+
+	if p.locations != nil {
+		return p.locations, nil
+	}
+
+	list, err := common.NewLocationService(p.client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	p.locations = append([]common.Location{}, list.Items...)
+	return p.locations, nil
+}
+
+// listModules returns all modules. Like the locations, the list is shared once it has been requested successfully.
+func (p *provider) listModules(ctx context.Context) ([]common.Module, error) {
+	p.modulesMutex.Lock()
+	defer p.modulesMutex.Unlock()
+
+	if p.modules != nil {
+		return p.modules, nil
+	}
+
+	list, err := common.NewModuleService(p.client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	p.modules = append([]common.Module{}, list.Items...)
+	return p.modules, nil
+}
+
+// ModifyPlan resolves the location of the planned resource. The location is only returned if it is known during
+// planning.
+func (l locationResolver) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) (location common.Location, known bool) {
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	config := locationDataSourceData{Name: types.String{Null: true}}
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, locationIDPath, &config.ID)...)
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, locationPath, &config.Key)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if config.ID.Unknown || config.Key.Unknown {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, locationIDPath, types.Int64{Unknown: true})...)
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, locationPath, types.String{Unknown: true})...)
+
+		if !request.State.Raw.IsNull() {
+			response.RequiresReplace = append(response.RequiresReplace, locationIDPath)
+		}
+		return
+	}
+
+	var state locationDataSourceData
+	if !request.State.Raw.IsNull() {
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, locationIDPath, &state.ID)...)
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, locationPath, &state.Key)...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+
+		// existing resources keep their location, even if the default location of the provider changes
+		if config.ID.Null && config.Key.Null {
+			config.ID = state.ID
+		}
+	}
+
+	if config.ID.Null && config.Key.Null {
+		if l.defaultLocation == "" {
+			response.Diagnostics.AddAttributeError(
+				locationIDPath,
+				"Missing Location",
+				"The location is missing. Please set either location_id or location, or configure a default_location in the provider.",
+			)
+			return
+		}
+
+		config = locationReference(l.defaultLocation)
+	}
+
+	locations, err := l.listLocations(ctx)
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to list locations", err)
+		return
+	}
+
+	location, err = filter.FindOne(config, locations)
+	if err != nil {
+		keys := make([]string, len(locations))
+		for i, item := range locations {
+			keys[i] = item.Key
+		}
+
+		response.Diagnostics.AddAttributeError(
+			locationIDPath,
+			"Invalid Location",
+			fmt.Sprintf("unable to find location: %s. Available locations are %s", err, strings.Join(keys, ", ")),
+		)
+		return
+	}
+
+	response.Diagnostics.Append(response.Plan.SetAttribute(ctx, locationIDPath, types.Int64{Value: int64(location.ID)})...)
+	response.Diagnostics.Append(response.Plan.SetAttribute(ctx, locationPath, types.String{Value: location.Key})...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if !state.ID.Null && !state.ID.Unknown && state.ID.Value != int64(location.ID) {
+		response.RequiresReplace = append(response.RequiresReplace, locationIDPath)
+	}
+
+	return location, true
+}
+
+// CheckModule verifies that the module with the given name is available in the location. If the api does not list a
+// module with this name, the check is skipped and left to the api when the order is submitted.
+func (l locationResolver) CheckModule(ctx context.Context, location common.Location, moduleName string, diagnostics *diag.Diagnostics) {
+	modules, err := l.listModules(ctx)
+	if err != nil {
+		addClientError(diagnostics, "unable to list modules", err)
+		return
+	}
+
+	found := filter.Find(moduleDataSourceData{ID: types.Int64{Null: true}, Name: types.String{Value: moduleName}}, modules)
+	if len(found) == 0 {
+		tflog.Warn(ctx, fmt.Sprintf("module %s is not listed by the api, skipping the availability check in location %s", moduleName, location.Key))
+		return
+	}
+
+	required := moduleDataSourceData{
+		ID:   types.Int64{Value: int64(found[0].ID)},
+		Name: types.String{Null: true},
+	}
+
+	config := locationDataSourceData{
+		ID:              types.Int64{Null: true},
+		Name:            types.String{Null: true},
+		Key:             types.String{Null: true},
+		RequiredModules: []moduleDataSourceData{required},
+	}
+
+	if config.AppliesTo(location) {
+		return
+	}
+
+	var data locationDataSourceData
+	data.FromEntity(location)
+
+	names := make([]string, len(data.AvailableModules))
+	for i, availableModule := range data.AvailableModules {
+		names[i] = fmt.Sprintf("%s (%d)", availableModule.Name.Value, availableModule.ID.Value)
+	}
+
+	diagnostics.AddAttributeError(
+		locationIDPath,
+		"Module Not Available",
+		fmt.Sprintf("The module %s is not available in location %s. Available modules are %s.", moduleName, location.Key, strings.Join(names, ", ")),
+	)
+}
+
+// CheckProduct verifies that the product is offered in the location. The product is returned if it was found and is
+// known during planning.
+func (l locationResolver) CheckProduct(ctx context.Context, attributePath path.Path, productID types.Int64, location common.Location, diagnostics *diag.Diagnostics) *common.Product {
+	if productID.Null || productID.Unknown {
+		return nil
+	}
+
+	product, err := common.NewProductService(l.client).Get(ctx, int(productID.Value))
+	if err != nil {
+		diagnostics.AddAttributeError(attributePath, "Invalid Product", fmt.Sprintf("unable to get product %d: %s", productID.Value, err))
+		return nil
+	}
+
+	if productAvailableIn(product, location.ID) {
+		return &product
+	}
+
+	diagnostics.AddAttributeError(
+		attributePath,
+		"Product Not Available",
+		fmt.Sprintf("The product %s (%d) is not available in location %s.", product.Name, product.ID, location.Key),
+	)
+	return nil
+}
+
+// CheckProductType verifies that a product of the given type (e.g. `compute-engine-load-balancer`) is offered in the
+// location. It is used for resources whose product is chosen by the api instead of being configured.
+func (l locationResolver) CheckProductType(ctx context.Context, productType string, location common.Location, diagnostics *diag.Diagnostics) {
+	list, err := common.NewProductService(l.client).ListByType(ctx, productType, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(diagnostics, fmt.Sprintf("unable to list products of type %s", productType), err)
+		return
+	}
+
+	for _, product := range list.Items {
+		if productAvailableIn(product, location.ID) {
+			return
+		}
+	}
+
+	diagnostics.AddAttributeError(
+		locationIDPath,
+		"Product Not Available",
+		fmt.Sprintf("No product of type %s is available in location %s.", productType, location.Key),
+	)
+}
+
+// CheckImage verifies that the image is offered in the location and, if a product is given, that the storage of the
+// product satisfies the minimal root disk size of the image.
+func (l locationResolver) CheckImage(ctx context.Context, attributePath path.Path, imageID types.Int64, product *common.Product, location common.Location, diagnostics *diag.Diagnostics) {
+	if imageID.Null || imageID.Unknown {
+		return
+	}
+
+	image, err := compute.NewImageService(l.client).Get(ctx, int(imageID.Value))
+	if err != nil {
+		diagnostics.AddAttributeError(attributePath, "Invalid Image", fmt.Sprintf("unable to get image %d: %s", imageID.Value, err))
+		return
+	}
+
+	available := false
+	for _, locationID := range image.AvailableLocations {
+		if locationID == location.ID {
+			available = true
+			break
+		}
+	}
+
+	if !available {
+		diagnostics.AddAttributeError(
+			attributePath,
+			"Image Not Available",
+			fmt.Sprintf("The image %s %s (%d) is not available in location %s.", image.OperatingSystem, image.Version, image.ID, location.Key),
+		)
+		return
+	}
+
+	if product == nil {
+		return
+	}
+
+	if size := productItemAmount(*product, "Storage"); size != 0 && size < image.MinRootDiskSize {
+		diagnostics.AddAttributeError(
+			attributePath,
+			"Incompatible Image",
+			fmt.Sprintf("The image %s %s (%d) requires a root disk of at least %d GB, but the product %s (%d) only provides %d GB.", image.OperatingSystem, image.Version, image.ID, image.MinRootDiskSize, product.Name, product.ID, size),
+		)
+	}
+}
+
+// locationReference interprets a reference to a location as either its unique identifier or its key.
+func locationReference(reference string) (data locationDataSourceData) {
+	data.ID = types.Int64{Null: true}
+	data.Name = types.String{Null: true}
+	data.Key = types.String{Null: true}
+
+	if id, err := strconv.Atoi(reference); err == nil {
+		data.ID = types.Int64{Value: int64(id)}
+	} else {
+		data.Key = types.String{Value: reference}
+	}
+
+	return
+}
@@ -0,0 +1,208 @@
+package cloudbit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+type countingTransport struct {
+	base   http.RoundTripper
+	path   string
+	counts int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, c.path) {
+		atomic.AddInt32(&c.counts, 1)
+	}
+
+	return c.base.RoundTrip(req)
+}
+
+// testCountLocationRequests replaces the client of the provider with one counting the requests listing the locations.
+func testCountLocationRequests(api *fakeapi.Server, prov *provider) *countingTransport {
+	transport := &countingTransport{path: "/v4/entities/locations"}
+	prov.client = goclient.NewClient(
+		goclient.WithToken("fake"),
+		goclient.WithBase(api.URL()),
+		goclient.WithHTTPClientOption(func(c *http.Client) {
+			transport.base = c.Transport
+			c.Transport = transport
+		}),
+	)
+
+	return transport
+}
+
+func TestLocationResolver_ListsLocationsOnce(t *testing.T) {
+	api, prov := testFakeProvider(t)
+	transport := testCountLocationRequests(api, prov)
+
+	resourceType := computeElasticIPResourceType{}
+	for _, location := range []int{fakeapi.LocationALP1, fakeapi.LocationZRH1, fakeapi.LocationALP1} {
+		response := testModifyPlan(t, testNewResource(t, resourceType, prov), resourceType, map[string]tftypes.Value{
+			"location_id": tftypes.NewValue(tftypes.Number, location),
+		})
+
+		if response.Diagnostics.HasError() {
+			t.Fatalf("unable to plan elastic ip: %v", response.Diagnostics)
+		}
+	}
+
+	if transport.counts != 1 {
+		t.Errorf("expected the locations to be listed once, got %d requests", transport.counts)
+	}
+}
+
+func TestProvider_ListLocationsRetriesErrors(t *testing.T) {
+	api, prov := testFakeProvider(t)
+	transport := testCountLocationRequests(api, prov)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := prov.listLocations(cancelled); err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+
+	for i := 0; i < 2; i++ {
+		locations, err := prov.listLocations(context.Background())
+		if err != nil {
+			t.Fatalf("unable to list locations after a failed request: %s", err)
+		}
+
+		if len(locations) == 0 {
+			t.Fatal("expected the locations of the fake api")
+		}
+	}
+
+	// the failed request and the retry, the second successful call is answered from the cache
+	if transport.counts != 2 {
+		t.Errorf("expected the locations to be listed twice, got %d requests", transport.counts)
+	}
+}
+
+func TestLocationResolver_CheckModule(t *testing.T) {
+	compute := common.Module{ID: 12, Name: moduleCompute}
+	networking := common.Module{ID: 15, Name: moduleComputeNetworking}
+	kube := common.Module{ID: 17, Name: moduleKubernetes}
+
+	location := common.Location{ID: 1, Key: "ALP1", Modules: []common.Module{compute, networking}}
+
+	tests := []struct {
+		name    string
+		modules []common.Module
+		listErr error
+		module  string
+		err     string
+	}{
+		{name: "available module", modules: []common.Module{compute, networking, kube}, module: moduleComputeNetworking},
+		{name: "missing module", modules: []common.Module{compute, networking, kube}, module: moduleKubernetes, err: "Module Not Available"},
+		{name: "module not listed by the api", modules: []common.Module{compute, networking}, module: moduleKubernetes},
+		{name: "unable to list modules", listErr: errors.New("unavailable"), module: moduleCompute, err: "Client Error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolver := locationResolver{
+				listModules: func(ctx context.Context) ([]common.Module, error) {
+					return test.modules, test.listErr
+				},
+			}
+
+			var diagnostics diag.Diagnostics
+			resolver.CheckModule(context.Background(), location, test.module, &diagnostics)
+
+			if test.err == "" {
+				if diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", diagnostics)
+				}
+				return
+			}
+
+			if !diagnostics.HasError() || diagnostics[0].Summary() != test.err {
+				t.Fatalf("expected error %q, got %v", test.err, diagnostics)
+			}
+		})
+	}
+}
+
+func TestLocationResolver_PlanChecks(t *testing.T) {
+	api, prov := testFakeProvider(t)
+	api.SetProductAvailability(fakeapi.ProductServerLarge, fakeapi.LocationALP1)
+	api.SetProductAvailability(fakeapi.ProductLoadBalancer, fakeapi.LocationALP1)
+
+	number := func(value int) tftypes.Value {
+		return tftypes.NewValue(tftypes.Number, value)
+	}
+
+	tests := []struct {
+		name         string
+		resourceType tfsdk.ResourceType
+		config       map[string]tftypes.Value
+		err          string
+	}{
+		{
+			name:         "server",
+			resourceType: computeServerResourceType{},
+			config:       map[string]tftypes.Value{"location_id": number(fakeapi.LocationALP1), "product_id": number(fakeapi.ProductServerLarge)},
+		},
+		{
+			name:         "server product not available",
+			resourceType: computeServerResourceType{},
+			config:       map[string]tftypes.Value{"location_id": number(fakeapi.LocationZRH1), "product_id": number(fakeapi.ProductServerLarge)},
+			err:          "Product Not Available",
+		},
+		{
+			name:         "load balancer",
+			resourceType: computeLoadBalancerResourceType{},
+			config:       map[string]tftypes.Value{"location_id": number(fakeapi.LocationALP1)},
+		},
+		{
+			name:         "load balancer product not available",
+			resourceType: computeLoadBalancerResourceType{},
+			config:       map[string]tftypes.Value{"location_id": number(fakeapi.LocationZRH1)},
+			err:          "Product Not Available",
+		},
+		{
+			name:         "kubernetes cluster",
+			resourceType: kubernetesClusterResourceType{},
+			config:       map[string]tftypes.Value{"location_id": number(fakeapi.LocationALP1), "node_product_id": number(fakeapi.ProductClusterNode)},
+		},
+		{
+			name:         "kubernetes module not available",
+			resourceType: kubernetesClusterResourceType{},
+			config:       map[string]tftypes.Value{"location_id": number(fakeapi.LocationZRH1)},
+			err:          "Module Not Available",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := testModifyPlan(t, testNewResource(t, test.resourceType, prov), test.resourceType, test.config)
+
+			if test.err == "" {
+				if response.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", response.Diagnostics)
+				}
+				return
+			}
+
+			if !response.Diagnostics.HasError() || response.Diagnostics[0].Summary() != test.err {
+				t.Fatalf("expected error %q, got %v", test.err, response.Diagnostics)
+			}
+		})
+	}
+}
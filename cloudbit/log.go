@@ -0,0 +1,175 @@
+package cloudbit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// logSubsystemAPI is the logging subsystem of the requests sent to the api.
+	logSubsystemAPI = "api"
+
+	// logBodiesEnv enables logging the bodies of requests and responses, using the configured level (e.g. DEBUG) for
+	// the api subsystem.
+	logBodiesEnv = "TF_LOG_PROVIDER_CLOUDBIT_API"
+
+	// logBodyLimit is the number of bytes after which logged bodies are truncated.
+	logBodyLimit = 16 * 1024
+
+	redacted = "REDACTED"
+)
+
+// logRedactedFields are masked in the logged bodies.
+var logRedactedFields = map[string]bool{
+	"token":       true,
+	"password":    true,
+	"private_key": true,
+	"cloud_init":  true,
+	"kube_config": true,
+}
+
+type logTransport struct {
+	base http.RoundTripper
+}
+
+func (l logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	additionalContext := map[string]interface{}{
+		"method": req.Method,
+		"url":    req.URL.String(),
+	}
+
+	logBodies := os.Getenv(logBodiesEnv) != ""
+	if logBodies {
+		ctx = tflog.NewSubsystem(ctx, logSubsystemAPI, tflog.WithLevelFromEnv(logBodiesEnv))
+
+		body, err := readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		msg := fmt.Sprintf("sending request `%s %s`", req.Method, req.URL.String())
+		tflog.SubsystemDebug(ctx, logSubsystemAPI, msg, map[string]interface{}{
+			"method": req.Method,
+			"url":    req.URL.String(),
+			"body":   formatLogBody(body),
+		})
+	}
+
+	start := time.Now()
+	res, err := l.transport().RoundTrip(req)
+	duration := time.Since(start)
+
+	additionalContext["duration_ms"] = duration.Milliseconds()
+
+	if err != nil {
+		msg := fmt.Sprintf("request to `%s %s` resulted in `%s` after %s", req.Method, req.URL.String(), err, duration)
+		tflog.Trace(ctx, msg, additionalContext)
+		return res, err
+	}
+
+	additionalContext["request_id"] = res.Header.Get("X-Request-ID")
+
+	msg := fmt.Sprintf("request to `%s %s` resulted in `%s` after %s", req.Method, req.URL.String(), res.Status, duration)
+	tflog.Trace(ctx, msg, additionalContext)
+
+	if logBodies {
+		body, err := readBody(&res.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		additionalContext["status"] = res.StatusCode
+		additionalContext["body"] = formatLogBody(body)
+
+		msg := fmt.Sprintf("received response of `%s %s`", req.Method, req.URL.String())
+		tflog.SubsystemDebug(ctx, logSubsystemAPI, msg, additionalContext)
+	}
+
+	return res, nil
+}
+
+func (l logTransport) transport() http.RoundTripper {
+	if l.base == nil {
+		return http.DefaultTransport
+	}
+
+	return l.base
+}
+
+// formatLogBody redacts the sensitive fields of a body and truncates it, if it is too large to be logged.
+func formatLogBody(body []byte) string {
+	formatted := redactJSON(body, logRedactedFields)
+	if len(formatted) <= logBodyLimit {
+		return formatted
+	}
+
+	return fmt.Sprintf("%s... (truncated %d of %d bytes)", formatted[:logBodyLimit], len(formatted)-logBodyLimit, len(formatted))
+}
+
+// readBody reads the whole body and replaces it with a reader over the read
+// content, so that it can still be consumed afterwards.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// redactJSON masks the given fields of a json body and normalizes its
+// formatting. Bodies which are not json are returned as is.
+func redactJSON(body []byte, fields map[string]bool) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+
+	data, err := json.Marshal(redactValue(value, fields))
+	if err != nil {
+		return string(body)
+	}
+
+	return string(data)
+}
+
+func redactValue(value interface{}, fields map[string]bool) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			// only strings are redacted, as some of the field names are also
+			// used for nested entities (e.g. the certificate of a pool)
+			if str, ok := field.(string); ok && str != "" && fields[key] {
+				value[key] = redacted
+				continue
+			}
+
+			value[key] = redactValue(field, fields)
+		}
+	case []interface{}:
+		for idx, item := range value {
+			value[idx] = redactValue(item, fields)
+		}
+	}
+
+	return value
+}
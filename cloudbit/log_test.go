@@ -0,0 +1,99 @@
+package cloudbit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+func TestLogTransport_Bodies(t *testing.T) {
+	t.Setenv(logBodiesEnv, "DEBUG")
+
+	api := fakeapi.New()
+	defer api.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := goclient.NewClient(
+		goclient.WithToken("secret-token"),
+		goclient.WithBase(api.URL()),
+		goclient.WithHTTPClientOption(func(c *http.Client) {
+			c.Transport = logTransport{base: c.Transport}
+		}),
+	)
+
+	networks, err := compute.NewNetworkService(client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("unable to list networks: %s", err)
+	}
+
+	_, err = compute.NewServerService(client).Create(ctx, compute.ServerCreate{
+		Name:       "test",
+		LocationID: fakeapi.LocationALP1,
+		ImageID:    fakeapi.ImageUbuntu,
+		ProductID:  fakeapi.ProductServerSmall,
+		NetworkID:  networks.Items[0].ID,
+		Password:   "secret-password",
+		CloudInit:  "#cloud-config\npassword: secret-cloud-init",
+	})
+	if err != nil {
+		t.Fatalf("unable to create server: %s", err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unable to decode log entries: %s", err)
+	}
+
+	var request, response map[string]interface{}
+	for _, entry := range entries {
+		if entry["@module"] != "provider."+logSubsystemAPI || entry["method"] != http.MethodPost {
+			continue
+		}
+
+		if strings.HasPrefix(entry["@message"].(string), "sending request") {
+			request = entry
+		} else {
+			response = entry
+		}
+	}
+
+	if request == nil || response == nil {
+		t.Fatalf("expected the request and response to be logged, got %v", entries)
+	}
+
+	for _, secret := range []string{"secret-token", "secret-password", "secret-cloud-init"} {
+		if strings.Contains(output.String(), secret) {
+			t.Errorf("expected %q to be redacted from the logs", secret)
+		}
+	}
+
+	if !strings.Contains(request["body"].(string), `"name":"test"`) {
+		t.Errorf("expected the request body to be logged, got %v", request["body"])
+	}
+
+	if !strings.Contains(response["body"].(string), `"ref":`) {
+		t.Errorf("expected the response body to be logged, got %v", response["body"])
+	}
+
+	if _, ok := response["duration_ms"]; !ok {
+		t.Errorf("expected the duration to be logged, got %v", response)
+	}
+}
+
+func TestFormatLogBody_Truncate(t *testing.T) {
+	body := formatLogBody([]byte(strings.Repeat("a", logBodyLimit+10)))
+
+	if !strings.HasSuffix(body, "... (truncated 10 of 16394 bytes)") {
+		t.Errorf("expected body to be truncated, got %q", body[logBodyLimit:])
+	}
+}
@@ -0,0 +1,60 @@
+package cloudbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Resources created by an order (e.g. servers or clusters) are recorded in the state as soon as the order has been
+// submitted, together with the identifier of the order. If waiting for the order fails or is interrupted, terraform
+// taints the resource instead of losing track of it, and the next read or delete resumes waiting for the order.
+
+func orderIDAttribute() tfsdk.Attribute {
+	return tfsdk.Attribute{
+		Type:                types.Int64Type,
+		MarkdownDescription: "unique identifier of the order which created the resource",
+		Computed:            true,
+		PlanModifiers: tfsdk.AttributePlanModifiers{
+			tfsdk.UseStateForUnknown(),
+		},
+	}
+}
+
+// resumeOrder waits for the order of a resource whose creation has not been completed and returns the unique
+// identifier of the ordered entity. It returns false if the order failed and therefore nothing has been created.
+func resumeOrder(ctx context.Context, orderService common.OrderService, orderID types.Int64) (int, bool, error) {
+	if orderID.Null || orderID.Unknown {
+		return 0, false, errors.New("the resource has neither been created nor is there an order to wait for")
+	}
+
+	ordering := common.Ordering{Ref: goclient.Join("/v4/orders", orderID.Value)}
+
+	order, err := waitForOrder(ctx, orderService, ordering)
+	if errors.Is(err, common.ErrOrderFailed) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("waiting for order %d: %w", orderID.Value, err)
+	}
+
+	return order.Product.ID, true, nil
+}
+
+// waitForOrder waits until the order has been processed, tracing the wait in a span.
+func waitForOrder(ctx context.Context, orderService common.OrderService, ordering common.Ordering) (common.Order, error) {
+	ctx, span := startSpan(ctx, "WaitUntilProcessed", trace.WithAttributes(attribute.String("cloudbit.order", ordering.Ref)))
+
+	order, err := orderService.WaitUntilProcessed(ctx, ordering)
+	endSpan(span, err)
+
+	return order, err
+}
@@ -2,19 +2,28 @@ package cloudbit
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
-var _ tfsdk.Provider = (*provider)(nil)
+var (
+	_ tfsdk.Provider                     = (*provider)(nil)
+	_ tfsdk.ProviderWithConfigValidators = (*provider)(nil)
+)
 
 type Option func(p *provider)
 
@@ -30,10 +39,20 @@ func WithDefaultEndpoint(endpoint string) Option {
 	}
 }
 
+// WithTransport wraps the transport used to send requests to the api, e.g. to
+// record or replay the requests in tests.
+func WithTransport(wrap func(base http.RoundTripper) http.RoundTripper) Option {
+	return func(p *provider) {
+		p.wrapTransport = wrap
+	}
+}
+
+const defaultEndpoint = "https://api.cloudbit.ch/"
+
 func New(opts ...Option) tfsdk.Provider {
 	p := &provider{
 		version:         "dev",
-		defaultEndpoint: "https://api.cloudbit.ch/",
+		defaultEndpoint: defaultEndpoint,
 	}
 
 	for _, opt := range opts {
@@ -46,14 +65,38 @@ func New(opts ...Option) tfsdk.Provider {
 type provider struct {
 	version         string
 	defaultEndpoint string
+	defaultLocation string
+	wrapTransport   func(base http.RoundTripper) http.RoundTripper
 
 	client     goclient.Client
 	configured bool
+
+	// the locations are needed to plan every resource with a location, so they are only listed once per run
+	locationsMutex sync.Mutex
+	locations      []common.Location
+	modulesMutex   sync.Mutex
+	modules        []common.Module
 }
 
 type providerData struct {
-	Token    types.String `tfsdk:"token"`
-	Endpoint types.String `tfsdk:"endpoint"`
+	Token           types.String `tfsdk:"token"`
+	TokenFile       types.String `tfsdk:"token_file"`
+	TokenCommand    types.List   `tfsdk:"token_command"`
+	Profile         types.String `tfsdk:"profile"`
+	ConfigFile      types.String `tfsdk:"config_file"`
+	Endpoint        types.String `tfsdk:"endpoint"`
+	DefaultLocation types.String `tfsdk:"default_location"`
+
+	CABundle           types.String `tfsdk:"ca_bundle"`
+	CAFile             types.String `tfsdk:"ca_file"`
+	ClientCertificate  types.String `tfsdk:"client_certificate"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	HTTPProxy          types.String `tfsdk:"http_proxy"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	RequestTimeout     types.String `tfsdk:"request_timeout"`
+
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	MaxRequestsPerSecond  types.Float64 `tfsdk:"max_requests_per_second"`
 }
 
 func (p *provider) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
@@ -65,11 +108,82 @@ func (p *provider) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostic
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"token_file": {
+				Type:                types.StringType,
+				MarkdownDescription: "path of a file containing the authentication token for the cloudbit api",
+				Optional:            true,
+			},
+			"token_command": {
+				Type:                types.ListType{ElemType: types.StringType},
+				MarkdownDescription: "command (e.g. `[\"pass\", \"show\", \"cloudbit\"]`) of a credential helper printing the authentication token for the cloudbit api",
+				Optional:            true,
+			},
+			"profile": {
+				Type:                types.StringType,
+				MarkdownDescription: "name of the profile in the config file to use, can also be set using the `CLOUDBIT_PROFILE` environment variable",
+				Optional:            true,
+			},
+			"config_file": {
+				Type:                types.StringType,
+				MarkdownDescription: "path of the config file containing the profiles, defaults to `~/.config/cloudbit/config.yaml`",
+				Optional:            true,
+			},
 			"endpoint": {
 				Type:                types.StringType,
 				MarkdownDescription: "endpoint of the cloudbit api",
 				Optional:            true,
 			},
+			"default_location": {
+				Type:                types.StringType,
+				MarkdownDescription: "key (e.g. `ALP1`) or unique identifier of the location used by resources which do not specify a location",
+				Optional:            true,
+			},
+			"ca_bundle": {
+				Type:                types.StringType,
+				MarkdownDescription: "pem encoded certificates of additional certificate authorities trusted when connecting to the api",
+				Optional:            true,
+			},
+			"ca_file": {
+				Type:                types.StringType,
+				MarkdownDescription: "path of a file containing pem encoded certificates of additional certificate authorities trusted when connecting to the api",
+				Optional:            true,
+			},
+			"client_certificate": {
+				Type:                types.StringType,
+				MarkdownDescription: "pem encoded client certificate presented when connecting to the api",
+				Optional:            true,
+			},
+			"client_key": {
+				Type:                types.StringType,
+				MarkdownDescription: "pem encoded private key of the client certificate",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"http_proxy": {
+				Type:                types.StringType,
+				MarkdownDescription: "url of the proxy used to connect to the api, defaults to the proxy of the `HTTPS_PROXY` environment variable",
+				Optional:            true,
+			},
+			"insecure_skip_verify": {
+				Type:                types.BoolType,
+				MarkdownDescription: "disables the verification of the certificate of the api, which should only be used for testing",
+				Optional:            true,
+			},
+			"request_timeout": {
+				Type:                types.StringType,
+				MarkdownDescription: "timeout (e.g. `30s`) of a single request to the api, requests do not time out by default",
+				Optional:            true,
+			},
+			"max_concurrent_requests": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "maximum number of requests sent to the api concurrently, further requests are queued",
+				Optional:            true,
+			},
+			"max_requests_per_second": {
+				Type:                types.Float64Type,
+				MarkdownDescription: "maximum number of requests sent to the api per second, further requests are queued",
+				Optional:            true,
+			},
 		},
 	}, nil
 }
@@ -86,39 +200,214 @@ func (p *provider) Configure(ctx context.Context, request tfsdk.ConfigureProvide
 		return
 	}
 
-	if data.Token.Null {
-		if val, ok := os.LookupEnv("CLOUDBIT_TOKEN"); ok {
-			data.Token = types.String{Value: val}
-		} else {
-			response.Diagnostics.AddError(
-				"Missing Token",
-				"The token is missing. Please set the token in the provider configuration or set the CLOUDBIT_TOKEN environment variable.",
-			)
+	var tokenCommand []string
+	response.Diagnostics.Append(data.TokenCommand.ElementsAs(ctx, &tokenCommand, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	profile, explicitProfile, diagnostics := p.loadProfile(data)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	token, err := resolveToken(ctx, data.Token.Value, data.TokenFile.Value, tokenCommand)
+	if err != nil {
+		response.Diagnostics.AddError("Invalid Token", fmt.Sprintf("unable to get the token: %s", err))
+		return
+	}
+
+	// a profile selected explicitly takes precedence over the environment variables, the default profile does not
+	envToken := os.Getenv("CLOUDBIT_TOKEN")
+	tokenFromProfile := false
+	if token == "" && (explicitProfile || envToken == "") {
+		token, err = profile.token(ctx)
+		if err != nil {
+			response.Diagnostics.AddError("Invalid Token", fmt.Sprintf("unable to get the token of the profile: %s", err))
 			return
 		}
+
+		tokenFromProfile = token != ""
+	}
+
+	// the endpoint and default location of a profile belong to its token and are not combined with another one
+	if !tokenFromProfile {
+		profile.Endpoint = ""
+		profile.DefaultLocation = ""
+	}
+
+	if token == "" {
+		token = envToken
+	}
+
+	if token == "" {
+		response.Diagnostics.AddError(
+			"Missing Token",
+			"The token is missing. Please set the token in the provider configuration, select a profile containing a token or set the CLOUDBIT_TOKEN environment variable.",
+		)
+		return
 	}
 
+	endpoint := data.Endpoint.Value
 	if data.Endpoint.Null {
-		data.Endpoint = types.String{Value: p.defaultEndpoint}
+		endpoint = profileOrEnv(profile.Endpoint, explicitProfile, "CLOUDBIT_ENDPOINT", p.defaultEndpoint)
+	}
+
+	if data.DefaultLocation.Null {
+		data.DefaultLocation = types.String{Value: profileOrEnv(profile.DefaultLocation, explicitProfile, "CLOUDBIT_DEFAULT_LOCATION", "")}
+	}
+
+	p.defaultLocation = data.DefaultLocation.Value
+
+	transport, err := newHTTPTransport(transportOptions{
+		CABundle:           data.CABundle.Value,
+		CAFile:             data.CAFile.Value,
+		ClientCertificate:  data.ClientCertificate.Value,
+		ClientKey:          data.ClientKey.Value,
+		HTTPProxy:          data.HTTPProxy.Value,
+		InsecureSkipVerify: data.InsecureSkipVerify.Value,
+	})
+	if err != nil {
+		response.Diagnostics.AddError("Invalid Transport Configuration", fmt.Sprintf("unable to configure the connection to the api: %s", err))
+		return
+	}
+
+	if data.InsecureSkipVerify.Value {
+		response.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"Insecure Connection",
+			"The certificate of the api is not verified, which allows anyone intercepting the connection to read and modify "+
+				"the requests, including the token. Please configure the certificate of the proxy using ca_bundle or ca_file instead.",
+		)
+	}
 
-		if val, ok := os.LookupEnv("CLOUDBIT_ENDPOINT"); ok {
-			data.Endpoint = types.String{Value: val}
+	var timeout time.Duration
+	if !data.RequestTimeout.Null {
+		timeout, err = time.ParseDuration(data.RequestTimeout.Value)
+		if err != nil || timeout < 0 {
+			response.Diagnostics.AddAttributeError(path.Root("request_timeout"), "Invalid Request Timeout", fmt.Sprintf("The request timeout %q is not a valid duration (e.g. 30s).", data.RequestTimeout.Value))
+			return
 		}
 	}
 
+	if !data.MaxConcurrentRequests.Null && data.MaxConcurrentRequests.Value < 1 {
+		response.Diagnostics.AddAttributeError(path.Root("max_concurrent_requests"), "Invalid Request Limit", "The maximum number of concurrent requests must be at least 1.")
+		return
+	}
+
+	if !data.MaxRequestsPerSecond.Null && data.MaxRequestsPerSecond.Value <= 0 {
+		response.Diagnostics.AddAttributeError(path.Root("max_requests_per_second"), "Invalid Request Limit", "The maximum number of requests per second must be greater than 0.")
+		return
+	}
+
 	p.client = goclient.NewClient(
-		goclient.WithToken(data.Token.Value),
-		goclient.WithBase(data.Endpoint.Value),
+		// the transport has to be set before it is wrapped by the token
+		goclient.WithHTTPClientOption(func(c *http.Client) {
+			c.Transport = transport
+			c.Timeout = timeout
+		}),
+
+		goclient.WithToken(token),
+		goclient.WithBase(endpoint),
 		goclient.WithUserAgent(fmt.Sprintf("terraform-provider-cloudbit/%s", p.version)),
 
 		goclient.WithHTTPClientOption(func(c *http.Client) {
+			if p.wrapTransport != nil {
+				c.Transport = p.wrapTransport(c.Transport)
+			}
+
+			c.Transport = traceTransport{base: c.Transport}
 			c.Transport = logTransport{base: c.Transport}
+			c.Transport = newLimitTransport(c.Transport, int(data.MaxConcurrentRequests.Value), data.MaxRequestsPerSecond.Value)
+			c.Transport = errorBodyTransport{base: c.Transport}
 		}),
 	)
 
 	p.configured = true
 }
 
+func (p *provider) ConfigValidators(ctx context.Context) []tfsdk.ProviderConfigValidator {
+	return []tfsdk.ProviderConfigValidator{
+		validators.ProviderMutuallyExclusive("token", "token_file", "token_command"),
+		validators.ProviderRequiredTogether("client_certificate", "client_key"),
+	}
+}
+
+// loadProfile loads the profile selected using the profile attribute or CLOUDBIT_PROFILE, or otherwise the default
+// profile of the config file. The returned flag is true if the profile has been selected explicitly. The config file
+// is optional, unless it has been configured or a profile has been selected.
+func (p *provider) loadProfile(data providerData) (profile configProfile, explicit bool, diagnostics diag.Diagnostics) {
+	name := data.Profile.Value
+	if data.Profile.Null {
+		name = os.Getenv("CLOUDBIT_PROFILE")
+	}
+
+	configFilePath := data.ConfigFile.Value
+	if data.ConfigFile.Null {
+		configFilePath = os.Getenv("CLOUDBIT_CONFIG_FILE")
+	}
+
+	required := name != "" || configFilePath != ""
+	if configFilePath == "" {
+		var err error
+		configFilePath, err = defaultConfigFilePath()
+		if err != nil && !required {
+			return profile, false, diagnostics
+		}
+	}
+
+	config, err := loadConfigFile(configFilePath)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return profile, false, diagnostics
+	}
+
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("config_file"), "Invalid Config File", fmt.Sprintf("unable to load the config file: %s", err))
+		return profile, false, diagnostics
+	}
+
+	explicit = name != ""
+	if !explicit {
+		name = config.defaultProfileName()
+		if name == "" {
+			return profile, false, diagnostics
+		}
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok {
+		diagnostics.AddAttributeError(path.Root("profile"), "Unknown Profile", fmt.Sprintf("The profile %q does not exist in the config file %s.", name, configFilePath))
+		return profile, false, diagnostics
+	}
+
+	return profile, explicit, diagnostics
+}
+
+// profileOrEnv returns the value of the profile, if it has been selected explicitly, followed by the value of the
+// environment variable, the value of the default profile and finally the fallback.
+func profileOrEnv(profileValue string, explicitProfile bool, env string, fallback string) string {
+	if explicitProfile && profileValue != "" {
+		return profileValue
+	}
+
+	if val, ok := os.LookupEnv(env); ok {
+		return val
+	}
+
+	return firstNonEmpty(profileValue, fallback)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
 func (p *provider) GetResources(ctx context.Context) (map[string]tfsdk.ResourceType, diag.Diagnostics) {
 	return map[string]tfsdk.ResourceType{
 		"cloudbit_compute_certificate":                  computeCertificateResourceType{},
@@ -133,6 +422,7 @@ func (p *provider) GetResources(ctx context.Context) (map[string]tfsdk.ResourceT
 		"cloudbit_compute_router":                       computeRouterResourceType{},
 		"cloudbit_compute_router_interface":             computeRouterInterfaceResourceType{},
 		"cloudbit_compute_router_route":                 computeRouterRouteResourceType{},
+		"cloudbit_compute_router_route_table":           computeRouterRouteTableResourceType{},
 		"cloudbit_compute_security_group":               computeSecurityGroupResourceType{},
 		"cloudbit_compute_security_group_rule":          computeSecurityGroupRuleResourceType{},
 		"cloudbit_compute_server":                       computeServerResourceType{},
@@ -145,9 +435,10 @@ func (p *provider) GetResources(ctx context.Context) (map[string]tfsdk.ResourceT
 
 func (p *provider) GetDataSources(ctx context.Context) (map[string]tfsdk.DataSourceType, diag.Diagnostics) {
 	return map[string]tfsdk.DataSourceType{
-		"cloudbit_location": locationDataSourceType{},
-		"cloudbit_module":   moduleDataSourceType{},
-		"cloudbit_product":  productDataSourceType{},
+		"cloudbit_cost_estimate": costEstimateDataSourceType{},
+		"cloudbit_location":      locationDataSourceType{},
+		"cloudbit_module":        moduleDataSourceType{},
+		"cloudbit_product":       productDataSourceType{},
 
 		"cloudbit_compute_certificate":                     computeCertificateDataSourceType{},
 		"cloudbit_compute_elastic_ip":                      computeElasticIPDataSourceType{},
@@ -159,6 +450,7 @@ func (p *provider) GetDataSources(ctx context.Context) (map[string]tfsdk.DataSou
 		"cloudbit_compute_load_balancer_pool":              computeLoadBalancerPoolDataSourceType{},
 		"cloudbit_compute_load_balancer_protocol":          computeLoadBalancerProtocolDataSourceType{},
 		"cloudbit_compute_network":                         computeNetworkDataSourceType{},
+		"cloudbit_compute_network_addresses":               computeNetworkAddressesDataSourceType{},
 		"cloudbit_compute_network_interface":               computeNetworkInterfaceDataSourceType{},
 		"cloudbit_compute_router":                          computeRouterDataSourceType{},
 		"cloudbit_compute_router_interface":                computeRouterInterfaceDataSourceType{},
@@ -189,6 +481,9 @@ func convertToLocalProviderType(p tfsdk.Provider) (prov *provider, diagnostics d
 }
 
 func waitForCondition(ctx context.Context, check func(ctx context.Context) (bool, diag.Diagnostics)) (diagnostics diag.Diagnostics) {
+	ctx, span := startSpan(ctx, "waitForCondition")
+	defer endSpanWithDiagnostics(span, &diagnostics)
+
 	done, d := check(ctx)
 	diagnostics.Append(d...)
 	if done || diagnostics.HasError() {
@@ -214,36 +509,3 @@ func waitForCondition(ctx context.Context, check func(ctx context.Context) (bool
 		}
 	}
 }
-
-type logTransport struct {
-	base http.RoundTripper
-}
-
-func (l logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	additionalContext := map[string]interface{}{
-		"method": req.Method,
-		"url":    req.URL.String(),
-	}
-
-	res, err := l.transport().RoundTrip(req)
-
-	if err == nil {
-		additionalContext["request_id"] = res.Header.Get("X-Request-ID")
-
-		msg := fmt.Sprintf("request to `%s %s` resulted in `%s`", req.Method, req.URL.String(), res.Status)
-		tflog.Trace(req.Context(), msg, additionalContext)
-	} else {
-		msg := fmt.Sprintf("request to `%s %s` resulted in `%s`", req.Method, req.URL.String(), err)
-		tflog.Trace(req.Context(), msg, additionalContext)
-	}
-
-	return res, err
-}
-
-func (l logTransport) transport() http.RoundTripper {
-	if l.base == nil {
-		return http.DefaultTransport
-	}
-
-	return l.base
-}
@@ -1,12 +1,244 @@
 package cloudbit
 
 import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
 )
 
-var protoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"cloudbit": providerserver.NewProtocol6WithError(New(
-		WithVersion("test"),
-	)),
+// testAccCassetteDir contains the recorded interactions of the acceptance
+// tests, one file per test.
+const testAccCassetteDir = "testdata/cassettes"
+
+var testProviderOptions = []Option{
+	WithVersion("test"),
+}
+
+// testAccCassettes holds the cassette of each test by its name, or nil if the
+// test neither records nor replays.
+var testAccCassettes sync.Map
+
+// TestMain runs the acceptance tests against an in-memory fake of the api,
+// unless a token for the real api is provided using CLOUDBIT_TOKEN. In that
+// case the sweepers can be run as well using the -sweep flag.
+func TestMain(m *testing.M) {
+	if os.Getenv(resource.EnvTfAcc) == "" || os.Getenv("CLOUDBIT_TOKEN") != "" {
+		resource.TestMain(m)
+		return
+	}
+
+	api := fakeapi.New()
+	testProviderOptions = append(testProviderOptions, WithDefaultEndpoint(api.URL()))
+	_ = os.Setenv("CLOUDBIT_TOKEN", "fake")
+
+	code := m.Run()
+	api.Close()
+	os.Exit(code)
+}
+
+// testAccProviderFactories returns the provider factories for the test. With
+// CLOUDBIT_RECORD=1 the requests of the test are recorded to its cassette and
+// with CLOUDBIT_REPLAY=1 they are replayed from it. Otherwise the requests are
+// sent to the fake or real api.
+func testAccProviderFactories(t *testing.T) map[string]func() (tfprotov6.ProviderServer, error) {
+	opts := append([]Option{}, testProviderOptions...)
+	if c := testAccCassette(t); c != nil {
+		opts = append(opts, WithTransport(c.Transport))
+	}
+
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"cloudbit": func() (tfprotov6.ProviderServer, error) {
+			return providerserver.NewProtocol6WithError(New(opts...))()
+		},
+	}
+}
+
+// testAccRandomName returns a random name with the given prefix, which is
+// recorded in the cassette of the test, so that it stays the same when the
+// test is replayed.
+func testAccRandomName(t *testing.T, prefix string) string {
+	generate := func() string {
+		return acctest.RandomWithPrefix(prefix)
+	}
+
+	c := testAccCassette(t)
+	if c == nil {
+		return generate()
+	}
+
+	name, err := c.Value(generate)
+	if err != nil {
+		t.Fatalf("unable to replay random name: %s", err)
+	}
+
+	return name
+}
+
+func testAccCassette(t *testing.T) *cassette {
+	if c, ok := testAccCassettes.Load(t.Name()); ok {
+		return c.(*cassette)
+	}
+
+	path := filepath.Join(testAccCassetteDir, t.Name()+".json")
+
+	var c *cassette
+	if os.Getenv("CLOUDBIT_RECORD") == "1" {
+		c = newRecordingCassette(path)
+
+		t.Cleanup(func() {
+			if t.Failed() || t.Skipped() {
+				return
+			}
+
+			if err := c.Save(); err != nil {
+				t.Errorf("unable to save cassette: %s", err)
+			}
+		})
+	} else if os.Getenv("CLOUDBIT_REPLAY") == "1" {
+		var err error
+		c, err = loadCassette(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			t.Skipf("no cassette recorded for %s", t.Name())
+		} else if err != nil {
+			t.Fatalf("unable to load cassette: %s", err)
+		}
+	}
+
+	testAccCassettes.Store(t.Name(), c)
+	return c
+}
+
+// testFakeProvider returns a provider which is configured to use a new fake of
+// the api, so that resources can be tested without running terraform.
+func testFakeProvider(t *testing.T) (*fakeapi.Server, *provider) {
+	t.Helper()
+
+	api := fakeapi.New()
+	t.Cleanup(api.Close)
+
+	prov := New(testProviderOptions...).(*provider)
+	prov.client = goclient.NewClient(
+		goclient.WithToken("fake"),
+		goclient.WithBase(api.URL()),
+	)
+	prov.configured = true
+
+	return api, prov
+}
+
+// testNewResource creates the resource of the given type for the provider.
+func testNewResource(t *testing.T, resourceType tfsdk.ResourceType, prov *provider) tfsdk.Resource {
+	t.Helper()
+
+	res, diagnostics := resourceType.NewResource(context.Background(), prov)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to create resource: %v", diagnostics)
+	}
+
+	return res
+}
+
+// testResourceState returns a state of the resource or data source type with
+// the given attribute values, all other attributes are null.
+func testResourceState(t *testing.T, resourceType interface {
+	GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics)
+}, values map[string]tftypes.Value) tfsdk.State {
+	t.Helper()
+
+	ctx := context.Background()
+
+	schema, diagnostics := resourceType.GetSchema(ctx)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to get schema: %v", diagnostics)
+	}
+
+	objectType := schema.TerraformType(ctx).(tftypes.Object)
+
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+
+	for name, value := range values {
+		if _, ok := attributes[name]; !ok {
+			t.Fatalf("unknown attribute %s", name)
+		}
+
+		attributes[name] = value
+	}
+
+	return tfsdk.State{Schema: schema, Raw: tftypes.NewValue(objectType, attributes)}
+}
+
+// testFakeServer creates a server in the first network of the fake api and
+// waits until it has been created.
+func testFakeServer(t *testing.T, client goclient.Client) compute.Server {
+	t.Helper()
+
+	ctx := context.Background()
+
+	networks, err := compute.NewNetworkService(client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("unable to list networks: %s", err)
+	}
+
+	ordering, err := compute.NewServerService(client).Create(ctx, compute.ServerCreate{
+		Name:       "test",
+		LocationID: fakeapi.LocationALP1,
+		ImageID:    fakeapi.ImageUbuntu,
+		ProductID:  fakeapi.ProductServerSmall,
+		NetworkID:  networks.Items[0].ID,
+		Password:   "secret-password",
+	})
+	if err != nil {
+		t.Fatalf("unable to create server: %s", err)
+	}
+
+	order, err := common.NewOrderService(client).WaitUntilProcessed(ctx, ordering)
+	if err != nil {
+		t.Fatalf("unable to wait for server: %s", err)
+	}
+
+	server, err := compute.NewServerService(client).Get(ctx, order.Product.ID)
+	if err != nil {
+		t.Fatalf("unable to get server: %s", err)
+	}
+
+	return server
+}
+
+// testModifyPlan plans the creation of a resource with the given configuration
+// and returns the response of the resource to the plan.
+func testModifyPlan(t *testing.T, res tfsdk.Resource, resourceType tfsdk.ResourceType, values map[string]tftypes.Value) tfsdk.ModifyResourcePlanResponse {
+	t.Helper()
+
+	config := testResourceState(t, resourceType, values)
+
+	request := tfsdk.ModifyResourcePlanRequest{
+		Config: tfsdk.Config{Schema: config.Schema, Raw: config.Raw},
+		Plan:   tfsdk.Plan{Schema: config.Schema, Raw: config.Raw},
+		State:  tfsdk.State{Schema: config.Schema, Raw: tftypes.NewValue(config.Raw.Type(), nil)},
+	}
+
+	response := tfsdk.ModifyResourcePlanResponse{Plan: request.Plan}
+	res.(tfsdk.ResourceWithModifyPlan).ModifyPlan(context.Background(), request, &response)
+
+	return response
 }
@@ -2,7 +2,14 @@ package cloudbit
 
 import (
 	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/compute"
@@ -10,14 +17,21 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeCertificateResourceType)(nil)
-	_ tfsdk.Resource                = (*computeCertificateResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeCertificateResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeCertificateResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeCertificateResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeCertificateResource)(nil)
+	_ tfsdk.ResourceWithValidateConfig   = (*computeCertificateResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeCertificateResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeCertificateResource)(nil)
 )
 
+const certificateTimeFormat = "2006-01-02T15:04:05-0700"
+
 type computeCertificateResourceAttributes struct {
 	CommonName         types.String `tfsdk:"common_name"`
 	OrganizationalUnit types.String `tfsdk:"organizational_unit"`
@@ -35,15 +49,49 @@ type computeCertificateResourceInfo struct {
 	NotAfter  types.String `tfsdk:"not_after"`
 
 	SerialNumber types.String `tfsdk:"serial_number"`
+
+	FingerprintSHA256       types.String   `tfsdk:"fingerprint_sha256"`
+	SubjectAlternativeNames []types.String `tfsdk:"subject_alternative_names"`
+}
+
+// FromCertificate sets the details which are not returned by the api but can be
+// derived from the certificate itself.
+func (c *computeCertificateResourceInfo) FromCertificate(certificate *x509.Certificate) {
+	if certificate == nil {
+		c.FingerprintSHA256 = types.String{Null: true}
+		c.SubjectAlternativeNames = nil
+		return
+	}
+
+	fingerprint := sha256.Sum256(certificate.Raw)
+	hexBytes := make([]string, len(fingerprint))
+	for idx, b := range fingerprint {
+		hexBytes[idx] = fmt.Sprintf("%02X", b)
+	}
+
+	c.FingerprintSHA256 = types.String{Value: strings.Join(hexBytes, ":")}
+
+	c.SubjectAlternativeNames = make([]types.String, 0, len(certificate.DNSNames)+len(certificate.IPAddresses))
+	for _, name := range certificate.DNSNames {
+		c.SubjectAlternativeNames = append(c.SubjectAlternativeNames, types.String{Value: name})
+	}
+	for _, ip := range certificate.IPAddresses {
+		c.SubjectAlternativeNames = append(c.SubjectAlternativeNames, types.String{Value: ip.String()})
+	}
 }
 
 type computeCertificateResourceData struct {
 	ID         types.Int64  `tfsdk:"id"`
 	Name       types.String `tfsdk:"name"`
 	LocationID types.Int64  `tfsdk:"location_id"`
+	Location   types.String `tfsdk:"location"`
+
+	Certificate      types.String `tfsdk:"certificate"`
+	CertificateChain types.String `tfsdk:"certificate_chain"`
+	PrivateKey       types.String `tfsdk:"private_key"`
 
-	Certificate types.String `tfsdk:"certificate"`
-	PrivateKey  types.String `tfsdk:"private_key"`
+	RenewBefore      types.String `tfsdk:"renew_before"`
+	ReplaceOnRenewal types.Bool   `tfsdk:"replace_on_renewal"`
 
 	Info *computeCertificateResourceInfo `tfsdk:"info"`
 }
@@ -52,6 +100,7 @@ func (c *computeCertificateResourceData) FromEntity(certificate compute.Certific
 	c.ID = types.Int64{Value: int64(certificate.ID)}
 	c.Name = types.String{Value: certificate.Name}
 	c.LocationID = types.Int64{Value: int64(certificate.Location.ID)}
+	c.Location = types.String{Value: certificate.Location.Key}
 
 	c.Info = &computeCertificateResourceInfo{
 		Subject: &computeCertificateResourceAttributes{
@@ -74,6 +123,17 @@ func (c *computeCertificateResourceData) FromEntity(certificate compute.Certific
 		NotAfter:     types.String{Value: certificate.Details.ValidTo.String()},
 		SerialNumber: types.String{Value: certificate.Details.Serial},
 	}
+
+	// the certificate is only known if it has been created by terraform and not imported
+	var leaf *x509.Certificate
+	if !c.Certificate.Null && !c.Certificate.Unknown {
+		certificates, err := parseCertificatesPEM(c.Certificate.Value)
+		if err == nil && len(certificates) != 0 {
+			leaf = certificates[0]
+		}
+	}
+
+	c.Info.FromCertificate(leaf)
 }
 
 type computeCertificateResourceType struct{}
@@ -130,31 +190,43 @@ func (c computeCertificateResourceType) GetSchema(ctx context.Context) (tfsdk.Sc
 					tfsdk.RequiresReplace(),
 				},
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the location",
+			"location_id": locationIDAttribute("unique identifier of the location"),
+			"location":    locationAttribute(),
+			"certificate": {
+				Type:                types.StringType,
+				MarkdownDescription: "certificate in PEM format, optionally base64 encoded",
 				Required:            true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
 				},
 			},
-			"certificate": {
+			"certificate_chain": {
 				Type:                types.StringType,
-				MarkdownDescription: "certificate in base64 encoded PEM format",
-				Required:            true,
+				MarkdownDescription: "intermediate certificates in PEM format, optionally base64 encoded",
+				Optional:            true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
 				},
 			},
 			"private_key": {
 				Type:                types.StringType,
-				MarkdownDescription: "private key in base64 encoded PEM format",
+				MarkdownDescription: "private key in PEM format, optionally base64 encoded",
 				Required:            true,
 				Sensitive:           true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
 				},
 			},
+			"renew_before": {
+				Type:                types.StringType,
+				MarkdownDescription: "duration before the expiry of the certificate from which on a warning is shown during planning (e.g. `720h`)",
+				Optional:            true,
+			},
+			"replace_on_renewal": {
+				Type:                types.BoolType,
+				MarkdownDescription: "whether the certificate is rotated by configuring a renewed certificate, which replaces the current one. Until then, a warning is shown during planning once it expires within `renew_before`. Combine with `create_before_destroy` to swap the certificate of load balancer pools without downtime",
+				Optional:            true,
+			},
 			"info": {
 				Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
 					"subject": {
@@ -182,6 +254,16 @@ func (c computeCertificateResourceType) GetSchema(ctx context.Context) (tfsdk.Sc
 						MarkdownDescription: "serial number of the certificate",
 						Computed:            true,
 					},
+					"fingerprint_sha256": {
+						Type:                types.StringType,
+						MarkdownDescription: "SHA-256 fingerprint of the certificate",
+						Computed:            true,
+					},
+					"subject_alternative_names": {
+						Type:                types.ListType{ElemType: types.StringType},
+						MarkdownDescription: "DNS names and IP addresses in the subject alternative names of the certificate",
+						Computed:            true,
+					},
 				}),
 				MarkdownDescription: "information about the certificate",
 				Computed:            true,
@@ -197,15 +279,22 @@ func (c computeCertificateResourceType) NewResource(ctx context.Context, p tfsdk
 	}
 
 	return computeCertificateResource{
+		locations: newLocationResolver(prov),
+
 		certificateService: compute.NewCertificateService(prov.client),
 	}, diagnostics
 }
 
 type computeCertificateResource struct {
+	locations locationResolver
+
 	certificateService compute.CertificateService
 }
 
 func (c computeCertificateResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_certificate", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeCertificateResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -213,31 +302,66 @@ func (c computeCertificateResource) Create(ctx context.Context, request tfsdk.Cr
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	certificatePEM, err := decodePEM(config.Certificate.Value)
+	if err != nil {
+		response.Diagnostics.AddAttributeError(path.Root("certificate"), "Invalid Certificate", err.Error())
+		return
+	}
+
+	if !config.CertificateChain.Null {
+		chainPEM, err := decodePEM(config.CertificateChain.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(path.Root("certificate_chain"), "Invalid Certificate Chain", err.Error())
+			return
+		}
+
+		certificatePEM = append(certificatePEM, chainPEM...)
+	}
+
+	privateKeyPEM, err := decodePEM(config.PrivateKey.Value)
+	if err != nil {
+		response.Diagnostics.AddAttributeError(path.Root("private_key"), "Invalid Private Key", err.Error())
+		return
+	}
+
+	// the api expects the certificate and private key to be base64 encoded
 	create := compute.CertificateCreate{
 		Name:        config.Name.Value,
 		LocationID:  int(config.LocationID.Value),
-		Certificate: config.Certificate.Value,
-		PrivateKey:  config.PrivateKey.Value,
+		Certificate: base64.StdEncoding.EncodeToString(certificatePEM),
+		PrivateKey:  base64.StdEncoding.EncodeToString(privateKeyPEM),
 	}
 
 	certificate, err := c.certificateService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create certificate: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create certificate", err)
 		return
 	}
 
-	var state computeCertificateResourceData
-	state.FromEntity(certificate)
-
 	// copy the certificate and private key from the config because the api does not return it
+	var state computeCertificateResourceData
 	state.Certificate = config.Certificate
+	state.CertificateChain = config.CertificateChain
 	state.PrivateKey = config.PrivateKey
+	state.RenewBefore = config.RenewBefore
+	state.ReplaceOnRenewal = config.ReplaceOnRenewal
+
+	state.FromEntity(certificate)
 
 	diagnostics = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diagnostics...)
 }
 
 func (c computeCertificateResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_certificate", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeCertificateResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -247,7 +371,7 @@ func (c computeCertificateResource) Read(ctx context.Context, request tfsdk.Read
 
 	list, err := c.certificateService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list certificates: %s", err))
+		addClientError(&response.Diagnostics, "unable to list certificates", err)
 		return
 	}
 
@@ -265,10 +389,35 @@ func (c computeCertificateResource) Read(ctx context.Context, request tfsdk.Read
 }
 
 func (c computeCertificateResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
-	response.Diagnostics.AddError("Not Supported", "updating a certificate is not supported")
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_certificate", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
+	var state computeCertificateResourceData
+	diagnostics := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	var config computeCertificateResourceData
+	diagnostics = request.Config.Get(ctx, &config)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// every other attribute requires a replacement, so only the renewal settings can change
+	state.RenewBefore = config.RenewBefore
+	state.ReplaceOnRenewal = config.ReplaceOnRenewal
+
+	diagnostics = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diagnostics...)
 }
 
 func (c computeCertificateResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_certificate", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeCertificateResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -278,7 +427,7 @@ func (c computeCertificateResource) Delete(ctx context.Context, request tfsdk.De
 
 	err := c.certificateService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete certificate: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete certificate", err)
 		return
 	}
 }
@@ -286,3 +435,218 @@ func (c computeCertificateResource) Delete(ctx context.Context, request tfsdk.De
 func (c computeCertificateResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (c computeCertificateResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
+
+func (c computeCertificateResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	c.locations.ModifyPlan(ctx, request, response)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if request.State.Raw.IsNull() || request.Plan.Raw.IsNull() {
+		return
+	}
+
+	var state computeCertificateResourceData
+	diagnostics := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// the computed info may be unknown in the plan, so only the configured attributes are read
+	var plan computeCertificateResourceData
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("certificate"), &plan.Certificate)...)
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("certificate_chain"), &plan.CertificateChain)...)
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("private_key"), &plan.PrivateKey)...)
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("renew_before"), &plan.RenewBefore)...)
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("replace_on_renewal"), &plan.ReplaceOnRenewal)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// a renewed certificate has been configured, which replaces the current one
+	if !plan.Certificate.Equal(state.Certificate) || !plan.CertificateChain.Equal(state.CertificateChain) || !plan.PrivateKey.Equal(state.PrivateKey) {
+		return
+	}
+
+	if plan.RenewBefore.Null || plan.RenewBefore.Unknown || state.Info == nil {
+		return
+	}
+
+	window, err := time.ParseDuration(plan.RenewBefore.Value)
+	if err != nil {
+		return
+	}
+
+	notAfter, err := time.Parse(certificateTimeFormat, state.Info.NotAfter.Value)
+	if err != nil {
+		return
+	}
+
+	remaining := time.Until(notAfter)
+	if remaining > window {
+		return
+	}
+
+	expiry := fmt.Sprintf("expires in %s", remaining.Round(time.Minute))
+	if remaining <= 0 {
+		expiry = "has expired"
+	}
+
+	if !plan.ReplaceOnRenewal.Value {
+		response.Diagnostics.AddAttributeWarning(
+			path.Root("renew_before"),
+			"Certificate Expiring",
+			fmt.Sprintf("The certificate %s %s (not after %s). Please renew it.", state.Name.Value, expiry, state.Info.NotAfter.Value),
+		)
+		return
+	}
+
+	// the configured certificate is unchanged, replacing it would upload the same certificate with the same expiry again
+	response.Diagnostics.AddAttributeWarning(
+		path.Root("certificate"),
+		"Certificate Not Renewed",
+		fmt.Sprintf("The certificate %s %s (not after %s), but the configured certificate has not been renewed yet. It is replaced as soon as a renewed certificate is configured.", state.Name.Value, expiry, state.Info.NotAfter.Value),
+	)
+}
+
+func (c computeCertificateResource) ValidateConfig(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	var config computeCertificateResourceData
+	diagnostics := request.Config.Get(ctx, &config)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	var certificate *x509.Certificate
+	if !config.Certificate.Unknown && !config.Certificate.Null {
+		certificates, err := parseCertificatesPEM(config.Certificate.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(path.Root("certificate"), "Invalid Certificate", err.Error())
+		} else if len(certificates) != 1 {
+			response.Diagnostics.AddAttributeError(
+				path.Root("certificate"),
+				"Invalid Certificate",
+				fmt.Sprintf("expected exactly one certificate, got %d. Please move the intermediate certificates to the certificate_chain attribute.", len(certificates)),
+			)
+		} else {
+			certificate = certificates[0]
+		}
+	}
+
+	if !config.CertificateChain.Unknown && !config.CertificateChain.Null {
+		certificates, err := parseCertificatesPEM(config.CertificateChain.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(path.Root("certificate_chain"), "Invalid Certificate Chain", err.Error())
+		} else if len(certificates) == 0 {
+			response.Diagnostics.AddAttributeError(path.Root("certificate_chain"), "Invalid Certificate Chain", "the certificate chain does not contain any certificates")
+		}
+	}
+
+	if !config.RenewBefore.Unknown && !config.RenewBefore.Null {
+		_, err := time.ParseDuration(config.RenewBefore.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(path.Root("renew_before"), "Invalid Duration", fmt.Sprintf("unable to parse renew before duration: %s", err))
+		}
+	}
+
+	if !config.PrivateKey.Unknown && !config.PrivateKey.Null {
+		privateKey, err := parsePrivateKeyPEM(config.PrivateKey.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(path.Root("private_key"), "Invalid Private Key", err.Error())
+			return
+		}
+
+		publicKey, ok := privateKey.Public().(interface{ Equal(crypto.PublicKey) bool })
+		if certificate != nil && (!ok || !publicKey.Equal(certificate.PublicKey)) {
+			response.Diagnostics.AddAttributeError(path.Root("private_key"), "Invalid Private Key", "the private key does not match the certificate")
+		}
+	}
+}
+
+// decodePEM returns the PEM data of the value, which can either be PEM encoded
+// directly or additionally be wrapped in base64.
+func decodePEM(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return []byte(value + "\n"), nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("value is neither in PEM nor in base64 encoded PEM format")
+	}
+
+	return data, nil
+}
+
+func parseCertificatesPEM(value string) ([]*x509.Certificate, error) {
+	data, err := decodePEM(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type %s", block.Type)
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate: %w", err)
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+func parsePrivateKeyPEM(value string) (crypto.Signer, error) {
+	data, err := decodePEM(value)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block of type %s", block.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
@@ -2,6 +2,7 @@ package cloudbit
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -10,18 +11,24 @@ import (
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"regexp"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
 )
 
 func TestAccComputeCertificate_Basic(t *testing.T) {
 	commonName := "cloudbit.ch"
 	orgName := "Cloudbit GmbH"
 
-	certificateName := acctest.RandomWithPrefix("test-certificate")
+	certificateName := testAccRandomName(t, "test-certificate")
 	cert, priv, err := randTLSCert(commonName, orgName)
 	if err != nil {
 		t.Fatal(err)
@@ -31,7 +38,7 @@ func TestAccComputeCertificate_Basic(t *testing.T) {
 	privBase64 := base64.StdEncoding.EncodeToString([]byte(priv))
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeCertificateConfigBasic, certificateName, certBase64, privBase64),
@@ -50,6 +57,166 @@ func TestAccComputeCertificate_Basic(t *testing.T) {
 	})
 }
 
+func TestAccComputeCertificate_PEM(t *testing.T) {
+	commonName := "cloudbit.ch"
+	orgName := "Cloudbit GmbH"
+
+	certificateName := testAccRandomName(t, "test-certificate")
+	cert, priv, err := randTLSCert(commonName, orgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccComputeCertificateConfigPEM, certificateName, cert, priv),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("cloudbit_compute_certificate.foobar", "id"),
+					resource.TestCheckResourceAttr("cloudbit_compute_certificate.foobar", "certificate", cert),
+					resource.TestCheckResourceAttr("cloudbit_compute_certificate.foobar", "info.subject.common_name", commonName),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_certificate.foobar", "info.fingerprint_sha256"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccComputeCertificate_KeyMismatch(t *testing.T) {
+	certificateName := testAccRandomName(t, "test-certificate")
+	cert, _, err := randTLSCert("cloudbit.ch", "Cloudbit GmbH")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, priv, err := genPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testAccComputeCertificateConfigPEM, certificateName, cert, priv),
+				ExpectError: regexp.MustCompile("the private key does not match the certificate"),
+			},
+		},
+	})
+}
+
+func TestAccComputeCertificate_ReplaceOnRenewal(t *testing.T) {
+	certificateName := testAccRandomName(t, "test-certificate")
+	cert, priv, err := randTLSCert("cloudbit.ch", "Cloudbit GmbH")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				// the generated certificate is valid for 24 hours and is therefore always within the renewal window
+				Config: fmt.Sprintf(testAccComputeCertificateConfigRenewal, certificateName, cert, priv, "48h"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudbit_compute_certificate.foobar", "renew_before", "48h"),
+					resource.TestCheckResourceAttr("cloudbit_compute_certificate.foobar", "replace_on_renewal", "true"),
+				),
+			},
+			{
+				// the unchanged certificate is only reported as expiring and not replaced again
+				Config:   fmt.Sprintf(testAccComputeCertificateConfigRenewal, certificateName, cert, priv, "48h"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestComputeCertificate_ModifyPlanRenewal(t *testing.T) {
+	_, prov := testFakeProvider(t)
+	resourceType := computeCertificateResourceType{}
+	res := testNewResource(t, resourceType, prov)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name             string
+		notAfter         time.Time
+		replaceOnRenewal bool
+		certificate      string
+		warning          string
+	}{
+		{name: "not expiring", notAfter: time.Now().Add(72 * time.Hour), replaceOnRenewal: true, certificate: "current"},
+		{name: "expiring", notAfter: time.Now().Add(24 * time.Hour), certificate: "current", warning: "Certificate Expiring"},
+		{name: "expiring with replace on renewal", notAfter: time.Now().Add(24 * time.Hour), replaceOnRenewal: true, certificate: "current", warning: "Certificate Not Renewed"},
+		{name: "renewed certificate", notAfter: time.Now().Add(24 * time.Hour), replaceOnRenewal: true, certificate: "renewed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := testResourceState(t, resourceType, nil)
+
+			data := computeCertificateResourceData{
+				ID:               types.Int64{Value: 1},
+				Name:             types.String{Value: "test"},
+				LocationID:       types.Int64{Value: fakeapi.LocationALP1},
+				Location:         types.String{Value: "ALP1"},
+				Certificate:      types.String{Value: "current"},
+				CertificateChain: types.String{Null: true},
+				PrivateKey:       types.String{Value: "key"},
+				RenewBefore:      types.String{Value: "48h"},
+				ReplaceOnRenewal: types.Bool{Value: test.replaceOnRenewal},
+				Info: &computeCertificateResourceInfo{
+					Subject:           &computeCertificateResourceAttributes{},
+					Issuer:            &computeCertificateResourceAttributes{},
+					NotAfter:          types.String{Value: test.notAfter.Format(certificateTimeFormat)},
+					FingerprintSHA256: types.String{Null: true},
+				},
+			}
+
+			if diagnostics := state.Set(ctx, data); diagnostics.HasError() {
+				t.Fatalf("unable to set state: %v", diagnostics)
+			}
+
+			plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+			if diagnostics := plan.SetAttribute(ctx, path.Root("certificate"), test.certificate); diagnostics.HasError() {
+				t.Fatalf("unable to set plan: %v", diagnostics)
+			}
+
+			request := tfsdk.ModifyResourcePlanRequest{
+				Config: tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+				Plan:   plan,
+				State:  state,
+			}
+
+			response := tfsdk.ModifyResourcePlanResponse{Plan: request.Plan}
+			res.(tfsdk.ResourceWithModifyPlan).ModifyPlan(ctx, request, &response)
+
+			if response.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", response.Diagnostics)
+			}
+
+			for _, attributePath := range response.RequiresReplace {
+				if attributePath.Equal(path.Root("info")) {
+					t.Errorf("expected the certificate not to be replaced because of its info")
+				}
+			}
+
+			if !response.Plan.Raw.Equal(request.Plan.Raw) {
+				t.Errorf("expected the plan to be unchanged")
+			}
+
+			switch {
+			case test.warning == "" && response.Diagnostics.WarningsCount() != 0:
+				t.Errorf("unexpected warnings: %v", response.Diagnostics)
+			case test.warning != "" && (response.Diagnostics.WarningsCount() != 1 || response.Diagnostics.Warnings()[0].Summary() != test.warning):
+				t.Errorf("expected warning %q, got %v", test.warning, response.Diagnostics)
+			}
+		})
+	}
+}
+
 const testAccComputeCertificateConfigBasic = `
 resource "cloudbit_compute_certificate" "foobar" {
 	name        = "%s"
@@ -60,6 +227,37 @@ resource "cloudbit_compute_certificate" "foobar" {
 }
 `
 
+const testAccComputeCertificateConfigPEM = `
+resource "cloudbit_compute_certificate" "foobar" {
+	name        = "%s"
+	location_id = 1
+
+	certificate = <<EOT
+%sEOT
+	private_key = <<EOT
+%sEOT
+}
+`
+
+const testAccComputeCertificateConfigRenewal = `
+resource "cloudbit_compute_certificate" "foobar" {
+	name        = "%s"
+	location_id = 1
+
+	certificate = <<EOT
+%sEOT
+	private_key = <<EOT
+%sEOT
+
+	renew_before       = "%s"
+	replace_on_renewal = true
+
+	lifecycle {
+		create_before_destroy = true
+	}
+}
+`
+
 // taken from https://github.com/hashicorp/terraform-plugin-sdk/blob/70ce77bce6118b74a49762bb401b46a723c0bab8/helper/acctest/random.go#L77
 // and modified to set the common name
 func randTLSCert(commonName string, orgName string) (string, string, error) {
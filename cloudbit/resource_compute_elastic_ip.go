@@ -11,23 +11,29 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeElasticIPResourceType)(nil)
-	_ tfsdk.Resource                = (*computeElasticIPResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeElasticIPResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeElasticIPResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeElasticIPResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeElasticIPResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeElasticIPResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeElasticIPResource)(nil)
 )
 
 type computeElasticIPResourceData struct {
 	ID         types.Int64  `tfsdk:"id"`
 	LocationID types.Int64  `tfsdk:"location_id"`
+	Location   types.String `tfsdk:"location"`
 	PublicIP   types.String `tfsdk:"public_ip"`
 }
 
 func (c *computeElasticIPResourceData) FromEntity(elasticIP compute.ElasticIP) {
 	c.ID = types.Int64{Value: int64(elasticIP.ID)}
 	c.LocationID = types.Int64{Value: int64(elasticIP.Location.ID)}
+	c.Location = types.String{Value: elasticIP.Location.Key}
 	c.PublicIP = types.String{Value: elasticIP.PublicIP}
 }
 
@@ -44,14 +50,8 @@ func (c computeElasticIPResourceType) GetSchema(ctx context.Context) (tfsdk.Sche
 					tfsdk.UseStateForUnknown(),
 				},
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "location of the elastic ip",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("location of the elastic ip"),
+			"location":    locationAttribute(),
 			"public_ip": {
 				Type:                types.StringType,
 				MarkdownDescription: "public ip address",
@@ -68,15 +68,22 @@ func (c computeElasticIPResourceType) NewResource(ctx context.Context, p tfsdk.P
 	}
 
 	return computeElasticIPResource{
+		locations: newLocationResolver(prov),
+
 		elasticIPService: compute.NewElasticIPService(prov.client),
 	}, diagnostics
 }
 
 type computeElasticIPResource struct {
+	locations locationResolver
+
 	elasticIPService compute.ElasticIPService
 }
 
 func (c computeElasticIPResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeElasticIPResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -84,13 +91,19 @@ func (c computeElasticIPResource) Create(ctx context.Context, request tfsdk.Crea
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := compute.ElasticIPCreate{
 		LocationID: int(config.LocationID.Value),
 	}
 
 	elasticIP, err := c.elasticIPService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create elastic ip: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create elastic ip", err)
 		return
 	}
 
@@ -107,6 +120,9 @@ func (c computeElasticIPResource) Create(ctx context.Context, request tfsdk.Crea
 }
 
 func (c computeElasticIPResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeElasticIPResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -127,10 +143,16 @@ func (c computeElasticIPResource) Read(ctx context.Context, request tfsdk.ReadRe
 }
 
 func (c computeElasticIPResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	response.Diagnostics.AddError("Not Supported", "updating an elastic ip is not supported")
 }
 
 func (c computeElasticIPResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeElasticIPResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -140,7 +162,7 @@ func (c computeElasticIPResource) Delete(ctx context.Context, request tfsdk.Dele
 
 	err := c.elasticIPService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete elastic ip: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete elastic ip", err)
 		return
 	}
 
@@ -153,10 +175,20 @@ func (c computeElasticIPResource) ImportState(ctx context.Context, request tfsdk
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
 
+func (c computeElasticIPResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	c.locations.ModifyPlan(ctx, request, response)
+}
+
+func (c computeElasticIPResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
+
 func findComputeElasticIP(ctx context.Context, service compute.ElasticIPService, id int) (elasticIP compute.ElasticIP, diagnostics diag.Diagnostics) {
 	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		diagnostics.AddError("Client Error", fmt.Sprintf("unable to list elastic ips: %s", err))
+		addClientError(&diagnostics, "unable to list elastic ips", err)
 		return
 	}
 
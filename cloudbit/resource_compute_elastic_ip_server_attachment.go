@@ -3,33 +3,43 @@ package cloudbit
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType = (*computeElasticIPServerAttachmentResourceType)(nil)
-	_ tfsdk.Resource     = (*computeElasticIPServerAttachmentResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeElasticIPServerAttachmentResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeElasticIPServerAttachmentResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeElasticIPServerAttachmentResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeElasticIPServerAttachmentResource)(nil)
 )
 
 type computeElasticIPServerAttachmentResourceData struct {
 	ServerID           types.Int64 `tfsdk:"server_id"`
+	NetworkID          types.Int64 `tfsdk:"network_id"`
 	NetworkInterfaceID types.Int64 `tfsdk:"network_interface_id"`
 	ElasticIPID        types.Int64 `tfsdk:"elastic_ip_id"`
 }
 
 func (c *computeElasticIPServerAttachmentResourceData) FromEntity(server compute.Server, elasticIP compute.ElasticIP) {
 	c.ServerID = types.Int64{Value: int64(server.ID)}
+	c.NetworkID = types.Int64{Null: true}
 	c.NetworkInterfaceID = types.Int64{Null: true}
 	c.ElasticIPID = types.Int64{Value: int64(elasticIP.ID)}
 
 	for _, network := range server.Networks {
 		for _, iface := range network.Interfaces {
 			if iface.PublicIP == elasticIP.PublicIP {
+				c.NetworkID = types.Int64{Value: int64(network.ID)}
 				c.NetworkInterfaceID = types.Int64{Value: int64(iface.ID)}
 			}
 		}
@@ -49,12 +59,24 @@ func (c computeElasticIPServerAttachmentResourceType) GetSchema(ctx context.Cont
 					tfsdk.RequiresReplace(),
 				},
 			},
+			"network_id": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "unique identifier of the network of the server to attach the elastic ip to. The server must have exactly one network interface in this network",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
 			"network_interface_id": {
 				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the network interface of the server to attach the elastic ip to",
-				Required:            true,
+				MarkdownDescription: "unique identifier of the network interface of the server to attach the elastic ip to. Required if the server has more than one network interface, unless network_id selects a single one",
+				Optional:            true,
+				Computed:            true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
 				},
 			},
 			"elastic_ip_id": {
@@ -90,6 +112,9 @@ type computeElasticIPServerAttachmentResource struct {
 }
 
 func (c computeElasticIPServerAttachmentResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip_server_attachment", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeElasticIPServerAttachmentResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -99,20 +124,48 @@ func (c computeElasticIPServerAttachmentResource) Create(ctx context.Context, re
 
 	serverID := int(config.ServerID.Value)
 
+	elasticIP, diagnostics := findComputeElasticIP(ctx, c.elasticIPService, int(config.ElasticIPID.Value))
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if elasticIP.Attachment.ID != 0 {
+		response.Diagnostics.AddError("Elastic IP Already Attached", describeElasticIPAttachment(elasticIP))
+		return
+	}
+
+	server, err := c.serverService.Get(ctx, serverID)
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to get server", err)
+		return
+	}
+
+	networkInterfaceID := int(config.NetworkInterfaceID.Value)
+	if config.NetworkInterfaceID.Null {
+		iface, diagnostics := findServerNetworkInterface(server, config.NetworkID)
+		response.Diagnostics.Append(diagnostics...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+
+		networkInterfaceID = iface.ID
+	}
+
 	attach := compute.ElasticIPAttach{
-		ElasticIPID:        int(config.ElasticIPID.Value),
-		NetworkInterfaceID: int(config.NetworkInterfaceID.Value),
+		ElasticIPID:        elasticIP.ID,
+		NetworkInterfaceID: networkInterfaceID,
 	}
 
-	elasticIP, err := compute.NewServerElasticIPService(c.client, serverID).Attach(ctx, attach)
+	elasticIP, err = compute.NewServerElasticIPService(c.client, serverID).Attach(ctx, attach)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to attach elastic ip: %s", err))
+		addClientError(&response.Diagnostics, "unable to attach elastic ip", err)
 		return
 	}
 
-	server, err := compute.NewServerService(c.client).Get(ctx, serverID)
+	server, err = c.serverService.Get(ctx, serverID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get server: %s", err))
+		addClientError(&response.Diagnostics, "unable to get server", err)
 		return
 	}
 
@@ -124,6 +177,9 @@ func (c computeElasticIPServerAttachmentResource) Create(ctx context.Context, re
 }
 
 func (c computeElasticIPServerAttachmentResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip_server_attachment", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeElasticIPServerAttachmentResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -133,7 +189,7 @@ func (c computeElasticIPServerAttachmentResource) Read(ctx context.Context, requ
 
 	server, err := compute.NewServerService(c.client).Get(ctx, int(state.ServerID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get server: %s", err))
+		addClientError(&response.Diagnostics, "unable to get server", err)
 		return
 	}
 
@@ -150,10 +206,16 @@ func (c computeElasticIPServerAttachmentResource) Read(ctx context.Context, requ
 }
 
 func (c computeElasticIPServerAttachmentResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip_server_attachment", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	response.Diagnostics.AddError("Not Supported", "updating an elastic ip attachment is not supported")
 }
 
 func (c computeElasticIPServerAttachmentResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_elastic_ip_server_attachment", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeElasticIPServerAttachmentResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -163,7 +225,89 @@ func (c computeElasticIPServerAttachmentResource) Delete(ctx context.Context, re
 
 	err := compute.NewServerElasticIPService(c.client, int(state.ServerID.Value)).Detach(ctx, int(state.ElasticIPID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to detach elastic ip: %s", err))
+		addClientError(&response.Diagnostics, "unable to detach elastic ip", err)
 		return
 	}
 }
+
+func (c computeElasticIPServerAttachmentResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("network_interface_id", "network_id"),
+	}
+}
+
+func (c computeElasticIPServerAttachmentResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	// only new attachments are checked, existing ones are attached to the elastic ip themselves
+	if request.Plan.Raw.IsNull() || !request.State.Raw.IsNull() {
+		return
+	}
+
+	var elasticIPID types.Int64
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("elastic_ip_id"), &elasticIPID)...)
+	if response.Diagnostics.HasError() || elasticIPID.Unknown || elasticIPID.Null {
+		return
+	}
+
+	elasticIP, diagnostics := findComputeElasticIP(ctx, c.elasticIPService, int(elasticIPID.Value))
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// terraform plans the replacement of an attachment before the old one is detached, so this can only be a warning
+	if elasticIP.Attachment.ID != 0 {
+		response.Diagnostics.AddAttributeWarning(
+			path.Root("elastic_ip_id"),
+			"Elastic IP Already Attached",
+			describeElasticIPAttachment(elasticIP)+" The attachment will fail unless it is detached before this resource is created.",
+		)
+	}
+}
+
+// findServerNetworkInterface returns the network interface to attach the elastic ip to, when it is not configured
+// explicitly. The server must have exactly one network interface (in the network, if one is given), as choosing one
+// of several interfaces would attach the elastic ip to whichever the api happens to list first.
+func findServerNetworkInterface(server compute.Server, networkID types.Int64) (iface compute.AttachedNetworkInterface, diagnostics diag.Diagnostics) {
+	var candidates []compute.AttachedNetworkInterface
+	for _, network := range server.Networks {
+		if !networkID.Null && network.ID != int(networkID.Value) {
+			continue
+		}
+
+		candidates = append(candidates, network.Interfaces...)
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], diagnostics
+	}
+
+	if len(candidates) > 1 {
+		ids := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			ids[i] = strconv.Itoa(candidate.ID)
+		}
+
+		detail := fmt.Sprintf("server %d has multiple network interfaces", server.ID)
+		if !networkID.Null {
+			detail += fmt.Sprintf(" in network %d", networkID.Value)
+		}
+
+		diagnostics.AddAttributeError(
+			path.Root("network_interface_id"),
+			"Ambiguous Network Interface",
+			fmt.Sprintf("%s (%s), set network_interface_id to choose the one to attach the elastic ip to", detail, strings.Join(ids, ", ")),
+		)
+		return
+	}
+
+	if networkID.Null {
+		diagnostics.AddError("Not Found", fmt.Sprintf("server %d does not have any network interface", server.ID))
+	} else {
+		diagnostics.AddError("Not Found", fmt.Sprintf("server %d does not have a network interface in network %d", server.ID, networkID.Value))
+	}
+	return
+}
+
+func describeElasticIPAttachment(elasticIP compute.ElasticIP) string {
+	return fmt.Sprintf("elastic ip %d (%s) is already attached to %s %q (%d).", elasticIP.ID, elasticIP.PublicIP, elasticIP.Attachment.Type, elasticIP.Attachment.Name, elasticIP.Attachment.ID)
+}
@@ -0,0 +1,193 @@
+package cloudbit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+func TestFindServerNetworkInterface(t *testing.T) {
+	server := compute.Server{
+		ID: 1,
+		Networks: []compute.ServerNetworkAttachment{
+			{Network: compute.Network{ID: 10}, Interfaces: []compute.AttachedNetworkInterface{{ID: 100}}},
+			{Network: compute.Network{ID: 20}, Interfaces: []compute.AttachedNetworkInterface{{ID: 200}, {ID: 201}}},
+			{Network: compute.Network{ID: 30}},
+		},
+	}
+
+	single := compute.Server{
+		ID:       2,
+		Networks: []compute.ServerNetworkAttachment{{Network: compute.Network{ID: 10}, Interfaces: []compute.AttachedNetworkInterface{{ID: 100}}}},
+	}
+
+	tests := []struct {
+		name      string
+		server    compute.Server
+		networkID types.Int64
+		iface     int
+		err       string
+	}{
+		{name: "single interface", server: single, networkID: types.Int64{Null: true}, iface: 100},
+		{name: "multiple interfaces", server: server, networkID: types.Int64{Null: true}, err: "server 1 has multiple network interfaces (100, 200, 201)"},
+		{name: "single interface in network", server: server, networkID: types.Int64{Value: 10}, iface: 100},
+		{name: "multiple interfaces in network", server: server, networkID: types.Int64{Value: 20}, err: "server 1 has multiple network interfaces in network 20 (200, 201)"},
+		{name: "no interface in network", server: server, networkID: types.Int64{Value: 30}, err: "server 1 does not have a network interface in network 30"},
+		{name: "no interface", server: compute.Server{ID: 3}, networkID: types.Int64{Null: true}, err: "server 3 does not have any network interface"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			iface, diagnostics := findServerNetworkInterface(test.server, test.networkID)
+
+			if test.err != "" {
+				if !diagnostics.HasError() || !strings.Contains(diagnostics[0].Detail(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, diagnostics)
+				}
+
+				return
+			}
+
+			if diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", diagnostics)
+			}
+
+			if iface.ID != test.iface {
+				t.Errorf("expected interface %d, got %d", test.iface, iface.ID)
+			}
+		})
+	}
+}
+
+func TestComputeElasticIPServerAttachment_Create(t *testing.T) {
+	ctx := context.Background()
+	_, prov := testFakeProvider(t)
+
+	resourceType := computeElasticIPServerAttachmentResourceType{}
+	res := testNewResource(t, resourceType, prov)
+
+	server := testFakeServer(t, prov.client)
+	primary := server.Networks[0].Interfaces[0]
+
+	create := func(t *testing.T, networkInterfaceID tftypes.Value) tfsdk.CreateResourceResponse {
+		elasticIP, err := compute.NewElasticIPService(prov.client).Create(ctx, compute.ElasticIPCreate{LocationID: fakeapi.LocationALP1})
+		if err != nil {
+			t.Fatalf("unable to create elastic ip: %s", err)
+		}
+
+		config := testResourceState(t, resourceType, map[string]tftypes.Value{
+			"server_id":            tftypes.NewValue(tftypes.Number, server.ID),
+			"elastic_ip_id":        tftypes.NewValue(tftypes.Number, elasticIP.ID),
+			"network_interface_id": networkInterfaceID,
+		})
+
+		response := tfsdk.CreateResourceResponse{State: testResourceState(t, resourceType, nil)}
+		res.Create(ctx, tfsdk.CreateResourceRequest{
+			Config: tfsdk.Config{Schema: config.Schema, Raw: config.Raw},
+			Plan:   tfsdk.Plan{Schema: config.Schema, Raw: config.Raw},
+		}, &response)
+
+		return response
+	}
+
+	t.Run("single interface", func(t *testing.T) {
+		response := create(t, tftypes.NewValue(tftypes.Number, nil))
+		if response.Diagnostics.HasError() {
+			t.Fatalf("unable to create attachment: %v", response.Diagnostics)
+		}
+
+		var state computeElasticIPServerAttachmentResourceData
+		response.State.Get(ctx, &state)
+		if state.NetworkInterfaceID.Value != int64(primary.ID) {
+			t.Errorf("expected the elastic ip to be attached to interface %d, got %v", primary.ID, state.NetworkInterfaceID)
+		}
+	})
+
+	secondary, err := compute.NewNetworkInterfaceService(prov.client, server.ID).Create(ctx, compute.NetworkInterfaceCreate{NetworkID: server.Networks[0].ID})
+	if err != nil {
+		t.Fatalf("unable to create network interface: %s", err)
+	}
+
+	t.Run("multiple interfaces", func(t *testing.T) {
+		response := create(t, tftypes.NewValue(tftypes.Number, nil))
+		if !response.Diagnostics.HasError() || response.Diagnostics[0].Summary() != "Ambiguous Network Interface" {
+			t.Fatalf("expected the network interface to be ambiguous, got %v", response.Diagnostics)
+		}
+	})
+
+	t.Run("explicit interface", func(t *testing.T) {
+		response := create(t, tftypes.NewValue(tftypes.Number, secondary.ID))
+		if response.Diagnostics.HasError() {
+			t.Fatalf("unable to create attachment: %v", response.Diagnostics)
+		}
+
+		var state computeElasticIPServerAttachmentResourceData
+		response.State.Get(ctx, &state)
+		if state.NetworkInterfaceID.Value != int64(secondary.ID) {
+			t.Errorf("expected the elastic ip to be attached to interface %d, got %v", secondary.ID, state.NetworkInterfaceID)
+		}
+	})
+}
+
+func TestComputeElasticIPServerAttachment_AlreadyAttached(t *testing.T) {
+	ctx := context.Background()
+	_, prov := testFakeProvider(t)
+
+	resourceType := computeElasticIPServerAttachmentResourceType{}
+	res := testNewResource(t, resourceType, prov)
+
+	server := testFakeServer(t, prov.client)
+
+	elasticIP, err := compute.NewElasticIPService(prov.client).Create(ctx, compute.ElasticIPCreate{LocationID: fakeapi.LocationALP1})
+	if err != nil {
+		t.Fatalf("unable to create elastic ip: %s", err)
+	}
+
+	_, err = compute.NewServerElasticIPService(prov.client, server.ID).Attach(ctx, compute.ElasticIPAttach{ElasticIPID: elasticIP.ID})
+	if err != nil {
+		t.Fatalf("unable to attach elastic ip: %s", err)
+	}
+
+	planned := testResourceState(t, resourceType, map[string]tftypes.Value{
+		"server_id":     tftypes.NewValue(tftypes.Number, server.ID),
+		"elastic_ip_id": tftypes.NewValue(tftypes.Number, elasticIP.ID),
+	})
+
+	config := tfsdk.Config{Schema: planned.Schema, Raw: planned.Raw}
+	plan := tfsdk.Plan{Schema: planned.Schema, Raw: planned.Raw}
+
+	t.Run("plan", func(t *testing.T) {
+		state := tfsdk.State{Schema: planned.Schema, Raw: tftypes.NewValue(planned.Raw.Type(), nil)}
+
+		response := tfsdk.ModifyResourcePlanResponse{Plan: plan}
+		res.(tfsdk.ResourceWithModifyPlan).ModifyPlan(ctx, tfsdk.ModifyResourcePlanRequest{Config: config, Plan: plan, State: state}, &response)
+
+		if response.Diagnostics.HasError() || response.Diagnostics.WarningsCount() != 1 {
+			t.Fatalf("expected a single warning, got %v", response.Diagnostics)
+		}
+
+		if response.Diagnostics[0].Summary() != "Elastic IP Already Attached" {
+			t.Errorf("expected the elastic ip to be reported as attached, got %v", response.Diagnostics)
+		}
+	})
+
+	t.Run("create", func(t *testing.T) {
+		response := tfsdk.CreateResourceResponse{State: testResourceState(t, resourceType, nil)}
+		res.Create(ctx, tfsdk.CreateResourceRequest{Config: config, Plan: plan}, &response)
+
+		if !response.Diagnostics.HasError() || response.Diagnostics[0].Summary() != "Elastic IP Already Attached" {
+			t.Fatalf("expected the elastic ip to be reported as attached, got %v", response.Diagnostics)
+		}
+
+		if !strings.Contains(response.Diagnostics[0].Detail(), `is already attached to server "test"`) {
+			t.Errorf("expected the detail to describe the attachment, got %q", response.Diagnostics[0].Detail())
+		}
+	})
+}
@@ -8,7 +8,7 @@ import (
 
 func TestAccComputeElasticIP_Basic(t *testing.T) {
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccComputeElasticIPConfigBasic,
@@ -27,3 +27,32 @@ resource "cloudbit_compute_elastic_ip" "foobar" {
 	location_id = 1
 }
 `
+
+func TestAccComputeElasticIP_Location(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeElasticIPConfigLocation,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudbit_compute_elastic_ip.by_key", "location", "ALP1"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_elastic_ip.by_key", "location_id"),
+					resource.TestCheckResourceAttrPair("cloudbit_compute_elastic_ip.by_default", "location_id", "cloudbit_compute_elastic_ip.by_key", "location_id"),
+				),
+			},
+		},
+	})
+}
+
+const testAccComputeElasticIPConfigLocation = `
+provider "cloudbit" {
+	default_location = "ALP1"
+}
+
+resource "cloudbit_compute_elastic_ip" "by_key" {
+	location = "ALP1"
+}
+
+resource "cloudbit_compute_elastic_ip" "by_default" {
+}
+`
@@ -2,7 +2,16 @@ package cloudbit
 
 import (
 	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/compute"
@@ -10,12 +19,25 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeKeyPairResourceType)(nil)
-	_ tfsdk.Resource                = (*computeKeyPairResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeKeyPairResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeKeyPairResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeKeyPairResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeKeyPairResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeKeyPairResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeKeyPairResource)(nil)
+	_ tfsdk.ResourceWithValidateConfig   = (*computeKeyPairResource)(nil)
+)
+
+const (
+	keyPairAlgorithmED25519 = "ed25519"
+	keyPairAlgorithmRSA     = "rsa"
+
+	keyPairDefaultRSABits = 4096
 )
 
 type computeKeyPairResourceData struct {
@@ -24,6 +46,11 @@ type computeKeyPairResourceData struct {
 
 	Name      types.String `tfsdk:"name"`
 	PublicKey types.String `tfsdk:"public_key"`
+
+	Algorithm         types.String `tfsdk:"algorithm"`
+	RSABits           types.Int64  `tfsdk:"rsa_bits"`
+	PrivateKeyPEM     types.String `tfsdk:"private_key_pem"`
+	PrivateKeyOpenSSH types.String `tfsdk:"private_key_openssh"`
 }
 
 func (d *computeKeyPairResourceData) FromEntity(keyPair compute.KeyPair) {
@@ -58,10 +85,47 @@ func (c computeKeyPairResourceType) GetSchema(ctx context.Context) (tfsdk.Schema
 			},
 			"public_key": {
 				Type:                types.StringType,
-				MarkdownDescription: "public key of the key pair",
-				Required:            true,
+				MarkdownDescription: "public key of the key pair in OpenSSH format. If omitted, a new key pair is generated. Removing the public key replaces the key pair with a generated one",
+				Optional:            true,
+				Computed:            true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+
+			"algorithm": {
+				Type:                types.StringType,
+				MarkdownDescription: "algorithm of the generated key pair (`ed25519` or `rsa`, defaults to `ed25519`)",
+				Optional:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"rsa_bits": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "size of the generated rsa key in bits (defaults to 4096)",
+				Optional:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"private_key_pem": {
+				Type:                types.StringType,
+				MarkdownDescription: "generated private key in PEM format",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"private_key_openssh": {
+				Type:                types.StringType,
+				MarkdownDescription: "generated private key in OpenSSH format",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
 				},
 			},
 		},
@@ -84,6 +148,9 @@ type computeKeyPairResource struct {
 }
 
 func (c computeKeyPairResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_key_pair", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeKeyPairResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -91,28 +158,52 @@ func (c computeKeyPairResource) Create(ctx context.Context, request tfsdk.Create
 		return
 	}
 
+	var state computeKeyPairResourceData
+	state.PublicKey = config.PublicKey
+	state.Algorithm = config.Algorithm
+	state.RSABits = config.RSABits
+	state.PrivateKeyPEM = types.String{Null: true}
+	state.PrivateKeyOpenSSH = types.String{Null: true}
+
+	if config.PublicKey.Null {
+		bits := keyPairDefaultRSABits
+		if !config.RSABits.Null {
+			bits = int(config.RSABits.Value)
+		}
+
+		generated, err := generateKeyPair(config.Algorithm.Value, bits, config.Name.Value)
+		if err != nil {
+			response.Diagnostics.AddError("Key Generation Error", fmt.Sprintf("unable to generate key pair: %s", err))
+			return
+		}
+
+		state.PublicKey = types.String{Value: generated.publicKey}
+		state.PrivateKeyPEM = types.String{Value: generated.privateKeyPEM}
+		state.PrivateKeyOpenSSH = types.String{Value: generated.privateKeyOpenSSH}
+	}
+
 	create := compute.KeyPairCreate{
 		Name:      config.Name.Value,
-		PublicKey: config.PublicKey.Value,
+		PublicKey: state.PublicKey.Value,
 	}
 
 	keyPair, err := c.keyPairService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create key pair: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create key pair", err)
 		return
 	}
 
-	var state computeKeyPairResourceData
+	// the public and private keys were copied from the config or generated because the api does not return them
 	state.FromEntity(keyPair)
 
-	// copy the public key from the config because the api does not return it
-	state.PublicKey = config.PublicKey
-
 	diagnostics = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diagnostics...)
 }
 
 func (c computeKeyPairResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_key_pair", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeKeyPairResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -122,7 +213,7 @@ func (c computeKeyPairResource) Read(ctx context.Context, request tfsdk.ReadReso
 
 	list, err := c.keyPairService.List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list key pairs: %s", err))
+		addClientError(&response.Diagnostics, "unable to list key pairs", err)
 		return
 	}
 
@@ -140,10 +231,16 @@ func (c computeKeyPairResource) Read(ctx context.Context, request tfsdk.ReadReso
 }
 
 func (c computeKeyPairResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_key_pair", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	response.Diagnostics.AddError("Not Supported", "updating a key pair is not supported")
 }
 
 func (c computeKeyPairResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_key_pair", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeKeyPairResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -153,7 +250,7 @@ func (c computeKeyPairResource) Delete(ctx context.Context, request tfsdk.Delete
 
 	err := c.keyPairService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete key pair: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete key pair", err)
 		return
 	}
 }
@@ -161,3 +258,193 @@ func (c computeKeyPairResource) Delete(ctx context.Context, request tfsdk.Delete
 func (c computeKeyPairResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (c computeKeyPairResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	if request.Plan.Raw.IsNull() || request.State.Raw.IsNull() {
+		return
+	}
+
+	var config, state computeKeyPairResourceData
+	response.Diagnostics.Append(request.Config.Get(ctx, &config)...)
+	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// the state of a generated or imported key pair is kept, but a public key which was removed from the config
+	// needs to be replaced by a generated one instead of silently keeping the old key from the state
+	if !config.PublicKey.Null || state.PublicKey.Null || !state.PrivateKeyOpenSSH.Null {
+		return
+	}
+
+	for _, name := range []string{"public_key", "private_key_pem", "private_key_openssh"} {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root(name), types.String{Unknown: true})...)
+	}
+
+	response.RequiresReplace = append(response.RequiresReplace, path.Root("public_key"))
+}
+
+func (c computeKeyPairResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("public_key", "algorithm"),
+		validators.MutuallyExclusive("public_key", "rsa_bits"),
+	}
+}
+
+func (c computeKeyPairResource) ValidateConfig(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	var config computeKeyPairResourceData
+	diagnostics := request.Config.Get(ctx, &config)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.Algorithm.Null && !config.Algorithm.Unknown {
+		if config.Algorithm.Value != keyPairAlgorithmED25519 && config.Algorithm.Value != keyPairAlgorithmRSA {
+			response.Diagnostics.AddAttributeError(
+				path.Root("algorithm"),
+				"Invalid Algorithm",
+				fmt.Sprintf("The algorithm %q is not supported. Please use either %q or %q.", config.Algorithm.Value, keyPairAlgorithmED25519, keyPairAlgorithmRSA),
+			)
+		}
+	}
+
+	if !config.RSABits.Null && !config.RSABits.Unknown {
+		if config.Algorithm.Value != keyPairAlgorithmRSA && !config.Algorithm.Unknown {
+			response.Diagnostics.AddAttributeError(path.Root("rsa_bits"), "Invalid Attribute Combination", "The rsa_bits attribute can only be used with the rsa algorithm.")
+		}
+
+		if config.RSABits.Value < 2048 {
+			response.Diagnostics.AddAttributeError(path.Root("rsa_bits"), "Invalid Key Size", "The rsa key needs to be at least 2048 bits long.")
+		}
+	}
+}
+
+type generatedKeyPair struct {
+	publicKey         string
+	privateKeyPEM     string
+	privateKeyOpenSSH string
+}
+
+func generateKeyPair(algorithm string, rsaBits int, comment string) (generated generatedKeyPair, err error) {
+	var publicKey crypto.PublicKey
+	var pemBlock *pem.Block
+	var opensshKey []byte
+
+	switch algorithm {
+	case keyPairAlgorithmRSA:
+		privateKey, err := rsa.GenerateKey(rand.Reader, rsaBits)
+		if err != nil {
+			return generated, err
+		}
+
+		publicKey = privateKey.Public()
+		pemBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}
+		opensshKey = ssh.Marshal(struct {
+			N       *big.Int
+			E       *big.Int
+			D       *big.Int
+			Iqmp    *big.Int
+			P       *big.Int
+			Q       *big.Int
+			Comment string
+		}{
+			N:       privateKey.N,
+			E:       big.NewInt(int64(privateKey.E)),
+			D:       privateKey.D,
+			Iqmp:    privateKey.Precomputed.Qinv,
+			P:       privateKey.Primes[0],
+			Q:       privateKey.Primes[1],
+			Comment: comment,
+		})
+
+	case keyPairAlgorithmED25519, "":
+		public, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return generated, err
+		}
+
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return generated, err
+		}
+
+		publicKey = public
+		pemBlock = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		opensshKey = ssh.Marshal(struct {
+			Pub     []byte
+			Priv    []byte
+			Comment string
+		}{
+			Pub:     public,
+			Priv:    privateKey,
+			Comment: comment,
+		})
+
+	default:
+		return generated, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return generated, err
+	}
+
+	privateKeyOpenSSH, err := marshalOpenSSHPrivateKey(sshPublicKey, opensshKey)
+	if err != nil {
+		return generated, err
+	}
+
+	generated.publicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	generated.privateKeyPEM = string(pem.EncodeToMemory(pemBlock))
+	generated.privateKeyOpenSSH = string(privateKeyOpenSSH)
+	return generated, nil
+}
+
+// marshalOpenSSHPrivateKey encodes an unencrypted private key in the
+// openssh-key-v1 format (see PROTOCOL.key of OpenSSH). The key contains the
+// algorithm specific fields of the private key including the comment.
+func marshalOpenSSHPrivateKey(publicKey ssh.PublicKey, key []byte) ([]byte, error) {
+	checkBytes := make([]byte, 4)
+	if _, err := rand.Read(checkBytes); err != nil {
+		return nil, err
+	}
+	check := binary.BigEndian.Uint32(checkBytes)
+
+	block := ssh.Marshal(struct {
+		Check1  uint32
+		Check2  uint32
+		Keytype string
+		Rest    []byte `ssh:"rest"`
+	}{
+		Check1:  check,
+		Check2:  check,
+		Keytype: publicKey.Type(),
+		Rest:    key,
+	})
+
+	// pad the block to the cipher block size, which is 8 for unencrypted keys
+	for i := byte(1); len(block)%8 != 0; i++ {
+		block = append(block, i)
+	}
+
+	data := ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       publicKey.Marshal(),
+		PrivKeyBlock: block,
+	})
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte("openssh-key-v1\x00"), data...),
+	}), nil
+}
@@ -1,22 +1,29 @@
 package cloudbit
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestAccComputeKeyPair_Basic(t *testing.T) {
-	keyPairName := acctest.RandomWithPrefix("test-key-pair")
+	keyPairName := testAccRandomName(t, "test-key-pair")
 	public, _, err := acctest.RandSSHKeyPair("test-key-pair")
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeKeyPairConfigBasic, keyPairName, public),
@@ -37,3 +44,143 @@ resource "cloudbit_compute_key_pair" "foobar" {
 	public_key  = "%s"
 }
 `
+
+func TestAccComputeKeyPair_Generated(t *testing.T) {
+	keyPairName := testAccRandomName(t, "test-key-pair")
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccComputeKeyPairConfigGenerated, keyPairName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("cloudbit_compute_key_pair.foobar", "id"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_key_pair.foobar", "fingerprint"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_key_pair.foobar", "public_key"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_key_pair.foobar", "private_key_pem"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_key_pair.foobar", "private_key_openssh"),
+				),
+			},
+		},
+	})
+}
+
+const testAccComputeKeyPairConfigGenerated = `
+resource "cloudbit_compute_key_pair" "foobar" {
+	name      = "%s"
+	algorithm = "ed25519"
+}
+`
+
+func TestGenerateKeyPair_RoundTrip(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		rsaBits   int
+		keyType   string
+	}{
+		{algorithm: keyPairAlgorithmED25519, keyType: ssh.KeyAlgoED25519},
+		{algorithm: keyPairAlgorithmRSA, rsaBits: 2048, keyType: ssh.KeyAlgoRSA},
+	}
+
+	for _, test := range tests {
+		t.Run(test.algorithm, func(t *testing.T) {
+			generated, err := generateKeyPair(test.algorithm, test.rsaBits, "test@cloudbit")
+			if err != nil {
+				t.Fatalf("unable to generate key pair: %s", err)
+			}
+
+			publicKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(generated.publicKey))
+			if err != nil {
+				t.Fatalf("unable to parse public key: %s", err)
+			}
+
+			if publicKey.Type() != test.keyType {
+				t.Errorf("expected key type %s, got %s", test.keyType, publicKey.Type())
+			}
+
+			if comment != "" {
+				t.Errorf("expected the public key to have no comment, got %q", comment)
+			}
+
+			for name, privateKey := range map[string]string{
+				"openssh": generated.privateKeyOpenSSH,
+				"pem":     generated.privateKeyPEM,
+			} {
+				signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+				if err != nil {
+					t.Fatalf("unable to parse %s private key: %s", name, err)
+				}
+
+				if !bytes.Equal(signer.PublicKey().Marshal(), publicKey.Marshal()) {
+					t.Errorf("expected the %s private key to belong to the public key", name)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeKeyPair_ModifyPlanPublicKey(t *testing.T) {
+	ctx := context.Background()
+	resourceType := computeKeyPairResourceType{}
+
+	publicKey := tftypes.NewValue(tftypes.String, "ssh-ed25519 AAAA")
+	null := tftypes.NewValue(tftypes.String, nil)
+
+	tests := []struct {
+		name              string
+		configPublicKey   tftypes.Value
+		statePublicKey    tftypes.Value
+		statePrivateKey   tftypes.Value
+		replace           bool
+		expectedPublicKey types.String
+	}{
+		{name: "supplied key", configPublicKey: publicKey, statePublicKey: publicKey, statePrivateKey: null, expectedPublicKey: types.String{Value: "ssh-ed25519 AAAA"}},
+		{name: "removed key", configPublicKey: null, statePublicKey: publicKey, statePrivateKey: null, replace: true, expectedPublicKey: types.String{Unknown: true}},
+		{name: "generated key", configPublicKey: null, statePublicKey: publicKey, statePrivateKey: tftypes.NewValue(tftypes.String, "private"), expectedPublicKey: types.String{Value: "ssh-ed25519 AAAA"}},
+		{name: "imported key", configPublicKey: null, statePublicKey: null, statePrivateKey: null, expectedPublicKey: types.String{Null: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := testResourceState(t, resourceType, map[string]tftypes.Value{
+				"id":                  tftypes.NewValue(tftypes.Number, 1),
+				"name":                tftypes.NewValue(tftypes.String, "test"),
+				"public_key":          test.statePublicKey,
+				"private_key_openssh": test.statePrivateKey,
+				"private_key_pem":     test.statePrivateKey,
+			})
+			config := testResourceState(t, resourceType, map[string]tftypes.Value{
+				"name":       tftypes.NewValue(tftypes.String, "test"),
+				"public_key": test.configPublicKey,
+			})
+
+			// the public key and the private keys are taken from the state by their plan modifiers
+			request := tfsdk.ModifyResourcePlanRequest{
+				Config: tfsdk.Config{Schema: config.Schema, Raw: config.Raw},
+				Plan:   tfsdk.Plan{Schema: state.Schema, Raw: state.Raw},
+				State:  state,
+			}
+
+			response := tfsdk.ModifyResourcePlanResponse{Plan: request.Plan}
+			computeKeyPairResource{}.ModifyPlan(ctx, request, &response)
+			if response.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", response.Diagnostics)
+			}
+
+			replace := len(response.RequiresReplace) == 1 && response.RequiresReplace[0].Equal(path.Root("public_key"))
+			if replace != test.replace || !test.replace && len(response.RequiresReplace) != 0 {
+				t.Errorf("expected replace %t, got %v", test.replace, response.RequiresReplace)
+			}
+
+			var plan computeKeyPairResourceData
+			response.Plan.Get(ctx, &plan)
+			if !plan.PublicKey.Equal(test.expectedPublicKey) {
+				t.Errorf("expected public key %v, got %v", test.expectedPublicKey, plan.PublicKey)
+			}
+
+			if test.replace && !plan.PrivateKeyOpenSSH.Unknown {
+				t.Errorf("expected the private key to be generated again, got %v", plan.PrivateKeyOpenSSH)
+			}
+		})
+	}
+}
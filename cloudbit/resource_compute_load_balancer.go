@@ -2,7 +2,6 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/flowswiss/goclient/common"
 	"github.com/flowswiss/goclient/compute"
@@ -10,18 +9,25 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeLoadBalancerResourceType)(nil)
-	_ tfsdk.Resource                = (*computeLoadBalancerResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeLoadBalancerResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeLoadBalancerResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeLoadBalancerResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeLoadBalancerResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeLoadBalancerResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeLoadBalancerResource)(nil)
 )
 
 type computeLoadBalancerResourceData struct {
 	ID         types.Int64  `tfsdk:"id"`
 	Name       types.String `tfsdk:"name"`
 	LocationID types.Int64  `tfsdk:"location_id"`
+	Location   types.String `tfsdk:"location"`
 	NetworkID  types.Int64  `tfsdk:"network_id"`
 	PrivateIP  types.String `tfsdk:"private_ip"`
 }
@@ -30,6 +36,7 @@ func (c *computeLoadBalancerResourceData) FromEntity(loadBalancer compute.LoadBa
 	c.ID = types.Int64{Value: int64(loadBalancer.ID)}
 	c.Name = types.String{Value: loadBalancer.Name}
 	c.LocationID = types.Int64{Value: int64(loadBalancer.Location.ID)}
+	c.Location = types.String{Value: loadBalancer.Location.Key}
 
 	if len(loadBalancer.Networks) != 0 {
 		network := loadBalancer.Networks[0]
@@ -56,14 +63,8 @@ func (c computeLoadBalancerResourceType) GetSchema(ctx context.Context) (tfsdk.S
 				MarkdownDescription: "name of the load balancer",
 				Required:            true,
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the location",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("unique identifier of the location"),
+			"location":    locationAttribute(),
 			"network_id": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "unique identifier of the initial network",
@@ -92,23 +93,36 @@ func (c computeLoadBalancerResourceType) NewResource(ctx context.Context, p tfsd
 	}
 
 	return computeLoadBalancerResource{
+		locations: newLocationResolver(prov),
+
 		loadBalancerService: compute.NewLoadBalancerService(prov.client),
 		orderService:        common.NewOrderService(prov.client),
 	}, diagnostics
 }
 
 type computeLoadBalancerResource struct {
+	locations locationResolver
+
 	loadBalancerService compute.LoadBalancerService
 	orderService        common.OrderService
 }
 
 func (c computeLoadBalancerResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerResourceData
 	response.Diagnostics.Append(request.Config.Get(ctx, &config)...)
 	if response.Diagnostics.HasError() {
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := compute.LoadBalancerCreate{
 		Name:             config.Name.Value,
 		LocationID:       int(config.LocationID.Value),
@@ -119,25 +133,25 @@ func (c computeLoadBalancerResource) Create(ctx context.Context, request tfsdk.C
 
 	ordering, err := c.loadBalancerService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create load balancer: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create load balancer", err)
 		return
 	}
 
-	order, err := c.orderService.WaitUntilProcessed(ctx, ordering)
+	order, err := waitForOrder(ctx, c.orderService, ordering)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("waiting for load balancer creation: %s", err))
+		addClientError(&response.Diagnostics, "waiting for load balancer creation", err)
 		return
 	}
 
 	loadBalancer, err := c.loadBalancerService.Get(ctx, order.Product.ID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get load balancer: %s", err))
+		addClientError(&response.Diagnostics, "unable to get load balancer", err)
 		return
 	}
 
-	err = c.loadBalancerService.WaitUntilMutable(ctx, loadBalancer.ID)
+	err = waitForLoadBalancer(ctx, c.loadBalancerService, loadBalancer.ID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("waiting for load balancer to be mutable: %s", err))
+		addClientError(&response.Diagnostics, "waiting for load balancer to be mutable", err)
 		return
 	}
 
@@ -148,6 +162,9 @@ func (c computeLoadBalancerResource) Create(ctx context.Context, request tfsdk.C
 }
 
 func (c computeLoadBalancerResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerResourceData
 	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
 	if response.Diagnostics.HasError() {
@@ -156,7 +173,7 @@ func (c computeLoadBalancerResource) Read(ctx context.Context, request tfsdk.Rea
 
 	loadBalancer, err := c.loadBalancerService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get load balancer: %s", err))
+		addClientError(&response.Diagnostics, "unable to get load balancer", err)
 		return
 	}
 
@@ -166,6 +183,9 @@ func (c computeLoadBalancerResource) Read(ctx context.Context, request tfsdk.Rea
 }
 
 func (c computeLoadBalancerResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerResourceData
 	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
 	if response.Diagnostics.HasError() {
@@ -184,7 +204,7 @@ func (c computeLoadBalancerResource) Update(ctx context.Context, request tfsdk.U
 
 	loadBalancer, err := c.loadBalancerService.Update(ctx, int(state.ID.Value), update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update load balancer: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update load balancer", err)
 		return
 	}
 
@@ -194,6 +214,9 @@ func (c computeLoadBalancerResource) Update(ctx context.Context, request tfsdk.U
 }
 
 func (c computeLoadBalancerResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerResourceData
 	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
 	if response.Diagnostics.HasError() {
@@ -202,7 +225,7 @@ func (c computeLoadBalancerResource) Delete(ctx context.Context, request tfsdk.D
 
 	err := c.loadBalancerService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete load balancer: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete load balancer", err)
 		return
 	}
 }
@@ -210,3 +233,37 @@ func (c computeLoadBalancerResource) Delete(ctx context.Context, request tfsdk.D
 func (c computeLoadBalancerResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (c computeLoadBalancerResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	location, known := c.locations.ModifyPlan(ctx, request, response)
+	if !known || response.Diagnostics.HasError() {
+		return
+	}
+
+	if !request.State.Raw.IsNull() {
+		var stateLocationID types.Int64
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, locationIDPath, &stateLocationID)...)
+		if response.Diagnostics.HasError() || stateLocationID.Value == int64(location.ID) {
+			return
+		}
+	}
+
+	c.locations.CheckModule(ctx, location, moduleComputeNetworking, &response.Diagnostics)
+	c.locations.CheckProductType(ctx, productTypeLoadBalancer, location, &response.Diagnostics)
+}
+
+func (c computeLoadBalancerResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
+
+// waitForLoadBalancer waits until the load balancer is mutable, tracing the wait in a span.
+func waitForLoadBalancer(ctx context.Context, loadBalancerService compute.LoadBalancerService, loadBalancerID int) error {
+	ctx, span := startSpan(ctx, "WaitUntilMutable", trace.WithAttributes(attribute.Int("cloudbit.load_balancer_id", loadBalancerID)))
+
+	err := loadBalancerService.WaitUntilMutable(ctx, loadBalancerID)
+	endSpan(span, err)
+
+	return err
+}
@@ -118,6 +118,9 @@ type computeLoadBalancerMemberResource struct {
 }
 
 func (c computeLoadBalancerMemberResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_member", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerMemberResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -136,13 +139,13 @@ func (c computeLoadBalancerMemberResource) Create(ctx context.Context, request t
 
 	member, err := c.loadBalancerService.Pools(loadBalancerID).Members(poolID).Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create load balancer member: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create load balancer member", err)
 		return
 	}
 
-	err = c.loadBalancerService.WaitUntilMutable(ctx, loadBalancerID)
+	err = waitForLoadBalancer(ctx, c.loadBalancerService, loadBalancerID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to wait until load balancer is mutable: %s", err))
+		addClientError(&response.Diagnostics, "unable to wait until load balancer is mutable", err)
 		return
 	}
 
@@ -154,6 +157,9 @@ func (c computeLoadBalancerMemberResource) Create(ctx context.Context, request t
 }
 
 func (c computeLoadBalancerMemberResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_member", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerMemberResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -166,7 +172,7 @@ func (c computeLoadBalancerMemberResource) Read(ctx context.Context, request tfs
 
 	list, err := c.loadBalancerService.Pools(loadBalancerID).Members(poolID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list load balancer members: %s", err))
+		addClientError(&response.Diagnostics, "unable to list load balancer members", err)
 		return
 	}
 
@@ -183,10 +189,16 @@ func (c computeLoadBalancerMemberResource) Read(ctx context.Context, request tfs
 }
 
 func (c computeLoadBalancerMemberResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_member", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	response.Diagnostics.AddError("Not Supported", "updating a load balancer member is not supported")
 }
 
 func (c computeLoadBalancerMemberResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_member", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerMemberResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -200,13 +212,13 @@ func (c computeLoadBalancerMemberResource) Delete(ctx context.Context, request t
 
 	err := c.loadBalancerService.Pools(loadBalancerID).Members(poolID).Delete(ctx, memberID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete load balancer member: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete load balancer member", err)
 		return
 	}
 
-	err = c.loadBalancerService.WaitUntilMutable(ctx, loadBalancerID)
+	err = waitForLoadBalancer(ctx, c.loadBalancerService, loadBalancerID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to wait until load balancer is mutable: %s", err))
+		addClientError(&response.Diagnostics, "unable to wait until load balancer is mutable", err)
 		return
 	}
 }
@@ -3,19 +3,29 @@ package cloudbit
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/filter"
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType = (*computeLoadBalancerPoolResourceType)(nil)
-	_ tfsdk.Resource     = (*computeLoadBalancerPoolResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeLoadBalancerPoolResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeLoadBalancerPoolResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeLoadBalancerPoolResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeLoadBalancerPoolResource)(nil)
 )
 
+const loadBalancerProtocolHTTPS = "https"
+
 type computeLoadBalancerHTTPHealthCheckResourceData struct {
 	Method types.String `tfsdk:"method"`
 	Path   types.String `tfsdk:"path"`
@@ -23,6 +33,7 @@ type computeLoadBalancerHTTPHealthCheckResourceData struct {
 
 type computeLoadBalancerHealthCheckResourceData struct {
 	TypeID types.Int64                                     `tfsdk:"type_id"`
+	Type   types.String                                    `tfsdk:"type"`
 	HTTP   *computeLoadBalancerHTTPHealthCheckResourceData `tfsdk:"http"`
 
 	Interval types.String `tfsdk:"interval"`
@@ -38,12 +49,15 @@ type computeLoadBalancerPoolResourceData struct {
 
 	Name types.String `tfsdk:"name"`
 
-	BalancingAlgorithmID types.Int64 `tfsdk:"balancing_algorithm_id"`
-	StickySession        types.Bool  `tfsdk:"sticky_session"`
+	BalancingAlgorithmID types.Int64  `tfsdk:"balancing_algorithm_id"`
+	BalancingAlgorithm   types.String `tfsdk:"balancing_algorithm"`
+	StickySession        types.Bool   `tfsdk:"sticky_session"`
 
-	EntryProtocolID  types.Int64 `tfsdk:"entry_protocol_id"`
-	EntryPort        types.Int64 `tfsdk:"entry_port"`
-	TargetProtocolID types.Int64 `tfsdk:"target_protocol_id"`
+	EntryProtocolID  types.Int64  `tfsdk:"entry_protocol_id"`
+	EntryProtocol    types.String `tfsdk:"entry_protocol"`
+	EntryPort        types.Int64  `tfsdk:"entry_port"`
+	TargetProtocolID types.Int64  `tfsdk:"target_protocol_id"`
+	TargetProtocol   types.String `tfsdk:"target_protocol"`
 
 	CertificateID types.Int64 `tfsdk:"certificate_id"`
 
@@ -56,11 +70,14 @@ func (c *computeLoadBalancerPoolResourceData) FromEntity(loadBalancerID int, poo
 	c.Name = types.String{Value: pool.Name}
 
 	c.BalancingAlgorithmID = types.Int64{Value: int64(pool.Algorithm.ID)}
+	c.BalancingAlgorithm = types.String{Value: pool.Algorithm.Key}
 	c.StickySession = types.Bool{Value: pool.StickySession}
 
 	c.EntryProtocolID = types.Int64{Value: int64(pool.EntryProtocol.ID)}
+	c.EntryProtocol = types.String{Value: pool.EntryProtocol.Key}
 	c.EntryPort = types.Int64{Value: int64(pool.EntryPort)}
 	c.TargetProtocolID = types.Int64{Value: int64(pool.TargetProtocol.ID)}
+	c.TargetProtocol = types.String{Value: pool.TargetProtocol.Key}
 
 	if pool.Certificate.ID == 0 {
 		c.CertificateID = types.Int64{Null: true}
@@ -70,6 +87,7 @@ func (c *computeLoadBalancerPoolResourceData) FromEntity(loadBalancerID int, poo
 
 	c.HealthCheck = &computeLoadBalancerHealthCheckResourceData{
 		TypeID:             types.Int64{Value: int64(pool.HealthCheck.Type.ID)},
+		Type:               types.String{Value: pool.HealthCheck.Type.Key},
 		HTTP:               nil,
 		Interval:           types.String{Value: (time.Duration(pool.HealthCheck.Interval) * time.Second).String()},
 		Timeout:            types.String{Value: (time.Duration(pool.HealthCheck.Timeout) * time.Second).String()},
@@ -85,6 +103,20 @@ func (c *computeLoadBalancerPoolResourceData) FromEntity(loadBalancerID int, poo
 	}
 }
 
+// SetResolvedIdentifiers copies the identifiers, which might have been resolved
+// from their keys during planning, from the plan into the config data.
+func (c *computeLoadBalancerPoolResourceData) SetResolvedIdentifiers(ctx context.Context, plan tfsdk.Plan) (diagnostics diag.Diagnostics) {
+	diagnostics.Append(plan.GetAttribute(ctx, path.Root("balancing_algorithm_id"), &c.BalancingAlgorithmID)...)
+	diagnostics.Append(plan.GetAttribute(ctx, path.Root("entry_protocol_id"), &c.EntryProtocolID)...)
+	diagnostics.Append(plan.GetAttribute(ctx, path.Root("target_protocol_id"), &c.TargetProtocolID)...)
+
+	if c.HealthCheck != nil {
+		diagnostics.Append(plan.GetAttribute(ctx, path.Root("health_check").AtName("type_id"), &c.HealthCheck.TypeID)...)
+	}
+
+	return
+}
+
 type computeLoadBalancerPoolResourceType struct{}
 
 func (c computeLoadBalancerPoolResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
@@ -119,7 +151,14 @@ func (c computeLoadBalancerPoolResourceType) GetSchema(ctx context.Context) (tfs
 			"balancing_algorithm_id": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "unique identifier of the balancing algorithm",
-				Required:            true,
+				Optional:            true,
+				Computed:            true,
+			},
+			"balancing_algorithm": {
+				Type:                types.StringType,
+				MarkdownDescription: "key of the balancing algorithm (e.g. `round_robin`)",
+				Optional:            true,
+				Computed:            true,
 			},
 			"sticky_session": {
 				Type:                types.BoolType,
@@ -134,11 +173,18 @@ func (c computeLoadBalancerPoolResourceType) GetSchema(ctx context.Context) (tfs
 			"entry_protocol_id": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "unique identifier of the entry protocol",
-				Required:            true,
+				Optional:            true,
+				Computed:            true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
 				},
 			},
+			"entry_protocol": {
+				Type:                types.StringType,
+				MarkdownDescription: "key of the entry protocol (e.g. `https`)",
+				Optional:            true,
+				Computed:            true,
+			},
 			"entry_port": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "entry port of the load balancer pool",
@@ -150,11 +196,18 @@ func (c computeLoadBalancerPoolResourceType) GetSchema(ctx context.Context) (tfs
 			"target_protocol_id": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "unique identifier of the target protocol",
-				Required:            true,
+				Optional:            true,
+				Computed:            true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
 				},
 			},
+			"target_protocol": {
+				Type:                types.StringType,
+				MarkdownDescription: "key of the target protocol (e.g. `http`)",
+				Optional:            true,
+				Computed:            true,
+			},
 
 			"certificate_id": {
 				Type:                types.Int64Type,
@@ -167,7 +220,14 @@ func (c computeLoadBalancerPoolResourceType) GetSchema(ctx context.Context) (tfs
 					"type_id": {
 						Type:                types.Int64Type,
 						MarkdownDescription: "unique identifier of the health check type",
-						Required:            true,
+						Optional:            true,
+						Computed:            true,
+					},
+					"type": {
+						Type:                types.StringType,
+						MarkdownDescription: "key of the health check type (e.g. `http`)",
+						Optional:            true,
+						Computed:            true,
 					},
 					"http": {
 						Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
@@ -238,15 +298,20 @@ func (c computeLoadBalancerPoolResourceType) NewResource(ctx context.Context, p
 	}
 
 	return computeLoadBalancerPoolResource{
-		loadBalancerService: compute.NewLoadBalancerService(prov.client),
+		loadBalancerService:       compute.NewLoadBalancerService(prov.client),
+		loadBalancerEntityService: compute.NewLoadBalancerEntityService(prov.client),
 	}, diagnostics
 }
 
 type computeLoadBalancerPoolResource struct {
-	loadBalancerService compute.LoadBalancerService
+	loadBalancerService       compute.LoadBalancerService
+	loadBalancerEntityService compute.LoadBalancerEntityService
 }
 
 func (c computeLoadBalancerPoolResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_pool", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeLoadBalancerPoolResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -254,6 +319,12 @@ func (c computeLoadBalancerPoolResource) Create(ctx context.Context, request tfs
 		return
 	}
 
+	diagnostics = config.SetResolvedIdentifiers(ctx, request.Plan)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	healthCheck, diagnostics := convertHealthCheckConfigToAPIOptions(*config.HealthCheck)
 	response.Diagnostics.Append(diagnostics...)
 	if response.Diagnostics.HasError() {
@@ -274,13 +345,13 @@ func (c computeLoadBalancerPoolResource) Create(ctx context.Context, request tfs
 
 	pool, err := c.loadBalancerService.Pools(loadBalancerID).Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create load balancer pool: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create load balancer pool", err)
 		return
 	}
 
-	err = c.loadBalancerService.WaitUntilMutable(ctx, loadBalancerID)
+	err = waitForLoadBalancer(ctx, c.loadBalancerService, loadBalancerID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to wait until load balancer is mutable: %s", err))
+		addClientError(&response.Diagnostics, "unable to wait until load balancer is mutable", err)
 		return
 	}
 
@@ -292,6 +363,9 @@ func (c computeLoadBalancerPoolResource) Create(ctx context.Context, request tfs
 }
 
 func (c computeLoadBalancerPoolResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_pool", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerPoolResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -303,7 +377,7 @@ func (c computeLoadBalancerPoolResource) Read(ctx context.Context, request tfsdk
 
 	pool, err := c.loadBalancerService.Pools(loadBalancerID).Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get load balancer pool: %s", err))
+		addClientError(&response.Diagnostics, "unable to get load balancer pool", err)
 		return
 	}
 
@@ -314,6 +388,9 @@ func (c computeLoadBalancerPoolResource) Read(ctx context.Context, request tfsdk
 }
 
 func (c computeLoadBalancerPoolResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_pool", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerPoolResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -328,6 +405,12 @@ func (c computeLoadBalancerPoolResource) Update(ctx context.Context, request tfs
 		return
 	}
 
+	diagnostics = config.SetResolvedIdentifiers(ctx, request.Plan)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	healthCheck, diagnostics := convertHealthCheckConfigToAPIOptions(*config.HealthCheck)
 	response.Diagnostics.Append(diagnostics...)
 	if response.Diagnostics.HasError() {
@@ -346,13 +429,13 @@ func (c computeLoadBalancerPoolResource) Update(ctx context.Context, request tfs
 
 	pool, err := c.loadBalancerService.Pools(loadBalancerID).Update(ctx, poolID, update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update load balancer pool: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update load balancer pool", err)
 		return
 	}
 
-	err = c.loadBalancerService.WaitUntilMutable(ctx, loadBalancerID)
+	err = waitForLoadBalancer(ctx, c.loadBalancerService, loadBalancerID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to wait until load balancer is mutable: %s", err))
+		addClientError(&response.Diagnostics, "unable to wait until load balancer is mutable", err)
 		return
 	}
 
@@ -363,6 +446,9 @@ func (c computeLoadBalancerPoolResource) Update(ctx context.Context, request tfs
 }
 
 func (c computeLoadBalancerPoolResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_load_balancer_pool", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeLoadBalancerPoolResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -375,15 +461,207 @@ func (c computeLoadBalancerPoolResource) Delete(ctx context.Context, request tfs
 
 	err := c.loadBalancerService.Pools(loadBalancerID).Delete(ctx, poolID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete load balancer pool: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete load balancer pool", err)
 		return
 	}
 
-	err = c.loadBalancerService.WaitUntilMutable(ctx, loadBalancerID)
+	err = waitForLoadBalancer(ctx, c.loadBalancerService, loadBalancerID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to wait until load balancer is mutable: %s", err))
+		addClientError(&response.Diagnostics, "unable to wait until load balancer is mutable", err)
+		return
+	}
+}
+
+func (c computeLoadBalancerPoolResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("balancing_algorithm_id", "balancing_algorithm"),
+		validators.AtLeastOneOf("balancing_algorithm_id", "balancing_algorithm"),
+		validators.MutuallyExclusive("entry_protocol_id", "entry_protocol"),
+		validators.AtLeastOneOf("entry_protocol_id", "entry_protocol"),
+		validators.MutuallyExclusive("target_protocol_id", "target_protocol"),
+		validators.AtLeastOneOf("target_protocol_id", "target_protocol"),
+		validators.MutuallyExclusive("health_check.type_id", "health_check.type"),
+		validators.AtLeastOneOf("health_check.type_id", "health_check.type"),
+	}
+}
+
+func (c computeLoadBalancerPoolResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	lists := map[string]func(ctx context.Context) ([]loadBalancerEntity, error){
+		"protocol":          c.listProtocols,
+		"algorithm":         c.listAlgorithms,
+		"health check type": c.listHealthCheckTypes,
+	}
+
+	references := []struct {
+		idPath   path.Path
+		keyPath  path.Path
+		kind     string
+		replaces bool
+	}{
+		{path.Root("balancing_algorithm_id"), path.Root("balancing_algorithm"), "algorithm", false},
+		{path.Root("entry_protocol_id"), path.Root("entry_protocol"), "protocol", true},
+		{path.Root("target_protocol_id"), path.Root("target_protocol"), "protocol", true},
+		{path.Root("health_check").AtName("type_id"), path.Root("health_check").AtName("type"), "health check type", false},
+	}
+
+	cache := map[string][]loadBalancerEntity{}
+
+	for _, reference := range references {
+		var ref loadBalancerEntityFilter
+
+		response.Diagnostics.Append(request.Plan.GetAttribute(ctx, reference.idPath, &ref.ID)...)
+		response.Diagnostics.Append(request.Plan.GetAttribute(ctx, reference.keyPath, &ref.Key)...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+
+		// nothing to resolve if both are already known or neither of them is
+		if ref.ID.Unknown == ref.Key.Unknown {
+			continue
+		}
+
+		entities, found := cache[reference.kind]
+		if !found {
+			var err error
+
+			entities, err = lists[reference.kind](ctx)
+			if err != nil {
+				addClientError(&response.Diagnostics, fmt.Sprintf("unable to list load balancer %ss", reference.kind), err)
+				return
+			}
+
+			cache[reference.kind] = entities
+		}
+
+		entity, err := filter.FindOne(ref, entities)
+		if err != nil {
+			attributePath := reference.keyPath
+			if ref.Key.Unknown {
+				attributePath = reference.idPath
+			}
+
+			response.Diagnostics.AddAttributeError(attributePath, "Not Found", fmt.Sprintf("unable to find load balancer %s: %s", reference.kind, err))
+			continue
+		}
+
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, reference.idPath, types.Int64{Value: int64(entity.ID)})...)
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, reference.keyPath, types.String{Value: entity.Key})...)
+
+		if reference.replaces && !request.State.Raw.IsNull() {
+			var stateID types.Int64
+			response.Diagnostics.Append(request.State.GetAttribute(ctx, reference.idPath, &stateID)...)
+
+			if int(stateID.Value) != entity.ID {
+				response.RequiresReplace = append(response.RequiresReplace, reference.idPath)
+			}
+		}
+	}
+
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	c.validatePlan(ctx, request.Config, response)
+}
+
+func (c computeLoadBalancerPoolResource) validatePlan(ctx context.Context, config tfsdk.Config, response *tfsdk.ModifyResourcePlanResponse) {
+	var entryProtocol, healthCheckType types.String
+	var certificateID types.Int64
+	var http types.Object
+
+	response.Diagnostics.Append(response.Plan.GetAttribute(ctx, path.Root("entry_protocol"), &entryProtocol)...)
+	response.Diagnostics.Append(response.Plan.GetAttribute(ctx, path.Root("health_check").AtName("type"), &healthCheckType)...)
+	response.Diagnostics.Append(config.GetAttribute(ctx, path.Root("certificate_id"), &certificateID)...)
+	response.Diagnostics.Append(config.GetAttribute(ctx, path.Root("health_check").AtName("http"), &http)...)
+	if response.Diagnostics.HasError() {
 		return
 	}
+
+	if !entryProtocol.Unknown && entryProtocol.Value == loadBalancerProtocolHTTPS && certificateID.Null {
+		response.Diagnostics.AddAttributeError(
+			path.Root("certificate_id"),
+			"Missing Certificate",
+			"A certificate is required for load balancer pools with an https entry protocol. Please set the certificate_id attribute.",
+		)
+	}
+
+	if !healthCheckType.Unknown && !http.Null && !http.Unknown && !strings.HasPrefix(healthCheckType.Value, "http") {
+		response.Diagnostics.AddAttributeError(
+			path.Root("health_check").AtName("http"),
+			"Invalid Health Check",
+			fmt.Sprintf("The http health check options can only be used with an http health check type, but the type is %q.", healthCheckType.Value),
+		)
+	}
+}
+
+func (c computeLoadBalancerPoolResource) listProtocols(ctx context.Context) ([]loadBalancerEntity, error) {
+	list, err := c.loadBalancerEntityService.ListProtocols(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]loadBalancerEntity, len(list.Items))
+	for i, protocol := range list.Items {
+		entities[i] = loadBalancerEntity{ID: protocol.ID, Key: protocol.Key}
+	}
+
+	return entities, nil
+}
+
+func (c computeLoadBalancerPoolResource) listAlgorithms(ctx context.Context) ([]loadBalancerEntity, error) {
+	list, err := c.loadBalancerEntityService.ListAlgorithms(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]loadBalancerEntity, len(list.Items))
+	for i, algorithm := range list.Items {
+		entities[i] = loadBalancerEntity{ID: algorithm.ID, Key: algorithm.Key}
+	}
+
+	return entities, nil
+}
+
+func (c computeLoadBalancerPoolResource) listHealthCheckTypes(ctx context.Context) ([]loadBalancerEntity, error) {
+	list, err := c.loadBalancerEntityService.ListHealthCheckTypes(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]loadBalancerEntity, len(list.Items))
+	for i, healthCheckType := range list.Items {
+		entities[i] = loadBalancerEntity{ID: healthCheckType.ID, Key: healthCheckType.Key}
+	}
+
+	return entities, nil
+}
+
+// loadBalancerEntity is the common representation of load balancer protocols,
+// algorithms and health check types, which can be referenced by id or key.
+type loadBalancerEntity struct {
+	ID  int
+	Key string
+}
+
+type loadBalancerEntityFilter struct {
+	ID  types.Int64
+	Key types.String
+}
+
+func (l loadBalancerEntityFilter) AppliesTo(entity loadBalancerEntity) bool {
+	if !l.ID.Null && !l.ID.Unknown && int(l.ID.Value) != entity.ID {
+		return false
+	}
+
+	if !l.Key.Null && !l.Key.Unknown && l.Key.Value != entity.Key {
+		return false
+	}
+
+	return true
 }
 
 func convertHealthCheckConfigToAPIOptions(config computeLoadBalancerHealthCheckResourceData) (options compute.LoadBalancerHealthCheckOptions, diagnostics diag.Diagnostics) {
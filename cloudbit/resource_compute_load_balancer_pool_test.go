@@ -0,0 +1,252 @@
+package cloudbit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestComputeLoadBalancerPool_ModifyPlan(t *testing.T) {
+	ctx := context.Background()
+	_, prov := testFakeProvider(t)
+
+	resourceType := computeLoadBalancerPoolResourceType{}
+	res := testNewResource(t, resourceType, prov)
+
+	schema, _ := resourceType.GetSchema(ctx)
+	healthCheckType := schema.TerraformType(ctx).(tftypes.Object).AttributeTypes["health_check"].(tftypes.Object)
+	httpType := healthCheckType.AttributeTypes["http"].(tftypes.Object)
+
+	healthCheck := func(key string, http bool) tftypes.Value {
+		attributes := map[string]tftypes.Value{}
+		for name, attributeType := range healthCheckType.AttributeTypes {
+			attributes[name] = tftypes.NewValue(attributeType, nil)
+		}
+
+		attributes["type_id"] = tftypes.NewValue(tftypes.Number, tftypes.UnknownValue)
+		attributes["type"] = tftypes.NewValue(tftypes.String, key)
+
+		if http {
+			attributes["http"] = tftypes.NewValue(httpType, map[string]tftypes.Value{
+				"method": tftypes.NewValue(tftypes.String, "GET"),
+				"path":   tftypes.NewValue(tftypes.String, "/"),
+			})
+		}
+
+		return tftypes.NewValue(healthCheckType, attributes)
+	}
+
+	unknownNumber := tftypes.NewValue(tftypes.Number, tftypes.UnknownValue)
+	unknownString := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+
+	config := func(entryProtocol string, changes map[string]tftypes.Value) map[string]tftypes.Value {
+		values := map[string]tftypes.Value{
+			"load_balancer_id":       tftypes.NewValue(tftypes.Number, 1),
+			"entry_port":             tftypes.NewValue(tftypes.Number, 443),
+			"entry_protocol_id":      unknownNumber,
+			"entry_protocol":         tftypes.NewValue(tftypes.String, entryProtocol),
+			"target_protocol_id":     tftypes.NewValue(tftypes.Number, 3),
+			"target_protocol":        unknownString,
+			"balancing_algorithm_id": unknownNumber,
+			"balancing_algorithm":    tftypes.NewValue(tftypes.String, "least_connections"),
+			"health_check":           healthCheck("tcp", false),
+		}
+
+		for name, value := range changes {
+			values[name] = value
+		}
+
+		return values
+	}
+
+	t.Run("resolve keys", func(t *testing.T) {
+		response := testModifyPlan(t, res, resourceType, config("http", nil))
+		if response.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", response.Diagnostics)
+		}
+
+		ids := map[string]int64{"entry_protocol_id": 1, "balancing_algorithm_id": 2}
+		for name, expected := range ids {
+			var id types.Int64
+			response.Plan.GetAttribute(ctx, path.Root(name), &id)
+			if id.Unknown || id.Value != expected {
+				t.Errorf("expected %s to be %d, got %v", name, expected, id)
+			}
+		}
+
+		var targetProtocol types.String
+		response.Plan.GetAttribute(ctx, path.Root("target_protocol"), &targetProtocol)
+		if targetProtocol.Unknown || targetProtocol.Value != "tcp" {
+			t.Errorf("expected target_protocol to be tcp, got %v", targetProtocol)
+		}
+
+		var typeID types.Int64
+		response.Plan.GetAttribute(ctx, path.Root("health_check").AtName("type_id"), &typeID)
+		if typeID.Value != 3 {
+			t.Errorf("expected health_check.type_id to be 3, got %v", typeID)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		values  map[string]tftypes.Value
+		summary string
+		path    path.Path
+	}{
+		{
+			name:    "unknown protocol",
+			values:  config("udp", nil),
+			summary: "Not Found",
+			path:    path.Root("entry_protocol"),
+		},
+		{
+			name:    "unknown protocol id",
+			values:  config("http", map[string]tftypes.Value{"target_protocol_id": tftypes.NewValue(tftypes.Number, 99)}),
+			summary: "Not Found",
+			path:    path.Root("target_protocol_id"),
+		},
+		{
+			name:    "https without certificate",
+			values:  config(loadBalancerProtocolHTTPS, nil),
+			summary: "Missing Certificate",
+			path:    path.Root("certificate_id"),
+		},
+		{
+			name:   "https with certificate",
+			values: config(loadBalancerProtocolHTTPS, map[string]tftypes.Value{"certificate_id": tftypes.NewValue(tftypes.Number, 1)}),
+		},
+		{
+			name:    "http options of a tcp health check",
+			values:  config("http", map[string]tftypes.Value{"health_check": healthCheck("tcp", true)}),
+			summary: "Invalid Health Check",
+			path:    path.Root("health_check").AtName("http"),
+		},
+		{
+			name:   "http options of an http health check",
+			values: config("http", map[string]tftypes.Value{"health_check": healthCheck("http", true)}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := testModifyPlan(t, res, resourceType, test.values)
+
+			if test.summary == "" {
+				if response.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", response.Diagnostics)
+				}
+
+				return
+			}
+
+			if response.Diagnostics.ErrorsCount() != 1 || response.Diagnostics[0].Summary() != test.summary {
+				t.Fatalf("expected a %q error, got %v", test.summary, response.Diagnostics)
+			}
+
+			if withPath, ok := response.Diagnostics[0].(interface{ Path() path.Path }); !ok || !withPath.Path().Equal(test.path) {
+				t.Errorf("expected the error at %s, got %v", test.path, response.Diagnostics[0])
+			}
+		})
+	}
+
+	t.Run("replace on protocol change", func(t *testing.T) {
+		state := testResourceState(t, resourceType, map[string]tftypes.Value{
+			"id":                     tftypes.NewValue(tftypes.Number, 1),
+			"load_balancer_id":       tftypes.NewValue(tftypes.Number, 1),
+			"entry_port":             tftypes.NewValue(tftypes.Number, 443),
+			"entry_protocol_id":      tftypes.NewValue(tftypes.Number, 1),
+			"entry_protocol":         tftypes.NewValue(tftypes.String, "http"),
+			"target_protocol_id":     tftypes.NewValue(tftypes.Number, 3),
+			"target_protocol":        tftypes.NewValue(tftypes.String, "tcp"),
+			"balancing_algorithm_id": tftypes.NewValue(tftypes.Number, 1),
+			"balancing_algorithm":    tftypes.NewValue(tftypes.String, "round_robin"),
+		})
+
+		plan := testResourceState(t, resourceType, config("tcp", nil))
+
+		request := tfsdk.ModifyResourcePlanRequest{
+			Config: tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+			Plan:   tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw},
+			State:  state,
+		}
+
+		response := tfsdk.ModifyResourcePlanResponse{Plan: request.Plan}
+		res.(tfsdk.ResourceWithModifyPlan).ModifyPlan(ctx, request, &response)
+		if response.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", response.Diagnostics)
+		}
+
+		// only the entry protocol changed, the algorithm can be updated in place
+		if len(response.RequiresReplace) != 1 || !response.RequiresReplace[0].Equal(path.Root("entry_protocol_id")) {
+			t.Errorf("expected only entry_protocol_id to require a replacement, got %v", response.RequiresReplace)
+		}
+	})
+}
+
+func TestAccComputeLoadBalancerPool_Keys(t *testing.T) {
+	networkName := testAccRandomName(t, "test-network")
+	loadBalancerName := testAccRandomName(t, "test-load-balancer")
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccComputeLoadBalancerPoolConfigKeys, networkName, loadBalancerName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("cloudbit_compute_load_balancer_pool.foobar", "id"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_load_balancer_pool.foobar", "entry_protocol_id"),
+					resource.TestCheckResourceAttr("cloudbit_compute_load_balancer_pool.foobar", "entry_protocol", "http"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_load_balancer_pool.foobar", "target_protocol_id"),
+					resource.TestCheckResourceAttr("cloudbit_compute_load_balancer_pool.foobar", "target_protocol", "http"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_load_balancer_pool.foobar", "balancing_algorithm_id"),
+					resource.TestCheckResourceAttr("cloudbit_compute_load_balancer_pool.foobar", "balancing_algorithm", "round_robin"),
+					resource.TestCheckResourceAttrSet("cloudbit_compute_load_balancer_pool.foobar", "health_check.type_id"),
+					resource.TestCheckResourceAttr("cloudbit_compute_load_balancer_pool.foobar", "health_check.type", "http"),
+				),
+			},
+		},
+	})
+}
+
+const testAccComputeLoadBalancerPoolConfigKeys = `
+locals {
+	location_id = 1
+}
+
+resource "cloudbit_compute_network" "foobar" {
+	name        = "%s"
+	location_id = local.location_id
+
+	cidr = "192.168.1.0/24"
+}
+
+resource "cloudbit_compute_load_balancer" "foobar" {
+	name        = "%s"
+	location_id = local.location_id
+	network_id  = cloudbit_compute_network.foobar.id
+}
+
+resource "cloudbit_compute_load_balancer_pool" "foobar" {
+	load_balancer_id = cloudbit_compute_load_balancer.foobar.id
+
+	entry_protocol      = "http"
+	entry_port          = 80
+	target_protocol     = "http"
+	balancing_algorithm = "round_robin"
+
+	health_check = {
+		type = "http"
+
+		http = {
+			method = "GET"
+			path   = "/"
+		}
+	}
+}
+`
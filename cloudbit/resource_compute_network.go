@@ -1,20 +1,27 @@
 package cloudbit
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeNetworkResourceType)(nil)
-	_ tfsdk.Resource                = (*computeNetworkResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeNetworkResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeNetworkResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeNetworkResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeNetworkResource)(nil)
+	_ tfsdk.ResourceWithValidateConfig   = (*computeNetworkResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeNetworkResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeNetworkResource)(nil)
 )
 
 type computeNetworkResourceAllocationPool struct {
@@ -27,6 +34,7 @@ type computeNetworkResourceData struct {
 	Name              types.String                          `tfsdk:"name"`
 	CIDR              types.String                          `tfsdk:"cidr"`
 	LocationID        types.Int64                           `tfsdk:"location_id"`
+	Location          types.String                          `tfsdk:"location"`
 	DomainNameServers []types.String                        `tfsdk:"domain_name_servers"`
 	AllocationPool    *computeNetworkResourceAllocationPool `tfsdk:"allocation_pool"`
 	GatewayIP         types.String                          `tfsdk:"gateway_ip"`
@@ -37,6 +45,7 @@ func (c *computeNetworkResourceData) FromEntity(network compute.Network) {
 	c.Name = types.String{Value: network.Name}
 	c.CIDR = types.String{Value: network.CIDR}
 	c.LocationID = types.Int64{Value: int64(network.Location.ID)}
+	c.Location = types.String{Value: network.Location.Key}
 	c.GatewayIP = types.String{Value: network.GatewayIP}
 
 	c.AllocationPool = &computeNetworkResourceAllocationPool{
@@ -76,14 +85,8 @@ func (c computeNetworkResourceType) GetSchema(ctx context.Context) (tfsdk.Schema
 					tfsdk.RequiresReplace(),
 				},
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the location",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("unique identifier of the location"),
+			"location":    locationAttribute(),
 			"domain_name_servers": {
 				Type: types.ListType{
 					ElemType: types.StringType,
@@ -135,15 +138,22 @@ func (c computeNetworkResourceType) NewResource(ctx context.Context, p tfsdk.Pro
 	}
 
 	return computeNetworkResource{
+		locations: newLocationResolver(prov),
+
 		networkService: compute.NewNetworkService(prov.client),
 	}, diagnostics
 }
 
 type computeNetworkResource struct {
+	locations locationResolver
+
 	networkService compute.NetworkService
 }
 
 func (c computeNetworkResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeNetworkResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -151,6 +161,12 @@ func (c computeNetworkResource) Create(ctx context.Context, request tfsdk.Create
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := compute.NetworkCreate{
 		Name:       config.Name.Value,
 		LocationID: int(config.LocationID.Value),
@@ -175,7 +191,7 @@ func (c computeNetworkResource) Create(ctx context.Context, request tfsdk.Create
 
 	network, err := c.networkService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create network: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create network", err)
 		return
 	}
 
@@ -187,6 +203,9 @@ func (c computeNetworkResource) Create(ctx context.Context, request tfsdk.Create
 }
 
 func (c computeNetworkResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeNetworkResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -196,7 +215,7 @@ func (c computeNetworkResource) Read(ctx context.Context, request tfsdk.ReadReso
 
 	network, err := c.networkService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get network: %s", err))
+		addClientError(&response.Diagnostics, "unable to get network", err)
 		return
 	}
 
@@ -207,6 +226,9 @@ func (c computeNetworkResource) Read(ctx context.Context, request tfsdk.ReadReso
 }
 
 func (c computeNetworkResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeNetworkResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -238,9 +260,16 @@ func (c computeNetworkResource) Update(ctx context.Context, request tfsdk.Update
 		update.AllocationPoolEnd = config.AllocationPool.End.Value
 	}
 
-	network, err := c.networkService.Update(ctx, int(state.ID.Value), update)
+	_, err := c.networkService.Update(ctx, int(state.ID.Value), update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update network: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update network", err)
+		return
+	}
+
+	// the dhcp options are applied asynchronously, so the response of the update might not contain them yet
+	network, err := c.networkService.Get(ctx, int(state.ID.Value))
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to get network", err)
 		return
 	}
 
@@ -251,6 +280,9 @@ func (c computeNetworkResource) Update(ctx context.Context, request tfsdk.Update
 }
 
 func (c computeNetworkResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeNetworkResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -260,7 +292,7 @@ func (c computeNetworkResource) Delete(ctx context.Context, request tfsdk.Delete
 
 	err := c.networkService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete network: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete network", err)
 		return
 	}
 }
@@ -268,3 +300,104 @@ func (c computeNetworkResource) Delete(ctx context.Context, request tfsdk.Delete
 func (c computeNetworkResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (c computeNetworkResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	c.locations.ModifyPlan(ctx, request, response)
+}
+
+func (c computeNetworkResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
+
+func (c computeNetworkResource) ValidateConfig(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	var cidr, gatewayIP types.String
+	var domainNameServers types.List
+	var allocationPool types.Object
+
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, path.Root("cidr"), &cidr)...)
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, path.Root("gateway_ip"), &gatewayIP)...)
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, path.Root("domain_name_servers"), &domainNameServers)...)
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, path.Root("allocation_pool"), &allocationPool)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	for idx, elem := range domainNameServers.Elems {
+		domainNameServer, ok := elem.(types.String)
+		if !ok || domainNameServer.Null || domainNameServer.Unknown {
+			continue
+		}
+
+		if net.ParseIP(domainNameServer.Value) == nil {
+			response.Diagnostics.AddAttributeError(
+				path.Root("domain_name_servers").AtListIndex(idx),
+				"Invalid Domain Name Server",
+				fmt.Sprintf("The domain name server %q is not a valid IP address.", domainNameServer.Value),
+			)
+		}
+	}
+
+	var network *net.IPNet
+	if !cidr.Null && !cidr.Unknown {
+		var err error
+
+		_, network, err = net.ParseCIDR(cidr.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(path.Root("cidr"), "Invalid CIDR", fmt.Sprintf("The cidr %q is invalid: %s", cidr.Value, err))
+		}
+	}
+
+	gateway := validateNetworkAddress(response, path.Root("gateway_ip"), "Gateway IP", gatewayIP, network)
+
+	if allocationPool.Null || allocationPool.Unknown {
+		return
+	}
+
+	start, _ := allocationPool.Attrs["start"].(types.String)
+	end, _ := allocationPool.Attrs["end"].(types.String)
+
+	poolStart := validateNetworkAddress(response, path.Root("allocation_pool").AtName("start"), "Allocation Pool Start", start, network)
+	poolEnd := validateNetworkAddress(response, path.Root("allocation_pool").AtName("end"), "Allocation Pool End", end, network)
+	if poolStart == nil || poolEnd == nil {
+		return
+	}
+
+	if bytes.Compare(poolStart, poolEnd) > 0 {
+		response.Diagnostics.AddAttributeError(
+			path.Root("allocation_pool"),
+			"Invalid Allocation Pool",
+			fmt.Sprintf("The start %s of the allocation pool is after its end %s.", poolStart, poolEnd),
+		)
+		return
+	}
+
+	if gateway != nil && bytes.Compare(poolStart, gateway) <= 0 && bytes.Compare(gateway, poolEnd) <= 0 {
+		response.Diagnostics.AddAttributeError(
+			path.Root("gateway_ip"),
+			"Invalid Gateway IP",
+			fmt.Sprintf("The gateway ip %s must not be within the allocation pool %s - %s.", gateway, poolStart, poolEnd),
+		)
+	}
+}
+
+// validateNetworkAddress parses the address and checks that it is within the network, if the network is known.
+func validateNetworkAddress(response *tfsdk.ValidateResourceConfigResponse, attributePath path.Path, summary string, address types.String, network *net.IPNet) net.IP {
+	if address.Null || address.Unknown {
+		return nil
+	}
+
+	ip := net.ParseIP(address.Value)
+	if ip == nil {
+		response.Diagnostics.AddAttributeError(attributePath, "Invalid "+summary, fmt.Sprintf("The address %q is not a valid IP address.", address.Value))
+		return nil
+	}
+
+	if network != nil && !network.Contains(ip) {
+		response.Diagnostics.AddAttributeError(attributePath, "Invalid "+summary, fmt.Sprintf("The address %s is not within the cidr %s.", ip, network))
+		return nil
+	}
+
+	return ip
+}
@@ -14,8 +14,9 @@ import (
 )
 
 var (
-	_ tfsdk.ResourceType = (*computeNetworkInterfaceResourceType)(nil)
-	_ tfsdk.Resource     = (*computeNetworkInterfaceResource)(nil)
+	_ tfsdk.ResourceType             = (*computeNetworkInterfaceResourceType)(nil)
+	_ tfsdk.Resource                 = (*computeNetworkInterfaceResource)(nil)
+	_ tfsdk.ResourceWithUpgradeState = (*computeNetworkInterfaceResource)(nil)
 )
 
 type computeNetworkInterfaceResourceData struct {
@@ -54,6 +55,7 @@ type computeNetworkInterfaceResourceType struct{}
 
 func (c computeNetworkInterfaceResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
 	return tfsdk.Schema{
+		Version: 1,
 		Attributes: map[string]tfsdk.Attribute{
 			"id": {
 				Type:                types.Int64Type,
@@ -96,8 +98,8 @@ func (c computeNetworkInterfaceResourceType) GetSchema(ctx context.Context) (tfs
 			},
 
 			"security_group_ids": {
-				Type:                types.ListType{ElemType: types.Int64Type},
-				MarkdownDescription: "list of security group IDs to assign to the network interface",
+				Type:                types.SetType{ElemType: types.Int64Type},
+				MarkdownDescription: "set of security group IDs to assign to the network interface",
 				Optional:            true,
 				Computed:            true,
 			},
@@ -111,6 +113,34 @@ func (c computeNetworkInterfaceResourceType) GetSchema(ctx context.Context) (tfs
 	}, nil
 }
 
+// computeNetworkInterfaceResourceDataV0 is the data of computeNetworkInterfaceSchemaV0. Like the schema, it is frozen
+// and must not be changed together with computeNetworkInterfaceResourceData.
+type computeNetworkInterfaceResourceDataV0 struct {
+	ID        types.Int64 `tfsdk:"id"`
+	ServerID  types.Int64 `tfsdk:"server_id"`
+	NetworkID types.Int64 `tfsdk:"network_id"`
+
+	PrivateIP  types.String `tfsdk:"private_ip"`
+	MacAddress types.String `tfsdk:"mac_address"`
+
+	SecurityGroupIDs []types.Int64 `tfsdk:"security_group_ids"`
+	Security         types.Bool    `tfsdk:"security"`
+}
+
+// computeNetworkInterfaceSchemaV0 is the schema before security_group_ids was
+// changed from a list to a set.
+var computeNetworkInterfaceSchemaV0 = tfsdk.Schema{
+	Attributes: map[string]tfsdk.Attribute{
+		"id":                 {Type: types.Int64Type, Computed: true},
+		"server_id":          {Type: types.Int64Type, Required: true},
+		"network_id":         {Type: types.Int64Type, Required: true},
+		"private_ip":         {Type: types.StringType, Optional: true, Computed: true},
+		"mac_address":        {Type: types.StringType, Computed: true},
+		"security_group_ids": {Type: types.ListType{ElemType: types.Int64Type}, Optional: true, Computed: true},
+		"security":           {Type: types.BoolType, Optional: true, Computed: true},
+	},
+}
+
 func (c computeNetworkInterfaceResourceType) NewResource(ctx context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
 	prov, diagnostics := convertToLocalProviderType(p)
 	if diagnostics.HasError() {
@@ -127,6 +157,9 @@ type computeNetworkInterfaceResource struct {
 }
 
 func (c computeNetworkInterfaceResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network_interface", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeNetworkInterfaceResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -144,7 +177,7 @@ func (c computeNetworkInterfaceResource) Create(ctx context.Context, request tfs
 
 	iface, err := service.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create network interface: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create network interface", err)
 		return
 	}
 
@@ -163,7 +196,7 @@ func (c computeNetworkInterfaceResource) Create(ctx context.Context, request tfs
 			// TODO: should we add a backoff here if the deletion fails?
 			_ = service.Delete(ctx, iface.ID)
 
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update security groups: %s", err))
+			addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update security groups", err)
 			return
 		}
 	}
@@ -179,7 +212,7 @@ func (c computeNetworkInterfaceResource) Create(ctx context.Context, request tfs
 			// TODO: should we add a backoff here if the deletion fails?
 			_ = service.Delete(ctx, iface.ID)
 
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update network interface security: %s", err))
+			addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update network interface security", err)
 			return
 		}
 	}
@@ -192,6 +225,9 @@ func (c computeNetworkInterfaceResource) Create(ctx context.Context, request tfs
 }
 
 func (c computeNetworkInterfaceResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network_interface", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeNetworkInterfaceResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -203,7 +239,7 @@ func (c computeNetworkInterfaceResource) Read(ctx context.Context, request tfsdk
 
 	list, err := c.serverService.NetworkInterfaces(serverID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list network interfaces: %s", err))
+		addClientError(&response.Diagnostics, "unable to list network interfaces", err)
 		return
 	}
 
@@ -220,6 +256,9 @@ func (c computeNetworkInterfaceResource) Read(ctx context.Context, request tfsdk
 }
 
 func (c computeNetworkInterfaceResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network_interface", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeNetworkInterfaceResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -250,7 +289,7 @@ func (c computeNetworkInterfaceResource) Update(ctx context.Context, request tfs
 
 		iface, err := service.UpdateSecurityGroups(ctx, ifaceID, update)
 		if err != nil {
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update security groups: %s", err))
+			addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update security groups", err)
 			return
 		}
 
@@ -264,7 +303,7 @@ func (c computeNetworkInterfaceResource) Update(ctx context.Context, request tfs
 
 		iface, err := service.UpdateSecurity(ctx, ifaceID, update)
 		if err != nil {
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update network interface security: %s", err))
+			addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update network interface security", err)
 			return
 		}
 
@@ -276,6 +315,9 @@ func (c computeNetworkInterfaceResource) Update(ctx context.Context, request tfs
 }
 
 func (c computeNetworkInterfaceResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_network_interface", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeNetworkInterfaceResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -288,7 +330,24 @@ func (c computeNetworkInterfaceResource) Delete(ctx context.Context, request tfs
 
 	err := c.serverService.NetworkInterfaces(serverID).Delete(ctx, ifaceID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete network interface: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete network interface", err)
 		return
 	}
 }
+
+func (c computeNetworkInterfaceResource) UpgradeState(ctx context.Context) map[int64]tfsdk.ResourceStateUpgrader {
+	return map[int64]tfsdk.ResourceStateUpgrader{
+		// the values are unchanged, the security groups only have to be stored as a set instead of a list
+		0: newStateUpgrader(computeNetworkInterfaceSchemaV0, func(ctx context.Context, prior computeNetworkInterfaceResourceDataV0) (computeNetworkInterfaceResourceData, diag.Diagnostics) {
+			return computeNetworkInterfaceResourceData{
+				ID:               prior.ID,
+				ServerID:         prior.ServerID,
+				NetworkID:        prior.NetworkID,
+				PrivateIP:        prior.PrivateIP,
+				MacAddress:       prior.MacAddress,
+				SecurityGroupIDs: prior.SecurityGroupIDs,
+				Security:         prior.Security,
+			}, nil
+		}),
+	}
+}
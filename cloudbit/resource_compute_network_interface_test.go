@@ -0,0 +1,79 @@
+package cloudbit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestComputeNetworkInterfaceResource_UpgradeStateV0(t *testing.T) {
+	state := testUpgradeState(t, computeNetworkInterfaceResourceType{}, 0, `{
+		"id": 3,
+		"server_id": 1,
+		"network_id": 2,
+		"private_ip": "172.31.0.10",
+		"mac_address": "fa:16:3e:00:00:03",
+		"security_group_ids": [5, 4],
+		"security": true
+	}`)
+
+	var data computeNetworkInterfaceResourceData
+	diagnostics := state.Get(context.Background(), &data)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to decode upgraded state: %v", diagnostics)
+	}
+
+	expected := computeNetworkInterfaceResourceData{
+		ID:               types.Int64{Value: 3},
+		ServerID:         types.Int64{Value: 1},
+		NetworkID:        types.Int64{Value: 2},
+		PrivateIP:        types.String{Value: "172.31.0.10"},
+		MacAddress:       types.String{Value: "fa:16:3e:00:00:03"},
+		SecurityGroupIDs: []types.Int64{{Value: 5}, {Value: 4}},
+		Security:         types.Bool{Value: true},
+	}
+
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected upgraded state %+v, got %+v", expected, data)
+	}
+
+	var securityGroupIDs types.Set
+	diagnostics = state.GetAttribute(context.Background(), path.Root("security_group_ids"), &securityGroupIDs)
+	if diagnostics.HasError() {
+		t.Errorf("expected security_group_ids to be stored as a set: %v", diagnostics)
+	}
+}
+
+func TestComputeNetworkInterfaceResource_UpgradeStateV0NullAttributes(t *testing.T) {
+	state := testUpgradeState(t, computeNetworkInterfaceResourceType{}, 0, `{
+		"id": 3,
+		"server_id": 1,
+		"network_id": 2,
+		"private_ip": null,
+		"mac_address": null,
+		"security_group_ids": null,
+		"security": null
+	}`)
+
+	var data computeNetworkInterfaceResourceData
+	diagnostics := state.Get(context.Background(), &data)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to decode upgraded state: %v", diagnostics)
+	}
+
+	expected := computeNetworkInterfaceResourceData{
+		ID:         types.Int64{Value: 3},
+		ServerID:   types.Int64{Value: 1},
+		NetworkID:  types.Int64{Value: 2},
+		PrivateIP:  types.String{Null: true},
+		MacAddress: types.String{Null: true},
+		Security:   types.Bool{Null: true},
+	}
+
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected upgraded state %+v, got %+v", expected, data)
+	}
+}
@@ -2,18 +2,19 @@ package cloudbit
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccComputeNetwork_Basic(t *testing.T) {
-	networkName := acctest.RandomWithPrefix("test-network")
+	networkName := testAccRandomName(t, "test-network")
 	networkCIDR := "192.168.1.0/24"
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeNetworkConfigBasic, networkName, networkCIDR),
@@ -33,6 +34,55 @@ func TestAccComputeNetwork_Basic(t *testing.T) {
 	})
 }
 
+func TestAccComputeNetwork_InPlaceUpdate(t *testing.T) {
+	networkName := testAccRandomName(t, "test-network")
+	var networkID string
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testAccComputeNetworkConfigOptions, networkName, "192.168.1.50", "192.168.1.100", "192.168.2.1", "1.1.1.1"),
+				ExpectError: regexp.MustCompile("not within the cidr"),
+			},
+			{
+				Config: fmt.Sprintf(testAccComputeNetworkConfigOptions, networkName, "192.168.1.50", "192.168.1.100", "192.168.1.1", "1.1.1.1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID("cloudbit_compute_network.foobar", &networkID, false),
+					resource.TestCheckResourceAttr("cloudbit_compute_network.foobar", "allocation_pool.start", "192.168.1.50"),
+					resource.TestCheckResourceAttr("cloudbit_compute_network.foobar", "domain_name_servers.0", "1.1.1.1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccComputeNetworkConfigOptions, networkName, "192.168.1.20", "192.168.1.200", "192.168.1.1", "9.9.9.9"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID("cloudbit_compute_network.foobar", &networkID, true),
+					resource.TestCheckResourceAttr("cloudbit_compute_network.foobar", "allocation_pool.start", "192.168.1.20"),
+					resource.TestCheckResourceAttr("cloudbit_compute_network.foobar", "allocation_pool.end", "192.168.1.200"),
+					resource.TestCheckResourceAttr("cloudbit_compute_network.foobar", "domain_name_servers.0", "9.9.9.9"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckResourceID stores the id of the resource or, if same is set, verifies that it was not replaced.
+func testAccCheckResourceID(name string, id *string, same bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+
+		if same && rs.Primary.ID != *id {
+			return fmt.Errorf("resource %s was replaced: id changed from %s to %s", name, *id, rs.Primary.ID)
+		}
+
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
 const testAccComputeNetworkConfigBasic = `
 resource "cloudbit_compute_network" "foobar" {
 	name        = "%s"
@@ -40,3 +90,19 @@ resource "cloudbit_compute_network" "foobar" {
 	location_id = 1
 }
 `
+
+const testAccComputeNetworkConfigOptions = `
+resource "cloudbit_compute_network" "foobar" {
+	name        = "%s"
+	cidr        = "192.168.1.0/24"
+	location_id = 1
+
+	allocation_pool = {
+		start = "%s"
+		end   = "%s"
+	}
+
+	gateway_ip          = "%s"
+	domain_name_servers = ["%s"]
+}
+`
@@ -2,33 +2,42 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeRouterResourceType)(nil)
-	_ tfsdk.Resource                = (*computeRouterResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeRouterResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeRouterResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeRouterResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeRouterResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeRouterResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeRouterResource)(nil)
 )
 
 type computeRouterResourceData struct {
 	ID         types.Int64  `tfsdk:"id"`
 	Name       types.String `tfsdk:"name"`
 	LocationID types.Int64  `tfsdk:"location_id"`
+	Location   types.String `tfsdk:"location"`
 	Public     types.Bool   `tfsdk:"public"`
 	PublicIP   types.String `tfsdk:"public_ip"`
+
+	Interfaces []computeRouterInterfaceData `tfsdk:"interfaces"`
+	Routes     []computeRouterRouteData     `tfsdk:"routes"`
 }
 
-func (c *computeRouterResourceData) FromEntity(router compute.Router) {
+func (c *computeRouterResourceData) FromEntity(router compute.Router, interfaces []compute.RouterInterface, routes []compute.Route) {
 	c.ID = types.Int64{Value: int64(router.ID)}
 	c.Name = types.String{Value: router.Name}
 	c.LocationID = types.Int64{Value: int64(router.Location.ID)}
+	c.Location = types.String{Value: router.Location.Key}
 	c.Public = types.Bool{Value: router.Public}
 
 	if router.Public {
@@ -36,6 +45,40 @@ func (c *computeRouterResourceData) FromEntity(router compute.Router) {
 	} else {
 		c.PublicIP = types.String{Null: true}
 	}
+
+	c.Interfaces = make([]computeRouterInterfaceData, len(interfaces))
+	for i, routerInterface := range interfaces {
+		c.Interfaces[i].FromEntity(routerInterface)
+	}
+
+	c.Routes = make([]computeRouterRouteData, len(routes))
+	for i, route := range routes {
+		c.Routes[i].FromEntity(route)
+	}
+}
+
+type computeRouterInterfaceData struct {
+	ID        types.Int64  `tfsdk:"id"`
+	NetworkID types.Int64  `tfsdk:"network_id"`
+	PrivateIP types.String `tfsdk:"private_ip"`
+}
+
+func (c *computeRouterInterfaceData) FromEntity(routerInterface compute.RouterInterface) {
+	c.ID = types.Int64{Value: int64(routerInterface.ID)}
+	c.NetworkID = types.Int64{Value: int64(routerInterface.Network.ID)}
+	c.PrivateIP = types.String{Value: routerInterface.PrivateIP}
+}
+
+type computeRouterRouteData struct {
+	ID          types.Int64  `tfsdk:"id"`
+	Destination types.String `tfsdk:"destination"`
+	NextHop     types.String `tfsdk:"next_hop"`
+}
+
+func (c *computeRouterRouteData) FromEntity(route compute.Route) {
+	c.ID = types.Int64{Value: int64(route.ID)}
+	c.Destination = types.String{Value: route.Destination}
+	c.NextHop = types.String{Value: route.NextHop}
 }
 
 type computeRouterResourceType struct{}
@@ -56,14 +99,8 @@ func (c computeRouterResourceType) GetSchema(ctx context.Context) (tfsdk.Schema,
 				MarkdownDescription: "name of the router",
 				Required:            true,
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the location",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("unique identifier of the location"),
+			"location":    locationAttribute(),
 			"public": {
 				Type:                types.BoolType,
 				MarkdownDescription: "if the router should be public",
@@ -81,10 +118,66 @@ func (c computeRouterResourceType) GetSchema(ctx context.Context) (tfsdk.Schema,
 					tfsdk.UseStateForUnknown(),
 				},
 			},
+			"interfaces": {
+				MarkdownDescription: "interfaces attached to the router",
+				Computed:            true,
+				Attributes:          tfsdk.ListNestedAttributes(computeRouterInterfaceAttributes()),
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"routes": {
+				MarkdownDescription: "static routes of the router",
+				Computed:            true,
+				Attributes:          tfsdk.ListNestedAttributes(computeRouterRouteAttributes()),
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
 		},
 	}, nil
 }
 
+func computeRouterInterfaceAttributes() map[string]tfsdk.Attribute {
+	return map[string]tfsdk.Attribute{
+		"id": {
+			Type:                types.Int64Type,
+			MarkdownDescription: "unique identifier of the router interface",
+			Computed:            true,
+		},
+		"network_id": {
+			Type:                types.Int64Type,
+			MarkdownDescription: "unique identifier of the network",
+			Computed:            true,
+		},
+		"private_ip": {
+			Type:                types.StringType,
+			MarkdownDescription: "private IP address of the router interface",
+			Computed:            true,
+		},
+	}
+}
+
+func computeRouterRouteAttributes() map[string]tfsdk.Attribute {
+	return map[string]tfsdk.Attribute{
+		"id": {
+			Type:                types.Int64Type,
+			MarkdownDescription: "unique identifier of the route",
+			Computed:            true,
+		},
+		"destination": {
+			Type:                types.StringType,
+			MarkdownDescription: "IP destination range of the route",
+			Computed:            true,
+		},
+		"next_hop": {
+			Type:                types.StringType,
+			MarkdownDescription: "IP address of the next hop",
+			Computed:            true,
+		},
+	}
+}
+
 func (c computeRouterResourceType) NewResource(ctx context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
 	prov, diagnostics := convertToLocalProviderType(p)
 	if diagnostics.HasError() {
@@ -92,15 +185,22 @@ func (c computeRouterResourceType) NewResource(ctx context.Context, p tfsdk.Prov
 	}
 
 	return computeRouterResource{
+		locations: newLocationResolver(prov),
+
 		routerService: compute.NewRouterService(prov.client),
 	}, diagnostics
 }
 
 type computeRouterResource struct {
+	locations locationResolver
+
 	routerService compute.RouterService
 }
 
 func (c computeRouterResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeRouterResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -108,6 +208,12 @@ func (c computeRouterResource) Create(ctx context.Context, request tfsdk.CreateR
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := compute.RouterCreate{
 		Name:       config.Name.Value,
 		LocationID: int(config.LocationID.Value),
@@ -120,18 +226,27 @@ func (c computeRouterResource) Create(ctx context.Context, request tfsdk.CreateR
 
 	router, err := c.routerService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create router: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create router", err)
+		return
+	}
+
+	interfaces, routes, diagnostics := listComputeRouterNetworking(ctx, c.routerService, router.ID)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
 		return
 	}
 
 	var state computeRouterResourceData
-	state.FromEntity(router)
+	state.FromEntity(router, interfaces, routes)
 
 	diagnostics = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diagnostics...)
 }
 
 func (c computeRouterResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeRouterResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -141,17 +256,26 @@ func (c computeRouterResource) Read(ctx context.Context, request tfsdk.ReadResou
 
 	router, err := c.routerService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get router: %s", err))
+		addClientError(&response.Diagnostics, "unable to get router", err)
+		return
+	}
+
+	interfaces, routes, diagnostics := listComputeRouterNetworking(ctx, c.routerService, router.ID)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
 		return
 	}
 
-	state.FromEntity(router)
+	state.FromEntity(router, interfaces, routes)
 
 	diagnostics = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diagnostics...)
 }
 
 func (c computeRouterResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeRouterResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -173,17 +297,26 @@ func (c computeRouterResource) Update(ctx context.Context, request tfsdk.UpdateR
 
 	router, err := c.routerService.Update(ctx, int(state.ID.Value), update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update router: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update router", err)
+		return
+	}
+
+	interfaces, routes, diagnostics := listComputeRouterNetworking(ctx, c.routerService, router.ID)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
 		return
 	}
 
-	state.FromEntity(router)
+	state.FromEntity(router, interfaces, routes)
 
 	diagnostics = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diagnostics...)
 }
 
 func (c computeRouterResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeRouterResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -193,7 +326,7 @@ func (c computeRouterResource) Delete(ctx context.Context, request tfsdk.DeleteR
 
 	err := c.routerService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete router: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete router", err)
 		return
 	}
 }
@@ -201,3 +334,29 @@ func (c computeRouterResource) Delete(ctx context.Context, request tfsdk.DeleteR
 func (c computeRouterResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (c computeRouterResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	c.locations.ModifyPlan(ctx, request, response)
+}
+
+func (c computeRouterResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
+
+func listComputeRouterNetworking(ctx context.Context, routerService compute.RouterService, routerID int) (interfaces []compute.RouterInterface, routes []compute.Route, diagnostics diag.Diagnostics) {
+	interfaceList, err := routerService.RouterInterfaces(routerID).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&diagnostics, "unable to list router interfaces", err)
+		return
+	}
+
+	routeList, err := routerService.Routes(routerID).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&diagnostics, "unable to list routes", err)
+		return
+	}
+
+	return interfaceList.Items, routeList.Items, diagnostics
+}
@@ -3,17 +3,21 @@ package cloudbit
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var (
-	_ tfsdk.ResourceType = (*computeRouterInterfaceResourceType)(nil)
-	_ tfsdk.Resource     = (*computeRouterInterfaceResource)(nil)
+	_ tfsdk.ResourceType               = (*computeRouterInterfaceResourceType)(nil)
+	_ tfsdk.Resource                   = (*computeRouterInterfaceResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan     = (*computeRouterInterfaceResource)(nil)
+	_ tfsdk.ResourceWithValidateConfig = (*computeRouterInterfaceResource)(nil)
 )
 
 type computeRouterInterfaceResourceData struct {
@@ -61,11 +65,12 @@ func (c computeRouterInterfaceResourceType) GetSchema(ctx context.Context) (tfsd
 			},
 			"private_ip": {
 				Type:                types.StringType,
-				MarkdownDescription: "private IP address of the router interface",
+				MarkdownDescription: "private IP address of the router interface. Must be within the CIDR of the network, a free address is chosen if omitted",
 				Optional:            true,
 				Computed:            true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.RequiresReplace(),
+					tfsdk.UseStateForUnknown(),
 				},
 			},
 		},
@@ -88,6 +93,9 @@ type computeRouterInterfaceResource struct {
 }
 
 func (c computeRouterInterfaceResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_interface", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeRouterInterfaceResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -103,7 +111,7 @@ func (c computeRouterInterfaceResource) Create(ctx context.Context, request tfsd
 
 	routerInterface, err := compute.NewRouterInterfaceService(c.client, routerID).Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create router interface: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create router interface", err)
 		return
 	}
 
@@ -115,6 +123,9 @@ func (c computeRouterInterfaceResource) Create(ctx context.Context, request tfsd
 }
 
 func (c computeRouterInterfaceResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_interface", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeRouterInterfaceResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -125,7 +136,7 @@ func (c computeRouterInterfaceResource) Read(ctx context.Context, request tfsdk.
 	routerID := int(state.RouterID.Value)
 	list, err := compute.NewRouterInterfaceService(c.client, routerID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list router interfaces: %s", err))
+		addClientError(&response.Diagnostics, "unable to list router interfaces", err)
 		return
 	}
 
@@ -143,10 +154,16 @@ func (c computeRouterInterfaceResource) Read(ctx context.Context, request tfsdk.
 }
 
 func (c computeRouterInterfaceResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_interface", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	response.Diagnostics.AddError("Not Supported", "updating a router interface is not supported")
 }
 
 func (c computeRouterInterfaceResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_interface", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeRouterInterfaceResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -157,7 +174,71 @@ func (c computeRouterInterfaceResource) Delete(ctx context.Context, request tfsd
 	routerID := int(state.RouterID.Value)
 	err := compute.NewRouterInterfaceService(c.client, routerID).Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete router interface: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete router interface", err)
+		return
+	}
+}
+
+func (c computeRouterInterfaceResource) ValidateConfig(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	var privateIP types.String
+	diagnostics := request.Config.GetAttribute(ctx, path.Root("private_ip"), &privateIP)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() || privateIP.Null || privateIP.Unknown {
+		return
+	}
+
+	if net.ParseIP(privateIP.Value) == nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("private_ip"),
+			"Invalid Private IP",
+			fmt.Sprintf("The private ip %q is not a valid IP address.", privateIP.Value),
+		)
+	}
+}
+
+func (c computeRouterInterfaceResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan computeRouterInterfaceResourceData
+	diagnostics := request.Plan.Get(ctx, &plan)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.PrivateIP.Null || plan.PrivateIP.Unknown || plan.NetworkID.Unknown {
+		return
+	}
+
+	// existing interfaces were already checked when they were planned
+	if !request.State.Raw.IsNull() {
+		var state computeRouterInterfaceResourceData
+		diagnostics = request.State.Get(ctx, &state)
+		response.Diagnostics.Append(diagnostics...)
+		if response.Diagnostics.HasError() || state.PrivateIP.Equal(plan.PrivateIP) && state.NetworkID.Equal(plan.NetworkID) {
+			return
+		}
+	}
+
+	network, err := compute.NewNetworkService(c.client).Get(ctx, int(plan.NetworkID.Value))
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to get network", err)
 		return
 	}
+
+	_, cidr, err := net.ParseCIDR(network.CIDR)
+	if err != nil {
+		addClientError(&response.Diagnostics, fmt.Sprintf("unable to parse network cidr %q", network.CIDR), err)
+		return
+	}
+
+	if ip := net.ParseIP(plan.PrivateIP.Value); ip != nil && !cidr.Contains(ip) {
+		response.Diagnostics.AddAttributeError(
+			path.Root("private_ip"),
+			"Invalid Private IP",
+			fmt.Sprintf("The private ip %s is not within the cidr %s of network %q.", plan.PrivateIP.Value, network.CIDR, network.Name),
+		)
+	}
 }
@@ -2,19 +2,19 @@ package cloudbit
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestAccComputeRouterInterface_Basic(t *testing.T) {
-	networkName := acctest.RandomWithPrefix("test-network")
+	networkName := testAccRandomName(t, "test-network")
 	networkCIDR := "192.168.1.0/24"
-	routerName := acctest.RandomWithPrefix("test-router")
+	routerName := testAccRandomName(t, "test-router")
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeRouterInterfaceConfigBasic, networkName, networkCIDR, routerName),
@@ -29,6 +29,32 @@ func TestAccComputeRouterInterface_Basic(t *testing.T) {
 	})
 }
 
+func TestAccComputeRouterInterface_PrivateIP(t *testing.T) {
+	networkName := testAccRandomName(t, "test-network")
+	networkCIDR := "192.168.1.0/24"
+	routerName := testAccRandomName(t, "test-router")
+	privateIP := "192.168.1.1"
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testAccComputeRouterInterfaceConfigPrivateIP, networkName, networkCIDR, routerName, "10.0.0.1"),
+				ExpectError: regexp.MustCompile("not within the cidr"),
+			},
+			{
+				Config: fmt.Sprintf(testAccComputeRouterInterfaceConfigPrivateIP, networkName, networkCIDR, routerName, privateIP),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudbit_compute_router_interface.foobar", "private_ip", privateIP),
+					resource.TestCheckResourceAttr("data.cloudbit_compute_router.foobar", "interfaces.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudbit_compute_router.foobar", "interfaces.0.private_ip", privateIP),
+					resource.TestCheckResourceAttr("data.cloudbit_compute_router.foobar", "routes.#", "0"),
+				),
+			},
+		},
+	})
+}
+
 const testAccComputeRouterInterfaceConfigBasic = `
 locals {
 	location_id = 1
@@ -53,3 +79,35 @@ resource "cloudbit_compute_router_interface" "foobar" {
 	network_id = cloudbit_compute_network.foobar.id
 }
 `
+
+const testAccComputeRouterInterfaceConfigPrivateIP = `
+locals {
+	location_id = 1
+}
+
+resource "cloudbit_compute_network" "foobar" {
+	name        = "%s"
+	location_id = local.location_id
+
+	cidr = "%s"
+}
+
+resource "cloudbit_compute_router" "foobar" {
+	name        = "%s"
+	location_id = local.location_id
+
+	public = false
+}
+
+resource "cloudbit_compute_router_interface" "foobar" {
+	router_id  = cloudbit_compute_router.foobar.id
+	network_id = cloudbit_compute_network.foobar.id
+	private_ip = "%s"
+}
+
+data "cloudbit_compute_router" "foobar" {
+	id = cloudbit_compute_router.foobar.id
+
+	depends_on = [cloudbit_compute_router_interface.foobar]
+}
+`
@@ -34,6 +34,7 @@ type computeRouterRouteResourceType struct{}
 
 func (c computeRouterRouteResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
 	return tfsdk.Schema{
+		MarkdownDescription: "Manages a single static route of a router. This resource cannot be used together with `cloudbit_compute_router_route_table` on the same router, as the route table removes every route which it does not list.",
 		Attributes: map[string]tfsdk.Attribute{
 			"id": {
 				Type:                types.Int64Type,
@@ -87,6 +88,9 @@ type computeRouterRouteResource struct {
 }
 
 func (c computeRouterRouteResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeRouterRouteResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -102,7 +106,7 @@ func (c computeRouterRouteResource) Create(ctx context.Context, request tfsdk.Cr
 
 	route, err := compute.NewRouteService(c.client, routerID).Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create route: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create route", err)
 		return
 	}
 
@@ -114,6 +118,9 @@ func (c computeRouterRouteResource) Create(ctx context.Context, request tfsdk.Cr
 }
 
 func (c computeRouterRouteResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeRouterRouteResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -125,7 +132,7 @@ func (c computeRouterRouteResource) Read(ctx context.Context, request tfsdk.Read
 
 	list, err := compute.NewRouteService(c.client, routerID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list routes: %s", err))
+		addClientError(&response.Diagnostics, "unable to list routes", err)
 		return
 	}
 
@@ -143,10 +150,16 @@ func (c computeRouterRouteResource) Read(ctx context.Context, request tfsdk.Read
 }
 
 func (c computeRouterRouteResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	response.Diagnostics.AddError("Not Supported", "updating a route is not supported")
 }
 
 func (c computeRouterRouteResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeRouterRouteResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -157,7 +170,7 @@ func (c computeRouterRouteResource) Delete(ctx context.Context, request tfsdk.De
 	routerID := int(state.RouterID.Value)
 	err := compute.NewRouteService(c.client, routerID).Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete route: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete route", err)
 		return
 	}
 }
@@ -0,0 +1,405 @@
+package cloudbit
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/filter"
+)
+
+var (
+	_ tfsdk.ResourceType               = (*computeRouterRouteTableResourceType)(nil)
+	_ tfsdk.Resource                   = (*computeRouterRouteTableResource)(nil)
+	_ tfsdk.ResourceWithImportState    = (*computeRouterRouteTableResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan     = (*computeRouterRouteTableResource)(nil)
+	_ tfsdk.ResourceWithValidateConfig = (*computeRouterRouteTableResource)(nil)
+)
+
+type computeRouterRouteTableResourceData struct {
+	ID       types.Int64                        `tfsdk:"id"`
+	RouterID types.Int64                        `tfsdk:"router_id"`
+	Routes   []computeRouterRouteTableRouteData `tfsdk:"routes"`
+}
+
+func (c *computeRouterRouteTableResourceData) FromEntity(routerID int, routes []compute.Route) {
+	c.ID = types.Int64{Value: int64(routerID)}
+	c.RouterID = types.Int64{Value: int64(routerID)}
+
+	c.Routes = make([]computeRouterRouteTableRouteData, len(routes))
+	for i, route := range routes {
+		c.Routes[i].FromEntity(route)
+	}
+}
+
+type computeRouterRouteTableRouteData struct {
+	Destination types.String `tfsdk:"destination"`
+	NextHop     types.String `tfsdk:"next_hop"`
+}
+
+func (c *computeRouterRouteTableRouteData) FromEntity(route compute.Route) {
+	c.Destination = types.String{Value: route.Destination}
+	c.NextHop = types.String{Value: route.NextHop}
+}
+
+func (c computeRouterRouteTableRouteData) AppliesTo(route compute.Route) bool {
+	return route.Destination == c.Destination.Value && route.NextHop == c.NextHop.Value
+}
+
+type computeRouterRouteTableResourceType struct{}
+
+func (c computeRouterRouteTableResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		MarkdownDescription: "Manages all static routes of a router. This resource cannot be used together with `cloudbit_compute_router_route` on the same router, as it removes every route which is not listed in `routes`.",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "unique identifier of the route table, which is the same as the unique identifier of the router",
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"router_id": {
+				Type:                types.Int64Type,
+				MarkdownDescription: "unique identifier of the router",
+				Required:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"routes": {
+				MarkdownDescription: "all static routes of the router. Routes which are not listed are removed from the router, including routes managed by `cloudbit_compute_router_route`. The next hop of each route must be within a network attached to the router",
+				Required:            true,
+				Attributes: tfsdk.SetNestedAttributes(map[string]tfsdk.Attribute{
+					"destination": {
+						Type:                types.StringType,
+						MarkdownDescription: "IP destination range of the route",
+						Required:            true,
+					},
+					"next_hop": {
+						Type:                types.StringType,
+						MarkdownDescription: "IP address of the next hop",
+						Required:            true,
+					},
+				}),
+			},
+		},
+	}, nil
+}
+
+func (c computeRouterRouteTableResourceType) NewResource(ctx context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	prov, diagnostics := convertToLocalProviderType(p)
+	if diagnostics.HasError() {
+		return nil, diagnostics
+	}
+
+	return computeRouterRouteTableResource{
+		client: prov.client,
+	}, diagnostics
+}
+
+type computeRouterRouteTableResource struct {
+	client goclient.Client
+}
+
+func (c computeRouterRouteTableResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route_table", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
+	var config computeRouterRouteTableResourceData
+	diagnostics := request.Config.Get(ctx, &config)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	routerID := int(config.RouterID.Value)
+
+	routes, diagnostics := c.applyRoutes(ctx, routerID, config.Routes)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	var state computeRouterRouteTableResourceData
+	state.FromEntity(routerID, routes)
+
+	diagnostics = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diagnostics...)
+}
+
+func (c computeRouterRouteTableResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route_table", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
+	var state computeRouterRouteTableResourceData
+	diagnostics := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	routerID := int(state.RouterID.Value)
+
+	list, err := compute.NewRouteService(c.client, routerID).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to list routes", err)
+		return
+	}
+
+	state.FromEntity(routerID, list.Items)
+
+	diagnostics = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diagnostics...)
+}
+
+func (c computeRouterRouteTableResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route_table", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
+	var config computeRouterRouteTableResourceData
+	diagnostics := request.Config.Get(ctx, &config)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	routerID := int(config.RouterID.Value)
+
+	routes, diagnostics := c.applyRoutes(ctx, routerID, config.Routes)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	var state computeRouterRouteTableResourceData
+	state.FromEntity(routerID, routes)
+
+	diagnostics = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diagnostics...)
+}
+
+func (c computeRouterRouteTableResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_router_route_table", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
+	var state computeRouterRouteTableResourceData
+	diagnostics := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	_, diagnostics = c.applyRoutes(ctx, int(state.RouterID.Value), nil)
+	response.Diagnostics.Append(diagnostics...)
+}
+
+func (c computeRouterRouteTableResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
+	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("router_id"), request, response)
+}
+
+func (c computeRouterRouteTableResource) ValidateConfig(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	var routes types.Set
+	diagnostics := request.Config.GetAttribute(ctx, path.Root("routes"), &routes)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() || routes.Null || routes.Unknown {
+		return
+	}
+
+	for _, elem := range routes.Elems {
+		route, ok := elem.(types.Object)
+		if !ok || route.Null || route.Unknown {
+			continue
+		}
+
+		routePath := path.Root("routes").AtSetValue(route)
+
+		if destination, ok := route.Attrs["destination"].(types.String); ok && !destination.Null && !destination.Unknown {
+			if _, _, err := net.ParseCIDR(destination.Value); err != nil {
+				response.Diagnostics.AddAttributeError(
+					routePath.AtName("destination"),
+					"Invalid Destination",
+					fmt.Sprintf("The destination %q is not a valid CIDR: %s", destination.Value, err),
+				)
+			}
+		}
+
+		if nextHop, ok := route.Attrs["next_hop"].(types.String); ok && !nextHop.Null && !nextHop.Unknown {
+			if net.ParseIP(nextHop.Value) == nil {
+				response.Diagnostics.AddAttributeError(
+					routePath.AtName("next_hop"),
+					"Invalid Next Hop",
+					fmt.Sprintf("The next hop %q is not a valid IP address.", nextHop.Value),
+				)
+			}
+		}
+	}
+}
+
+func (c computeRouterRouteTableResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	var routerID types.Int64
+	diagnostics := request.Plan.GetAttribute(ctx, path.Root("router_id"), &routerID)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() || routerID.Null || routerID.Unknown {
+		return
+	}
+
+	var routes types.Set
+	diagnostics = request.Plan.GetAttribute(ctx, path.Root("routes"), &routes)
+	response.Diagnostics.Append(diagnostics...)
+	if response.Diagnostics.HasError() || routes.Null || routes.Unknown || len(routes.Elems) == 0 {
+		return
+	}
+
+	// existing routes were already checked when they were planned
+	if !request.State.Raw.IsNull() {
+		var stateRoutes types.Set
+		diagnostics = request.State.GetAttribute(ctx, path.Root("routes"), &stateRoutes)
+		response.Diagnostics.Append(diagnostics...)
+		if response.Diagnostics.HasError() || stateRoutes.Equal(routes) {
+			return
+		}
+	}
+
+	interfaces, err := compute.NewRouterInterfaceService(c.client, int(routerID.Value)).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to list router interfaces", err)
+		return
+	}
+
+	for _, elem := range routes.Elems {
+		route, ok := elem.(types.Object)
+		if !ok || route.Null || route.Unknown {
+			continue
+		}
+
+		nextHop, ok := route.Attrs["next_hop"].(types.String)
+		if !ok || nextHop.Null || nextHop.Unknown || routeNextHopAttached(nextHop.Value, interfaces.Items) {
+			continue
+		}
+
+		// the interface may still be attached during the same apply, which is why this is only a warning here
+		response.Diagnostics.AddAttributeWarning(
+			path.Root("routes").AtSetValue(route).AtName("next_hop"),
+			"Next Hop Not Attached",
+			fmt.Sprintf("The next hop %s is not within any network currently attached to router %d. Applying the route table will fail, unless the network is attached using cloudbit_compute_router_interface in the same apply.", nextHop.Value, routerID.Value),
+		)
+	}
+}
+
+// applyRoutes makes the desired routes the only routes of the router. The next hops are validated against the
+// networks attached to the router beforehand, so that a failing route does not leave the router half configured.
+func (c computeRouterRouteTableResource) applyRoutes(ctx context.Context, routerID int, desired []computeRouterRouteTableRouteData) (routes []compute.Route, diagnostics diag.Diagnostics) {
+	routeService := compute.NewRouteService(c.client, routerID)
+
+	if len(desired) != 0 {
+		interfaces, err := compute.NewRouterInterfaceService(c.client, routerID).List(ctx, goclient.Cursor{NoFilter: 1})
+		if err != nil {
+			addClientError(&diagnostics, "unable to list router interfaces", err)
+			return
+		}
+
+		for _, route := range desired {
+			diagnostics.Append(validateRouteNextHop(routerID, route.NextHop.Value, interfaces.Items)...)
+		}
+
+		if diagnostics.HasError() {
+			return
+		}
+	}
+
+	list, err := routeService.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		addClientError(&diagnostics, "unable to list routes", err)
+		return
+	}
+
+	// stale routes are removed first, as the destination of a changed route is most likely still in use
+	for _, route := range list.Items {
+		if containsRoute(desired, route) {
+			routes = append(routes, route)
+			continue
+		}
+
+		err = routeService.Delete(ctx, route.ID)
+		if err != nil {
+			addClientError(&diagnostics, fmt.Sprintf("unable to delete route to %s", route.Destination), err)
+			return
+		}
+	}
+
+	for _, wanted := range desired {
+		if len(filter.Find(wanted, routes)) != 0 {
+			continue
+		}
+
+		create := compute.RouteCreate{
+			Destination: wanted.Destination.Value,
+			NextHop:     wanted.NextHop.Value,
+		}
+
+		route, err := routeService.Create(ctx, create)
+		if err != nil {
+			addClientError(&diagnostics, fmt.Sprintf("unable to create route to %s", create.Destination), err)
+			return
+		}
+
+		routes = append(routes, route)
+	}
+
+	return
+}
+
+func containsRoute(desired []computeRouterRouteTableRouteData, route compute.Route) bool {
+	for _, wanted := range desired {
+		if wanted.AppliesTo(route) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func routeNextHopAttached(nextHop string, interfaces []compute.RouterInterface) bool {
+	ip := net.ParseIP(nextHop)
+	if ip == nil {
+		return false
+	}
+
+	for _, routerInterface := range interfaces {
+		_, network, err := net.ParseCIDR(routerInterface.Network.CIDR)
+		if err != nil {
+			continue
+		}
+
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateRouteNextHop(routerID int, nextHop string, interfaces []compute.RouterInterface) (diagnostics diag.Diagnostics) {
+	if routeNextHopAttached(nextHop, interfaces) {
+		return
+	}
+
+	diagnostics.AddError(
+		"Invalid Next Hop",
+		fmt.Sprintf("The next hop %s is not within any network attached to router %d. Attach the network using cloudbit_compute_router_interface first.", nextHop, routerID),
+	)
+	return
+}
@@ -0,0 +1,154 @@
+package cloudbit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+func TestComputeRouterRouteTable_ModifyPlan(t *testing.T) {
+	ctx := context.Background()
+	_, prov := testFakeProvider(t)
+
+	resourceType := computeRouterRouteTableResourceType{}
+	res := testNewResource(t, resourceType, prov)
+
+	networks, err := compute.NewNetworkService(prov.client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("unable to list networks: %s", err)
+	}
+
+	router, err := compute.NewRouterService(prov.client).Create(ctx, compute.RouterCreate{Name: "test", LocationID: fakeapi.LocationALP1})
+	if err != nil {
+		t.Fatalf("unable to create router: %s", err)
+	}
+
+	_, err = compute.NewRouterInterfaceService(prov.client, router.ID).Create(ctx, compute.RouterInterfaceCreate{NetworkID: networks.Items[0].ID})
+	if err != nil {
+		t.Fatalf("unable to attach network %s: %s", networks.Items[0].CIDR, err)
+	}
+
+	routeType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"destination": tftypes.String, "next_hop": tftypes.String}}
+	routes := func(nextHop string) tftypes.Value {
+		return tftypes.NewValue(tftypes.Set{ElementType: routeType}, []tftypes.Value{
+			tftypes.NewValue(routeType, map[string]tftypes.Value{
+				"destination": tftypes.NewValue(tftypes.String, "10.0.0.0/8"),
+				"next_hop":    tftypes.NewValue(tftypes.String, nextHop),
+			}),
+		})
+	}
+
+	tests := []struct {
+		name     string
+		routerID tftypes.Value
+		nextHop  string
+		warning  string
+	}{
+		{name: "attached next hop", routerID: tftypes.NewValue(tftypes.Number, router.ID), nextHop: "172.31.0.10"},
+		{name: "detached next hop", routerID: tftypes.NewValue(tftypes.Number, router.ID), nextHop: "192.168.0.10", warning: "Next Hop Not Attached"},
+		{name: "unknown router", routerID: tftypes.NewValue(tftypes.Number, tftypes.UnknownValue), nextHop: "192.168.0.10"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := testModifyPlan(t, res, resourceType, map[string]tftypes.Value{
+				"router_id": test.routerID,
+				"routes":    routes(test.nextHop),
+			})
+
+			if response.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", response.Diagnostics)
+			}
+
+			if test.warning == "" {
+				if len(response.Diagnostics) != 0 {
+					t.Fatalf("expected no diagnostics, got %v", response.Diagnostics)
+				}
+
+				return
+			}
+
+			if len(response.Diagnostics) != 1 || response.Diagnostics[0].Summary() != test.warning {
+				t.Fatalf("expected a %q warning, got %v", test.warning, response.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestAccComputeRouterRouteTable_Basic(t *testing.T) {
+	networkName := testAccRandomName(t, "test-network")
+	networkCIDR := "192.168.1.0/24"
+	routerName := testAccRandomName(t, "test-router")
+
+	nextHop, err := acctest.RandIpAddress(networkCIDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := fmt.Sprintf(`{ destination = "10.0.0.0/8", next_hop = "%s" }`, nextHop)
+	moreRoutes := fmt.Sprintf(`{ destination = "10.0.0.0/8", next_hop = "%[1]s" }, { destination = "172.16.0.0/12", next_hop = "%[1]s" }`, nextHop)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccComputeRouterRouteTableConfigBasic, networkName, networkCIDR, routerName, routes),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("cloudbit_compute_router_route_table.foobar", "router_id"),
+					resource.TestCheckResourceAttr("cloudbit_compute_router_route_table.foobar", "routes.#", "1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccComputeRouterRouteTableConfigBasic, networkName, networkCIDR, routerName, moreRoutes),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudbit_compute_router_route_table.foobar", "routes.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "cloudbit_compute_router_route_table.foobar",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccComputeRouterRouteTableConfigBasic = `
+locals {
+	location_id = 1
+}
+
+resource "cloudbit_compute_network" "foobar" {
+	name        = "%s"
+	location_id = local.location_id
+
+	cidr = "%s"
+}
+
+resource "cloudbit_compute_router" "foobar" {
+	name        = "%s"
+	location_id = local.location_id
+
+	public = false
+}
+
+resource "cloudbit_compute_router_interface" "foobar" {
+	router_id = cloudbit_compute_router.foobar.id
+	network_id = cloudbit_compute_network.foobar.id
+}
+
+resource "cloudbit_compute_router_route_table" "foobar" {
+	router_id = cloudbit_compute_router.foobar.id
+	routes    = [%s]
+
+	depends_on = [cloudbit_compute_router_interface.foobar]
+}
+`
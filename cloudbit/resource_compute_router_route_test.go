@@ -9,9 +9,9 @@ import (
 )
 
 func TestAccComputeRouterRoute_Basic(t *testing.T) {
-	networkName := acctest.RandomWithPrefix("test-network")
+	networkName := testAccRandomName(t, "test-network")
 	networkCIDR := "192.168.1.0/24"
-	routerName := acctest.RandomWithPrefix("test-router")
+	routerName := testAccRandomName(t, "test-router")
 
 	destination := "0.0.0.0/0"
 	nextHop, err := acctest.RandIpAddress(networkCIDR)
@@ -20,7 +20,7 @@ func TestAccComputeRouterRoute_Basic(t *testing.T) {
 	}
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeRouterRouteConfigBasic, networkName, networkCIDR, routerName, destination, nextHop),
@@ -2,31 +2,36 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeSecurityGroupResourceType)(nil)
-	_ tfsdk.Resource                = (*computeSecurityGroupResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeSecurityGroupResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeSecurityGroupResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeSecurityGroupResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeSecurityGroupResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeSecurityGroupResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeSecurityGroupResource)(nil)
 )
 
 type computeSecurityGroupResourceData struct {
 	ID         types.Int64  `tfsdk:"id"`
 	Name       types.String `tfsdk:"name"`
 	LocationID types.Int64  `tfsdk:"location_id"`
+	Location   types.String `tfsdk:"location"`
 }
 
 func (c *computeSecurityGroupResourceData) FromEntity(securityGroup compute.SecurityGroup) {
 	c.ID = types.Int64{Value: int64(securityGroup.ID)}
 	c.Name = types.String{Value: securityGroup.Name}
 	c.LocationID = types.Int64{Value: int64(securityGroup.Location.ID)}
+	c.Location = types.String{Value: securityGroup.Location.Key}
 }
 
 type computeSecurityGroupResourceType struct{}
@@ -47,14 +52,8 @@ func (c computeSecurityGroupResourceType) GetSchema(ctx context.Context) (tfsdk.
 				MarkdownDescription: "name of the security group",
 				Required:            true,
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the location",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("unique identifier of the location"),
+			"location":    locationAttribute(),
 		},
 	}, nil
 }
@@ -66,15 +65,22 @@ func (c computeSecurityGroupResourceType) NewResource(ctx context.Context, p tfs
 	}
 
 	return computeSecurityGroupResource{
+		locations: newLocationResolver(prov),
+
 		securityGroupService: compute.NewSecurityGroupService(prov.client),
 	}, diagnostics
 }
 
 type computeSecurityGroupResource struct {
+	locations locationResolver
+
 	securityGroupService compute.SecurityGroupService
 }
 
 func (c computeSecurityGroupResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeSecurityGroupResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -82,6 +88,12 @@ func (c computeSecurityGroupResource) Create(ctx context.Context, request tfsdk.
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := compute.SecurityGroupCreate{
 		Name:       config.Name.Value,
 		LocationID: int(config.LocationID.Value),
@@ -89,7 +101,7 @@ func (c computeSecurityGroupResource) Create(ctx context.Context, request tfsdk.
 
 	securityGroup, err := c.securityGroupService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create security group: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create security group", err)
 		return
 	}
 
@@ -101,6 +113,9 @@ func (c computeSecurityGroupResource) Create(ctx context.Context, request tfsdk.
 }
 
 func (c computeSecurityGroupResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSecurityGroupResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -110,7 +125,7 @@ func (c computeSecurityGroupResource) Read(ctx context.Context, request tfsdk.Re
 
 	securityGroup, err := c.securityGroupService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list security groups: %s", err))
+		addClientError(&response.Diagnostics, "unable to list security groups", err)
 		return
 	}
 
@@ -121,6 +136,9 @@ func (c computeSecurityGroupResource) Read(ctx context.Context, request tfsdk.Re
 }
 
 func (c computeSecurityGroupResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSecurityGroupResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -141,7 +159,7 @@ func (c computeSecurityGroupResource) Update(ctx context.Context, request tfsdk.
 
 	securityGroup, err := c.securityGroupService.Update(ctx, int(state.ID.Value), update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update security group: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update security group", err)
 		return
 	}
 
@@ -152,6 +170,9 @@ func (c computeSecurityGroupResource) Update(ctx context.Context, request tfsdk.
 }
 
 func (c computeSecurityGroupResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSecurityGroupResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -161,7 +182,7 @@ func (c computeSecurityGroupResource) Delete(ctx context.Context, request tfsdk.
 
 	err := c.securityGroupService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete security group: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete security group", err)
 		return
 	}
 }
@@ -169,3 +190,13 @@ func (c computeSecurityGroupResource) Delete(ctx context.Context, request tfsdk.
 func (c computeSecurityGroupResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (c computeSecurityGroupResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	c.locations.ModifyPlan(ctx, request, response)
+}
+
+func (c computeSecurityGroupResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
@@ -228,6 +228,9 @@ type computeSecurityGroupRuleResource struct {
 }
 
 func (c computeSecurityGroupRuleResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group_rule", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeSecurityGroupRuleResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -255,7 +258,7 @@ func (c computeSecurityGroupRuleResource) Create(ctx context.Context, request tf
 
 	rule, err := c.securityGroupService.Rules(securityGroupID).Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create security group rule: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create security group rule", err)
 		return
 	}
 
@@ -267,6 +270,9 @@ func (c computeSecurityGroupRuleResource) Create(ctx context.Context, request tf
 }
 
 func (c computeSecurityGroupRuleResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group_rule", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSecurityGroupRuleResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -279,7 +285,7 @@ func (c computeSecurityGroupRuleResource) Read(ctx context.Context, request tfsd
 
 	list, err := c.securityGroupService.Rules(securityGroupID).List(ctx, goclient.Cursor{NoFilter: 1})
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to list security group rules: %s", err))
+		addClientError(&response.Diagnostics, "unable to list security group rules", err)
 		return
 	}
 
@@ -297,6 +303,9 @@ func (c computeSecurityGroupRuleResource) Read(ctx context.Context, request tfsd
 }
 
 func (c computeSecurityGroupRuleResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group_rule", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSecurityGroupRuleResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -333,7 +342,7 @@ func (c computeSecurityGroupRuleResource) Update(ctx context.Context, request tf
 
 	rule, err := c.securityGroupService.Rules(securityGroupID).Update(ctx, ruleID, update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update security group rule: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update security group rule", err)
 		return
 	}
 
@@ -344,6 +353,9 @@ func (c computeSecurityGroupRuleResource) Update(ctx context.Context, request tf
 }
 
 func (c computeSecurityGroupRuleResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_security_group_rule", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSecurityGroupRuleResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -356,7 +368,7 @@ func (c computeSecurityGroupRuleResource) Delete(ctx context.Context, request tf
 
 	err := c.securityGroupService.Rules(securityGroupID).Delete(ctx, ruleID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete security group rule: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete security group rule", err)
 		return
 	}
 }
@@ -4,12 +4,11 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestAccComputeSecurityGroupRule_Basic(t *testing.T) {
-	securityGroupName := acctest.RandomWithPrefix("test-security-group")
+	securityGroupName := testAccRandomName(t, "test-security-group")
 
 	protocolNumber := "6"
 	protocolName := "tcp"
@@ -18,7 +17,7 @@ func TestAccComputeSecurityGroupRule_Basic(t *testing.T) {
 	ipRange := "1.1.1.1/32"
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeSecurityGroupRuleConfigBasic, securityGroupName, "foobar_ingress", "ingress", protocolName, fromPort, toPort, ipRange),
@@ -2,7 +2,6 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/flowswiss/goclient/common"
 	"github.com/flowswiss/goclient/compute"
@@ -10,18 +9,23 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeServerResourceType)(nil)
-	_ tfsdk.Resource                = (*computeServerResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeServerResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeServerResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeServerResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeServerResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeServerResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeServerResource)(nil)
 )
 
 type computeServerResourceData struct {
 	ID         types.Int64  `tfsdk:"id"`
 	Name       types.String `tfsdk:"name"`
 	LocationID types.Int64  `tfsdk:"location_id"`
+	Location   types.String `tfsdk:"location"`
 	ImageID    types.Int64  `tfsdk:"image_id"`
 	ProductID  types.Int64  `tfsdk:"product_id"`
 	NetworkID  types.Int64  `tfsdk:"network_id"`
@@ -29,12 +33,14 @@ type computeServerResourceData struct {
 	KeyPairID  types.Int64  `tfsdk:"key_pair_id"`
 	Password   types.String `tfsdk:"password"`
 	CloudInit  types.String `tfsdk:"cloud_init"`
+	OrderID    types.Int64  `tfsdk:"order_id"`
 }
 
 func (c *computeServerResourceData) FromEntity(server compute.Server) {
 	c.ID = types.Int64{Value: int64(server.ID)}
 	c.Name = types.String{Value: server.Name}
 	c.LocationID = types.Int64{Value: int64(server.Location.ID)}
+	c.Location = types.String{Value: server.Location.Key}
 	c.ImageID = types.Int64{Value: int64(server.Image.ID)}
 	c.ProductID = types.Int64{Value: int64(server.Product.ID)}
 	c.KeyPairID = types.Int64{Value: int64(server.KeyPair.ID)}
@@ -64,14 +70,8 @@ func (c computeServerResourceType) GetSchema(ctx context.Context) (tfsdk.Schema,
 				MarkdownDescription: "name of the server",
 				Required:            true,
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the location",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("unique identifier of the location"),
+			"location":    locationAttribute(),
 			"image_id": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "unique identifier of the image",
@@ -130,6 +130,7 @@ func (c computeServerResourceType) GetSchema(ctx context.Context) (tfsdk.Schema,
 					tfsdk.RequiresReplace(),
 				},
 			},
+			"order_id": orderIDAttribute(),
 		},
 	}, nil
 }
@@ -141,23 +142,36 @@ func (c computeServerResourceType) NewResource(ctx context.Context, p tfsdk.Prov
 	}
 
 	return computeServerResource{
+		locations: newLocationResolver(prov),
+
 		serverService: compute.NewServerService(prov.client),
 		orderService:  common.NewOrderService(prov.client),
 	}, diagnostics
 }
 
 type computeServerResource struct {
+	locations locationResolver
+
 	serverService compute.ServerService
 	orderService  common.OrderService
 }
 
 func (c computeServerResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_server", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeServerResourceData
 	response.Diagnostics.Append(request.Config.Get(ctx, &config)...)
 	if response.Diagnostics.HasError() {
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := compute.ServerCreate{
 		Name:             config.Name.Value,
 		LocationID:       int(config.LocationID.Value),
@@ -173,41 +187,78 @@ func (c computeServerResource) Create(ctx context.Context, request tfsdk.CreateR
 
 	ordering, err := c.serverService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create server: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create server", err)
 		return
 	}
 
-	order, err := c.orderService.WaitUntilProcessed(ctx, ordering)
+	orderID, err := ordering.ExtractIdentifier()
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("waiting for server creation: %s", err))
+		addClientError(&response.Diagnostics, "unable to get server order", err)
+		return
+	}
+
+	// record the ordered server right away, so that it is tainted instead of lost if the order does not complete
+	state := config
+	state.ID = types.Int64{Null: true}
+	state.OrderID = types.Int64{Value: int64(orderID)}
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationPath, &state.Location)...)
+	if state.PrivateIP.Null || state.PrivateIP.Unknown {
+		state.PrivateIP = types.String{Null: true}
+	}
+
+	response.Diagnostics.Append(response.State.Set(ctx, state)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	order, err := waitForOrder(ctx, c.orderService, ordering)
+	if err != nil {
+		addClientError(&response.Diagnostics, "waiting for server creation", err)
 		return
 	}
 
 	server, err := c.serverService.Get(ctx, order.Product.ID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get server: %s", err))
+		state.ID = types.Int64{Value: int64(order.Product.ID)}
+		response.Diagnostics.Append(response.State.Set(ctx, state)...)
+
+		addClientError(&response.Diagnostics, "unable to get server", err)
 		return
 	}
 
-	var state computeServerResourceData
 	state.FromEntity(server)
 
-	state.Password = config.Password
-	state.CloudInit = config.CloudInit
-
 	response.Diagnostics.Append(response.State.Set(ctx, state)...)
 }
 
 func (c computeServerResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_server", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeServerResourceData
 	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
 	if response.Diagnostics.HasError() {
 		return
 	}
 
+	if state.ID.Null {
+		serverID, created, err := resumeOrder(ctx, c.orderService, state.OrderID)
+		if err != nil {
+			addClientError(&response.Diagnostics, "unable to resume server creation", err)
+			return
+		}
+
+		if !created {
+			response.State.RemoveResource(ctx)
+			return
+		}
+
+		state.ID = types.Int64{Value: int64(serverID)}
+	}
+
 	server, err := c.serverService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get server: %s", err))
+		addClientError(&response.Diagnostics, "unable to get server", err)
 		return
 	}
 
@@ -217,6 +268,9 @@ func (c computeServerResource) Read(ctx context.Context, request tfsdk.ReadResou
 }
 
 func (c computeServerResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_server", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeServerResourceData
 	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
 	if response.Diagnostics.HasError() {
@@ -235,7 +289,7 @@ func (c computeServerResource) Update(ctx context.Context, request tfsdk.UpdateR
 
 	server, err := c.serverService.Update(ctx, int(state.ID.Value), update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update server: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update server", err)
 		return
 	}
 
@@ -245,15 +299,32 @@ func (c computeServerResource) Update(ctx context.Context, request tfsdk.UpdateR
 }
 
 func (c computeServerResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_server", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeServerResourceData
 	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
 	if response.Diagnostics.HasError() {
 		return
 	}
 
+	if state.ID.Null {
+		serverID, created, err := resumeOrder(ctx, c.orderService, state.OrderID)
+		if err != nil {
+			addClientError(&response.Diagnostics, "unable to resume server creation", err)
+			return
+		}
+
+		if !created {
+			return
+		}
+
+		state.ID = types.Int64{Value: int64(serverID)}
+	}
+
 	err := c.serverService.Delete(ctx, int(state.ID.Value), false)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete server: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete server", err)
 		return
 	}
 }
@@ -261,3 +332,41 @@ func (c computeServerResource) Delete(ctx context.Context, request tfsdk.DeleteR
 func (c computeServerResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (c computeServerResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	location, known := c.locations.ModifyPlan(ctx, request, response)
+	if !known || response.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state computeServerResourceData
+	response.Diagnostics.Append(response.Plan.GetAttribute(ctx, path.Root("product_id"), &plan.ProductID)...)
+	response.Diagnostics.Append(response.Plan.GetAttribute(ctx, path.Root("image_id"), &plan.ImageID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if !request.State.Raw.IsNull() {
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, locationIDPath, &state.LocationID)...)
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, path.Root("product_id"), &state.ProductID)...)
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, path.Root("image_id"), &state.ImageID)...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+
+		// only check the compatibility if something relevant changed to avoid unnecessary requests on every plan
+		if state.LocationID.Value == int64(location.ID) && state.ProductID.Equal(plan.ProductID) && state.ImageID.Equal(plan.ImageID) {
+			return
+		}
+	}
+
+	c.locations.CheckModule(ctx, location, moduleCompute, &response.Diagnostics)
+	product := c.locations.CheckProduct(ctx, path.Root("product_id"), plan.ProductID, location, &response.Diagnostics)
+	c.locations.CheckImage(ctx, path.Root("image_id"), plan.ImageID, product, location, &response.Diagnostics)
+}
+
+func (c computeServerResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
@@ -0,0 +1,125 @@
+package cloudbit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+func TestComputeServer_ResumeOrder(t *testing.T) {
+	api, prov := testFakeProvider(t)
+
+	resourceType := computeServerResourceType{}
+	res := testNewResource(t, resourceType, prov)
+
+	networks, err := compute.NewNetworkService(prov.client).List(context.Background(), goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("unable to list networks: %s", err)
+	}
+
+	// create submits the order of a server, but gives up waiting for it as the fake holds the order
+	create := func(t *testing.T) computeServerResourceData {
+		api.HoldOrders(true)
+		defer api.HoldOrders(false)
+
+		planned := testResourceState(t, resourceType, map[string]tftypes.Value{
+			"name":        tftypes.NewValue(tftypes.String, "test"),
+			"location_id": tftypes.NewValue(tftypes.Number, fakeapi.LocationALP1),
+			"location":    tftypes.NewValue(tftypes.String, "ALP1"),
+			"image_id":    tftypes.NewValue(tftypes.Number, fakeapi.ImageUbuntu),
+			"product_id":  tftypes.NewValue(tftypes.Number, fakeapi.ProductServerSmall),
+			"network_id":  tftypes.NewValue(tftypes.Number, networks.Items[0].ID),
+			"password":    tftypes.NewValue(tftypes.String, "secret-password"),
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		response := tfsdk.CreateResourceResponse{State: testResourceState(t, resourceType, nil)}
+		res.Create(ctx, tfsdk.CreateResourceRequest{
+			Config: tfsdk.Config{Schema: planned.Schema, Raw: planned.Raw},
+			Plan:   tfsdk.Plan{Schema: planned.Schema, Raw: planned.Raw},
+		}, &response)
+
+		if !response.Diagnostics.HasError() {
+			t.Fatal("expected waiting for the held order to fail")
+		}
+
+		// terraform taints a created resource whose creation returned an error
+		if response.State.Raw.IsNull() {
+			t.Fatal("expected the server to be recorded in the state")
+		}
+
+		var state computeServerResourceData
+		response.Diagnostics = nil
+		response.Diagnostics.Append(response.State.Get(context.Background(), &state)...)
+		if response.Diagnostics.HasError() {
+			t.Fatalf("unable to get state: %v", response.Diagnostics)
+		}
+
+		if !state.ID.Null || state.OrderID.Null || state.OrderID.Value == 0 {
+			t.Fatalf("expected the state to have an order id but no id, got id %v and order id %v", state.ID, state.OrderID)
+		}
+
+		return state
+	}
+
+	read := func(t *testing.T, state computeServerResourceData) tfsdk.ReadResourceResponse {
+		current := testResourceState(t, resourceType, nil)
+		if diagnostics := current.Set(context.Background(), state); diagnostics.HasError() {
+			t.Fatalf("unable to set state: %v", diagnostics)
+		}
+
+		response := tfsdk.ReadResourceResponse{State: current}
+		res.Read(context.Background(), tfsdk.ReadResourceRequest{State: current}, &response)
+
+		if response.Diagnostics.HasError() {
+			t.Fatalf("unable to read server: %v", response.Diagnostics)
+		}
+
+		return response
+	}
+
+	t.Run("succeeded", func(t *testing.T) {
+		state := create(t)
+
+		if err := api.SetOrderStatus(int(state.OrderID.Value), common.OrderStatusSucceeded); err != nil {
+			t.Fatal(err)
+		}
+
+		response := read(t, state)
+
+		var resumed computeServerResourceData
+		response.State.Get(context.Background(), &resumed)
+
+		if resumed.ID.Null || resumed.ID.Value == 0 {
+			t.Fatalf("expected the id of the ordered server to be filled in, got %v", resumed.ID)
+		}
+
+		if resumed.Name.Value != "test" || resumed.OrderID.Value != state.OrderID.Value {
+			t.Errorf("expected the server to be read, got %+v", resumed)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		state := create(t)
+
+		if err := api.SetOrderStatus(int(state.OrderID.Value), common.OrderStatusFailed); err != nil {
+			t.Fatal(err)
+		}
+
+		response := read(t, state)
+
+		if !response.State.Raw.IsNull() {
+			t.Error("expected the server of the failed order to be removed from the state")
+		}
+	})
+}
@@ -2,7 +2,6 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -93,6 +92,9 @@ type computeSnapshotResource struct {
 }
 
 func (r computeSnapshotResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_snapshot", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeSnapshotResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -107,7 +109,7 @@ func (r computeSnapshotResource) Create(ctx context.Context, request tfsdk.Creat
 
 	snapshot, err := r.snapshotService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create snapshot: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create snapshot", err)
 		return
 	}
 
@@ -131,6 +133,9 @@ func (r computeSnapshotResource) Create(ctx context.Context, request tfsdk.Creat
 }
 
 func (r computeSnapshotResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_snapshot", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSnapshotResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -140,7 +145,7 @@ func (r computeSnapshotResource) Read(ctx context.Context, request tfsdk.ReadRes
 
 	snapshot, err := r.snapshotService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get snapshot: %s", err))
+		addClientError(&response.Diagnostics, "unable to get snapshot", err)
 		return
 	}
 
@@ -151,6 +156,9 @@ func (r computeSnapshotResource) Read(ctx context.Context, request tfsdk.ReadRes
 }
 
 func (r computeSnapshotResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_snapshot", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSnapshotResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -181,7 +189,7 @@ func (r computeSnapshotResource) Update(ctx context.Context, request tfsdk.Updat
 
 	snapshot, err := r.snapshotService.Update(ctx, int(state.ID.Value), update)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update snapshot: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update snapshot", err)
 		return
 	}
 
@@ -192,6 +200,9 @@ func (r computeSnapshotResource) Update(ctx context.Context, request tfsdk.Updat
 }
 
 func (r computeSnapshotResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_snapshot", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeSnapshotResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -201,7 +212,7 @@ func (r computeSnapshotResource) Delete(ctx context.Context, request tfsdk.Delet
 
 	err := r.snapshotService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete snapshot: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete snapshot", err)
 		return
 	}
 }
@@ -213,7 +224,7 @@ func (r computeSnapshotResource) ImportState(ctx context.Context, request tfsdk.
 func (r computeSnapshotResource) waitForSnapshotStatus(ctx context.Context, snapshotID int) (done bool, diagnostics diag.Diagnostics) {
 	snapshot, err := r.snapshotService.Get(ctx, snapshotID)
 	if err != nil {
-		diagnostics.AddError("Client Error", fmt.Sprintf("unable to get snapshot: %s", err))
+		addClientError(&diagnostics, "unable to get snapshot", err)
 		return
 	}
 
@@ -11,12 +11,12 @@ import (
 func TestAccComputeSnapshot_Basic(t *testing.T) {
 	t.Skip("skipping test due to race condition during deletion in api")
 
-	volumeName := acctest.RandomWithPrefix("test-volume")
+	volumeName := testAccRandomName(t, "test-volume")
 	volumeSize := acctest.RandIntRange(1, 20)
-	snapshotName := acctest.RandomWithPrefix("test-snapshot")
+	snapshotName := testAccRandomName(t, "test-snapshot")
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeSnapshotConfigBasic, volumeName, volumeSize, snapshotName),
@@ -2,7 +2,6 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/flowswiss/goclient/compute"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
@@ -11,12 +10,16 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*computeVolumeResourceType)(nil)
-	_ tfsdk.Resource                = (*computeVolumeResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*computeVolumeResource)(nil)
+	_ tfsdk.ResourceType                 = (*computeVolumeResourceType)(nil)
+	_ tfsdk.Resource                     = (*computeVolumeResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*computeVolumeResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*computeVolumeResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*computeVolumeResource)(nil)
 )
 
 type computeVolumeResourceData struct {
@@ -24,7 +27,8 @@ type computeVolumeResourceData struct {
 	SerialNumber types.String `tfsdk:"serial_number"`
 	Name         types.String `tfsdk:"name"`
 	Size         types.Int64  `tfsdk:"size"`
-	Location     types.Int64  `tfsdk:"location_id"`
+	LocationID   types.Int64  `tfsdk:"location_id"`
+	Location     types.String `tfsdk:"location"`
 	Snapshot     types.Int64  `tfsdk:"restore_from_snapshot_id"`
 }
 
@@ -33,7 +37,8 @@ func (d *computeVolumeResourceData) FromEntity(volume compute.Volume) {
 	d.SerialNumber = types.String{Value: volume.SerialNumber}
 	d.Name = types.String{Value: volume.Name}
 	d.Size = types.Int64{Value: int64(volume.Size)}
-	d.Location = types.Int64{Value: int64(volume.Location.ID)}
+	d.LocationID = types.Int64{Value: int64(volume.Location.ID)}
+	d.Location = types.String{Value: volume.Location.Key}
 }
 
 type computeVolumeResourceType struct{}
@@ -74,14 +79,8 @@ func (t computeVolumeResourceType) GetSchema(ctx context.Context) (tfsdk.Schema,
 					}, "", "volume size cannot be decreased"),
 				},
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "identifier of the location of the volume",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("identifier of the location of the volume"),
+			"location":    locationAttribute(),
 			"restore_from_snapshot_id": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "restore the volume from the snapshot",
@@ -101,15 +100,22 @@ func (t computeVolumeResourceType) NewResource(ctx context.Context, p tfsdk.Prov
 	}
 
 	return computeVolumeResource{
+		locations: newLocationResolver(prov),
+
 		volumeService: compute.NewVolumeService(prov.client),
 	}, diagnostics
 }
 
 type computeVolumeResource struct {
+	locations locationResolver
+
 	volumeService compute.VolumeService
 }
 
 func (r computeVolumeResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeVolumeResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -117,16 +123,22 @@ func (r computeVolumeResource) Create(ctx context.Context, request tfsdk.CreateR
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := compute.VolumeCreate{
 		Name:       config.Name.Value,
 		Size:       int(config.Size.Value),
-		LocationID: int(config.Location.Value),
+		LocationID: int(config.LocationID.Value),
 		SnapshotID: int(config.Snapshot.Value),
 	}
 
 	volume, err := r.volumeService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create volume: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create volume", err)
 		return
 	}
 
@@ -154,6 +166,9 @@ func (r computeVolumeResource) Create(ctx context.Context, request tfsdk.CreateR
 }
 
 func (r computeVolumeResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeVolumeResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -163,7 +178,7 @@ func (r computeVolumeResource) Read(ctx context.Context, request tfsdk.ReadResou
 
 	volume, err := r.volumeService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get volume: %s", err))
+		addClientError(&response.Diagnostics, "unable to get volume", err)
 		return
 	}
 
@@ -174,6 +189,9 @@ func (r computeVolumeResource) Read(ctx context.Context, request tfsdk.ReadResou
 }
 
 func (r computeVolumeResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeVolumeResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -190,7 +208,7 @@ func (r computeVolumeResource) Update(ctx context.Context, request tfsdk.UpdateR
 
 	volume, err := r.volumeService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get volume: %s", err))
+		addClientError(&response.Diagnostics, "unable to get volume", err)
 		return
 	}
 
@@ -207,7 +225,7 @@ func (r computeVolumeResource) Update(ctx context.Context, request tfsdk.UpdateR
 
 		volume, err = r.volumeService.Update(ctx, int(state.ID.Value), update)
 		if err != nil {
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update volume: %s", err))
+			addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update volume", err)
 			return
 		}
 	}
@@ -225,7 +243,7 @@ func (r computeVolumeResource) Update(ctx context.Context, request tfsdk.UpdateR
 
 		volume, err = r.volumeService.Expand(ctx, int(state.ID.Value), expand)
 		if err != nil {
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to expand volume: %s", err))
+			addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to expand volume", err)
 			return
 		}
 	}
@@ -237,6 +255,9 @@ func (r computeVolumeResource) Update(ctx context.Context, request tfsdk.UpdateR
 }
 
 func (r computeVolumeResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeVolumeResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -246,7 +267,7 @@ func (r computeVolumeResource) Delete(ctx context.Context, request tfsdk.DeleteR
 
 	err := r.volumeService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete volume: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete volume", err)
 		return
 	}
 }
@@ -255,10 +276,20 @@ func (r computeVolumeResource) ImportState(ctx context.Context, request tfsdk.Im
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
 
+func (r computeVolumeResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	r.locations.ModifyPlan(ctx, request, response)
+}
+
+func (r computeVolumeResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
+
 func (r computeVolumeResource) waitForVolumeStatus(ctx context.Context, volumeID int) (done bool, diagnostics diag.Diagnostics) {
 	volume, err := r.volumeService.Get(ctx, volumeID)
 	if err != nil {
-		diagnostics.AddError("Client Error", fmt.Sprintf("unable to get volume: %s", err))
+		addClientError(&diagnostics, "unable to get volume", err)
 		return
 	}
 
@@ -2,7 +2,6 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/flowswiss/goclient"
 	"github.com/flowswiss/goclient/compute"
@@ -62,6 +61,9 @@ type computeVolumeAttachmentResource struct {
 }
 
 func (r computeVolumeAttachmentResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume_attachment", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config computeVolumeAttachmentResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -73,7 +75,7 @@ func (r computeVolumeAttachmentResource) Create(ctx context.Context, request tfs
 
 	volume, err := service.Get(ctx, int(config.VolumeID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get volume: %s", err))
+		addClientError(&response.Diagnostics, "unable to get volume", err)
 		return
 	}
 
@@ -100,7 +102,7 @@ func (r computeVolumeAttachmentResource) Create(ctx context.Context, request tfs
 
 	volume, err = service.Attach(ctx, int(config.VolumeID.Value), attach)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to attach volume: %s", err))
+		addClientError(&response.Diagnostics, "unable to attach volume", err)
 		return
 	}
 
@@ -112,6 +114,9 @@ func (r computeVolumeAttachmentResource) Create(ctx context.Context, request tfs
 }
 
 func (r computeVolumeAttachmentResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume_attachment", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeVolumeAttachmentResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -121,7 +126,7 @@ func (r computeVolumeAttachmentResource) Read(ctx context.Context, request tfsdk
 
 	volume, err := compute.NewVolumeService(r.client).Get(ctx, int(state.VolumeID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get volume: %s", err))
+		addClientError(&response.Diagnostics, "unable to get volume", err)
 		return
 	}
 
@@ -132,6 +137,9 @@ func (r computeVolumeAttachmentResource) Read(ctx context.Context, request tfsdk
 }
 
 func (r computeVolumeAttachmentResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume_attachment", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeVolumeAttachmentResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -149,7 +157,7 @@ func (r computeVolumeAttachmentResource) Update(ctx context.Context, request tfs
 	// detach the volume from the current server
 	err := compute.NewVolumeService(r.client).Detach(ctx, int(state.VolumeID.Value), int(state.ServerID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to detach volume from current server: %s", err))
+		addClientError(&response.Diagnostics, "unable to detach volume from current server", err)
 		return
 	}
 
@@ -162,7 +170,7 @@ func (r computeVolumeAttachmentResource) Update(ctx context.Context, request tfs
 
 	volume, err := compute.NewVolumeService(r.client).Attach(ctx, int(state.VolumeID.Value), attach)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to attach volume to new server: %s", err))
+		addClientError(&response.Diagnostics, "unable to attach volume to new server", err)
 		return
 	}
 
@@ -175,6 +183,9 @@ func (r computeVolumeAttachmentResource) Update(ctx context.Context, request tfs
 }
 
 func (r computeVolumeAttachmentResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_compute_volume_attachment", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state computeVolumeAttachmentResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -184,7 +195,7 @@ func (r computeVolumeAttachmentResource) Delete(ctx context.Context, request tfs
 
 	err := compute.NewVolumeService(r.client).Detach(ctx, int(state.VolumeID.Value), int(state.ServerID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to detach volume: %s", err))
+		addClientError(&response.Diagnostics, "unable to detach volume", err)
 		return
 	}
 }
@@ -9,11 +9,11 @@ import (
 )
 
 func TestAccComputeVolume_Basic(t *testing.T) {
-	volumeName := acctest.RandomWithPrefix("test-volume")
+	volumeName := testAccRandomName(t, "test-volume")
 	volumeSize := acctest.RandIntRange(1, 20)
 
 	resource.ParallelTest(t, resource.TestCase{
-		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: fmt.Sprintf(testAccComputeVolumeConfigBasic, volumeName, volumeSize),
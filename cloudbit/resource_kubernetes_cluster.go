@@ -2,7 +2,6 @@ package cloudbit
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/flowswiss/goclient/common"
 	"github.com/flowswiss/goclient/kubernetes"
@@ -10,21 +9,26 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/validators"
 )
 
 var (
-	_ tfsdk.ResourceType            = (*kubernetesClusterResourceType)(nil)
-	_ tfsdk.Resource                = (*kubernetesClusterResource)(nil)
-	_ tfsdk.ResourceWithImportState = (*kubernetesClusterResource)(nil)
+	_ tfsdk.ResourceType                 = (*kubernetesClusterResourceType)(nil)
+	_ tfsdk.Resource                     = (*kubernetesClusterResource)(nil)
+	_ tfsdk.ResourceWithImportState      = (*kubernetesClusterResource)(nil)
+	_ tfsdk.ResourceWithModifyPlan       = (*kubernetesClusterResource)(nil)
+	_ tfsdk.ResourceWithConfigValidators = (*kubernetesClusterResource)(nil)
 )
 
 type kubernetesClusterResourceData struct {
 	ID   types.Int64  `tfsdk:"id"`
 	Name types.String `tfsdk:"name"`
 
-	LocationID      types.Int64 `tfsdk:"location_id"`
-	NetworkID       types.Int64 `tfsdk:"network_id"`
-	SecurityGroupID types.Int64 `tfsdk:"security_group_id"`
+	LocationID      types.Int64  `tfsdk:"location_id"`
+	Location        types.String `tfsdk:"location"`
+	NetworkID       types.Int64  `tfsdk:"network_id"`
+	SecurityGroupID types.Int64  `tfsdk:"security_group_id"`
 
 	Public        types.Bool   `tfsdk:"public"`
 	PublicAddress types.String `tfsdk:"public_address"`
@@ -34,6 +38,8 @@ type kubernetesClusterResourceData struct {
 
 	NodeCount     types.Int64 `tfsdk:"node_count"`
 	NodeProductID types.Int64 `tfsdk:"node_product_id"`
+
+	OrderID types.Int64 `tfsdk:"order_id"`
 }
 
 func (k *kubernetesClusterResourceData) FromEntity(cluster kubernetes.Cluster) {
@@ -41,6 +47,7 @@ func (k *kubernetesClusterResourceData) FromEntity(cluster kubernetes.Cluster) {
 	k.Name = types.String{Value: cluster.Name}
 
 	k.LocationID = types.Int64{Value: int64(cluster.Location.ID)}
+	k.Location = types.String{Value: cluster.Location.Key}
 	k.NetworkID = types.Int64{Value: int64(cluster.Network.ID)}
 	k.SecurityGroupID = types.Int64{Value: int64(cluster.SecurityGroup.ID)}
 
@@ -86,14 +93,8 @@ func (k kubernetesClusterResourceType) GetSchema(ctx context.Context) (tfsdk.Sch
 				MarkdownDescription: "name of the cluster",
 				Required:            true,
 			},
-			"location_id": {
-				Type:                types.Int64Type,
-				MarkdownDescription: "unique identifier of the location",
-				Required:            true,
-				PlanModifiers: tfsdk.AttributePlanModifiers{
-					tfsdk.RequiresReplace(),
-				},
-			},
+			"location_id": locationIDAttribute("unique identifier of the location"),
+			"location":    locationAttribute(),
 			"network_id": {
 				Type:                types.Int64Type,
 				MarkdownDescription: "unique identifier of the network",
@@ -154,6 +155,7 @@ func (k kubernetesClusterResourceType) GetSchema(ctx context.Context) (tfsdk.Sch
 				MarkdownDescription: "unique identifier of the node product",
 				Required:            true,
 			},
+			"order_id": orderIDAttribute(),
 		},
 	}, nil
 }
@@ -165,17 +167,24 @@ func (k kubernetesClusterResourceType) NewResource(ctx context.Context, p tfsdk.
 	}
 
 	return kubernetesClusterResource{
+		locations: newLocationResolver(prov),
+
 		orderService:   common.NewOrderService(prov.client),
 		clusterService: kubernetes.NewClusterService(prov.client),
 	}, diagnostics
 }
 
 type kubernetesClusterResource struct {
+	locations locationResolver
+
 	orderService   common.OrderService
 	clusterService kubernetes.ClusterService
 }
 
 func (k kubernetesClusterResource) Create(ctx context.Context, request tfsdk.CreateResourceRequest, response *tfsdk.CreateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_kubernetes_cluster", "create")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var config kubernetesClusterResourceData
 	diagnostics := request.Config.Get(ctx, &config)
 	response.Diagnostics.Append(diagnostics...)
@@ -183,6 +192,12 @@ func (k kubernetesClusterResource) Create(ctx context.Context, request tfsdk.Cre
 		return
 	}
 
+	// the location is resolved during planning if it is not configured explicitly
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationIDPath, &config.LocationID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
 	create := kubernetes.ClusterCreate{
 		Name:       config.Name.Value,
 		LocationID: int(config.LocationID.Value),
@@ -200,24 +215,54 @@ func (k kubernetesClusterResource) Create(ctx context.Context, request tfsdk.Cre
 
 	ordering, err := k.clusterService.Create(ctx, create)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to create cluster: %s", err))
+		addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to create cluster", err)
+		return
+	}
+
+	orderID, err := ordering.ExtractIdentifier()
+	if err != nil {
+		addClientError(&response.Diagnostics, "unable to get cluster order", err)
 		return
 	}
 
-	order, err := k.orderService.WaitUntilProcessed(ctx, ordering)
+	// record the ordered cluster right away, so that it is tainted instead of lost if the order does not complete
+	state := kubernetesClusterResourceData{
+		ID:              types.Int64{Null: true},
+		Name:            config.Name,
+		LocationID:      config.LocationID,
+		NetworkID:       config.NetworkID,
+		SecurityGroupID: types.Int64{Null: true},
+		Public:          types.Bool{Value: create.AttachExternalIP},
+		PublicAddress:   types.String{Null: true},
+		DNSName:         types.String{Null: true},
+		VersionID:       types.Int64{Null: true},
+		NodeCount:       config.NodeCount,
+		NodeProductID:   config.NodeProductID,
+		OrderID:         types.Int64{Value: int64(orderID)},
+	}
+
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, locationPath, &state.Location)...)
+	response.Diagnostics.Append(response.State.Set(ctx, state)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	order, err := waitForOrder(ctx, k.orderService, ordering)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("waiting for cluster creation: %s", err))
+		addClientError(&response.Diagnostics, "waiting for cluster creation", err)
 		return
 	}
 
 	cluster, err := k.clusterService.Get(ctx, order.Product.ID)
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get cluster: %s", err))
+		state.ID = types.Int64{Value: int64(order.Product.ID)}
+		response.Diagnostics.Append(response.State.Set(ctx, state)...)
+
+		addClientError(&response.Diagnostics, "unable to get cluster", err)
 		return
 	}
 
 	// set state of the resource
-	var state kubernetesClusterResourceData
 	state.FromEntity(cluster)
 
 	diagnostics = response.State.Set(ctx, state)
@@ -225,6 +270,9 @@ func (k kubernetesClusterResource) Create(ctx context.Context, request tfsdk.Cre
 }
 
 func (k kubernetesClusterResource) Read(ctx context.Context, request tfsdk.ReadResourceRequest, response *tfsdk.ReadResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_kubernetes_cluster", "read")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state kubernetesClusterResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -232,9 +280,24 @@ func (k kubernetesClusterResource) Read(ctx context.Context, request tfsdk.ReadR
 		return
 	}
 
+	if state.ID.Null {
+		clusterID, created, err := resumeOrder(ctx, k.orderService, state.OrderID)
+		if err != nil {
+			addClientError(&response.Diagnostics, "unable to resume cluster creation", err)
+			return
+		}
+
+		if !created {
+			response.State.RemoveResource(ctx)
+			return
+		}
+
+		state.ID = types.Int64{Value: int64(clusterID)}
+	}
+
 	cluster, err := k.clusterService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get cluster: %s", err))
+		addClientError(&response.Diagnostics, "unable to get cluster", err)
 		return
 	}
 
@@ -245,6 +308,9 @@ func (k kubernetesClusterResource) Read(ctx context.Context, request tfsdk.ReadR
 }
 
 func (k kubernetesClusterResource) Update(ctx context.Context, request tfsdk.UpdateResourceRequest, response *tfsdk.UpdateResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_kubernetes_cluster", "update")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state kubernetesClusterResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -266,7 +332,7 @@ func (k kubernetesClusterResource) Update(ctx context.Context, request tfsdk.Upd
 
 		_, err := k.clusterService.Update(ctx, int(state.ID.Value), update)
 		if err != nil {
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to update cluster: %s", err))
+			addClientErrorWithAttributes(&response.Diagnostics, request.Plan.Schema, "unable to update cluster", err)
 			return
 		}
 	}
@@ -279,7 +345,7 @@ func (k kubernetesClusterResource) Update(ctx context.Context, request tfsdk.Upd
 
 		_, err := k.clusterService.UpdateConfiguration(ctx, int(state.ID.Value), update)
 		if err != nil {
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to change cluster configuration: %s", err))
+			addClientError(&response.Diagnostics, "unable to change cluster configuration", err)
 			return
 		}
 	}
@@ -294,14 +360,14 @@ func (k kubernetesClusterResource) Update(ctx context.Context, request tfsdk.Upd
 
 		_, err := k.clusterService.UpdateFlavor(ctx, int(state.ID.Value), update)
 		if err != nil {
-			response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to change cluster flavor: %s", err))
+			addClientError(&response.Diagnostics, "unable to change cluster flavor", err)
 			return
 		}
 	}
 
 	cluster, err := k.clusterService.Get(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to get cluster: %s", err))
+		addClientError(&response.Diagnostics, "unable to get cluster", err)
 		return
 	}
 
@@ -312,6 +378,9 @@ func (k kubernetesClusterResource) Update(ctx context.Context, request tfsdk.Upd
 }
 
 func (k kubernetesClusterResource) Delete(ctx context.Context, request tfsdk.DeleteResourceRequest, response *tfsdk.DeleteResourceResponse) {
+	ctx, span := startResourceSpan(ctx, "cloudbit_kubernetes_cluster", "delete")
+	defer endSpanWithDiagnostics(span, &response.Diagnostics)
+
 	var state kubernetesClusterResourceData
 	diagnostics := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diagnostics...)
@@ -319,9 +388,23 @@ func (k kubernetesClusterResource) Delete(ctx context.Context, request tfsdk.Del
 		return
 	}
 
+	if state.ID.Null {
+		clusterID, created, err := resumeOrder(ctx, k.orderService, state.OrderID)
+		if err != nil {
+			addClientError(&response.Diagnostics, "unable to resume cluster creation", err)
+			return
+		}
+
+		if !created {
+			return
+		}
+
+		state.ID = types.Int64{Value: int64(clusterID)}
+	}
+
 	err := k.clusterService.Delete(ctx, int(state.ID.Value))
 	if err != nil {
-		response.Diagnostics.AddError("Client Error", fmt.Sprintf("unable to delete router: %s", err))
+		addClientError(&response.Diagnostics, "unable to delete router", err)
 		return
 	}
 }
@@ -329,3 +412,38 @@ func (k kubernetesClusterResource) Delete(ctx context.Context, request tfsdk.Del
 func (k kubernetesClusterResource) ImportState(ctx context.Context, request tfsdk.ImportResourceStateRequest, response *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
+
+func (k kubernetesClusterResource) ModifyPlan(ctx context.Context, request tfsdk.ModifyResourcePlanRequest, response *tfsdk.ModifyResourcePlanResponse) {
+	location, known := k.locations.ModifyPlan(ctx, request, response)
+	if !known || response.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state kubernetesClusterResourceData
+	response.Diagnostics.Append(response.Plan.GetAttribute(ctx, path.Root("node_product_id"), &plan.NodeProductID)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if !request.State.Raw.IsNull() {
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, locationIDPath, &state.LocationID)...)
+		response.Diagnostics.Append(request.State.GetAttribute(ctx, path.Root("node_product_id"), &state.NodeProductID)...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+
+		// only check the compatibility if something relevant changed to avoid unnecessary requests on every plan
+		if state.LocationID.Value == int64(location.ID) && state.NodeProductID.Equal(plan.NodeProductID) {
+			return
+		}
+	}
+
+	k.locations.CheckModule(ctx, location, moduleKubernetes, &response.Diagnostics)
+	k.locations.CheckProduct(ctx, path.Root("node_product_id"), plan.NodeProductID, location, &response.Diagnostics)
+}
+
+func (k kubernetesClusterResource) ConfigValidators(ctx context.Context) []tfsdk.ResourceConfigValidator {
+	return []tfsdk.ResourceConfigValidator{
+		validators.MutuallyExclusive("location_id", "location"),
+	}
+}
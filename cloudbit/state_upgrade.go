@@ -0,0 +1,42 @@
+package cloudbit
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+// Breaking changes to the schema of a resource increase the Version of its
+// schema by one. The schema before the change is kept as a frozen copy next to
+// the resource (e.g. computeNetworkInterfaceSchemaV0), which only has to
+// describe the types of the attributes, together with a frozen copy of its data
+// (e.g. computeNetworkInterfaceResourceDataV0). The resource then implements
+// tfsdk.ResourceWithUpgradeState, mapping every prior version to an upgrader
+// converting that data explicitly into the data of the current version.
+
+// newStateUpgrader returns a state upgrader which decodes the state stored with
+// the prior schema into P and stores the data returned by upgrade as the state
+// of the current schema.
+func newStateUpgrader[P, C any](priorSchema tfsdk.Schema, upgrade func(ctx context.Context, prior P) (C, diag.Diagnostics)) tfsdk.ResourceStateUpgrader {
+	return tfsdk.ResourceStateUpgrader{
+		PriorSchema: &priorSchema,
+		StateUpgrader: func(ctx context.Context, request tfsdk.UpgradeResourceStateRequest, response *tfsdk.UpgradeResourceStateResponse) {
+			var prior P
+			diagnostics := request.State.Get(ctx, &prior)
+			response.Diagnostics.Append(diagnostics...)
+			if response.Diagnostics.HasError() {
+				return
+			}
+
+			state, diagnostics := upgrade(ctx, prior)
+			response.Diagnostics.Append(diagnostics...)
+			if response.Diagnostics.HasError() {
+				return
+			}
+
+			diagnostics = response.State.Set(ctx, state)
+			response.Diagnostics.Append(diagnostics...)
+		},
+	}
+}
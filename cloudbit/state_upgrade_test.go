@@ -0,0 +1,103 @@
+package cloudbit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// TestResourceStateUpgraders verifies that every resource with a versioned
+// schema is able to upgrade the state of each of its prior versions.
+func TestResourceStateUpgraders(t *testing.T) {
+	ctx := context.Background()
+	prov := New(testProviderOptions...)
+
+	resourceTypes, diagnostics := prov.GetResources(ctx)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to get resources: %v", diagnostics)
+	}
+
+	for name, resourceType := range resourceTypes {
+		schema, diagnostics := resourceType.GetSchema(ctx)
+		if diagnostics.HasError() {
+			t.Fatalf("unable to get schema of %s: %v", name, diagnostics)
+		}
+
+		if schema.Version == 0 {
+			continue
+		}
+
+		res, diagnostics := resourceType.NewResource(ctx, prov)
+		if diagnostics.HasError() {
+			t.Fatalf("unable to create %s: %v", name, diagnostics)
+		}
+
+		upgradable, ok := res.(tfsdk.ResourceWithUpgradeState)
+		if !ok {
+			t.Errorf("%s has schema version %d, but does not implement state upgrades", name, schema.Version)
+			continue
+		}
+
+		upgraders := upgradable.UpgradeState(ctx)
+		for version := int64(0); version < schema.Version; version++ {
+			upgrader, ok := upgraders[version]
+			if !ok {
+				t.Errorf("%s is missing a state upgrader for version %d", name, version)
+				continue
+			}
+
+			if upgrader.PriorSchema == nil {
+				t.Errorf("state upgrader of %s for version %d is missing the prior schema", name, version)
+			}
+		}
+	}
+}
+
+// testUpgradeState upgrades the raw json state of the given version to the
+// current schema of the resource type.
+func testUpgradeState(t *testing.T, resourceType tfsdk.ResourceType, version int64, rawState string) tfsdk.State {
+	t.Helper()
+
+	ctx := context.Background()
+	prov := New(testProviderOptions...)
+
+	schema, diagnostics := resourceType.GetSchema(ctx)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to get schema: %v", diagnostics)
+	}
+
+	res, diagnostics := resourceType.NewResource(ctx, prov)
+	if diagnostics.HasError() {
+		t.Fatalf("unable to create resource: %v", diagnostics)
+	}
+
+	upgrader, ok := res.(tfsdk.ResourceWithUpgradeState).UpgradeState(ctx)[version]
+	if !ok {
+		t.Fatalf("no state upgrader for version %d", version)
+	}
+
+	raw := &tfprotov6.RawState{JSON: []byte(rawState)}
+
+	priorValue, err := raw.Unmarshal(upgrader.PriorSchema.TerraformType(ctx))
+	if err != nil {
+		t.Fatalf("unable to decode prior state: %s", err)
+	}
+
+	request := tfsdk.UpgradeResourceStateRequest{
+		RawState: raw,
+		State:    &tfsdk.State{Schema: *upgrader.PriorSchema, Raw: priorValue},
+	}
+
+	response := tfsdk.UpgradeResourceStateResponse{
+		State: tfsdk.State{Schema: schema},
+	}
+
+	upgrader.StateUpgrader(ctx, request, &response)
+	if response.Diagnostics.HasError() {
+		t.Fatalf("unable to upgrade state: %v", response.Diagnostics)
+	}
+
+	return response.State
+}
@@ -0,0 +1,694 @@
+package cloudbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"github.com/flowswiss/goclient/kubernetes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// testSweepPrefix is the prefix of the names of all resources created by the
+// acceptance tests.
+const testSweepPrefix = "test-"
+
+// The sweepers delete the resources leaked by failed acceptance tests. They
+// are run using `go test ./cloudbit -v -sweep=ALP1,ZRH1`, where the regions
+// are the keys of the locations to sweep. Entities which are deleted together
+// with their parent (e.g. security group rules, routes or network interfaces)
+// do not have a sweeper of their own.
+func init() {
+	resource.AddTestSweepers("cloudbit_kubernetes_cluster", &resource.Sweeper{
+		Name: "cloudbit_kubernetes_cluster",
+		F:    testSweepKubernetesClusters,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_load_balancer_member", &resource.Sweeper{
+		Name: "cloudbit_compute_load_balancer_member",
+		F:    testSweepComputeLoadBalancerMembers,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_load_balancer_pool", &resource.Sweeper{
+		Name:         "cloudbit_compute_load_balancer_pool",
+		Dependencies: []string{"cloudbit_compute_load_balancer_member"},
+		F:            testSweepComputeLoadBalancerPools,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_elastic_ip", &resource.Sweeper{
+		Name: "cloudbit_compute_elastic_ip",
+		F:    testSweepComputeElasticIPs,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_load_balancer", &resource.Sweeper{
+		Name:         "cloudbit_compute_load_balancer",
+		Dependencies: []string{"cloudbit_compute_load_balancer_pool"},
+		F:            testSweepComputeLoadBalancers,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_server", &resource.Sweeper{
+		Name:         "cloudbit_compute_server",
+		Dependencies: []string{"cloudbit_compute_elastic_ip"},
+		F:            testSweepComputeServers,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_snapshot", &resource.Sweeper{
+		Name: "cloudbit_compute_snapshot",
+		F:    testSweepComputeSnapshots,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_volume", &resource.Sweeper{
+		Name:         "cloudbit_compute_volume",
+		Dependencies: []string{"cloudbit_compute_server", "cloudbit_compute_snapshot"},
+		F:            testSweepComputeVolumes,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_router_interface", &resource.Sweeper{
+		Name: "cloudbit_compute_router_interface",
+		F:    testSweepComputeRouterInterfaces,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_router", &resource.Sweeper{
+		Name:         "cloudbit_compute_router",
+		Dependencies: []string{"cloudbit_compute_router_interface"},
+		F:            testSweepComputeRouters,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_network", &resource.Sweeper{
+		Name: "cloudbit_compute_network",
+		Dependencies: []string{
+			"cloudbit_kubernetes_cluster",
+			"cloudbit_compute_load_balancer",
+			"cloudbit_compute_server",
+			"cloudbit_compute_router_interface",
+			"cloudbit_compute_router",
+		},
+		F: testSweepComputeNetworks,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_security_group", &resource.Sweeper{
+		Name:         "cloudbit_compute_security_group",
+		Dependencies: []string{"cloudbit_kubernetes_cluster", "cloudbit_compute_server"},
+		F:            testSweepComputeSecurityGroups,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_certificate", &resource.Sweeper{
+		Name:         "cloudbit_compute_certificate",
+		Dependencies: []string{"cloudbit_compute_load_balancer_pool"},
+		F:            testSweepComputeCertificates,
+	})
+
+	resource.AddTestSweepers("cloudbit_compute_key_pair", &resource.Sweeper{
+		Name:         "cloudbit_compute_key_pair",
+		Dependencies: []string{"cloudbit_compute_server"},
+		F:            testSweepComputeKeyPairs,
+	})
+}
+
+func testSweepClient() (goclient.Client, error) {
+	token := os.Getenv("CLOUDBIT_TOKEN")
+	if token == "" {
+		return goclient.Client{}, errors.New("CLOUDBIT_TOKEN must be set to run the sweepers")
+	}
+
+	endpoint := defaultEndpoint
+	if val, ok := os.LookupEnv("CLOUDBIT_ENDPOINT"); ok {
+		endpoint = val
+	}
+
+	return goclient.NewClient(
+		goclient.WithToken(token),
+		goclient.WithBase(endpoint),
+		goclient.WithUserAgent("terraform-provider-cloudbit/sweeper"),
+	), nil
+}
+
+// testSweepable reports whether any of the names has been created by a test.
+func testSweepable(names ...string) bool {
+	for _, name := range names {
+		if strings.HasPrefix(name, testSweepPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func testSweepLocation(location common.Location, region string) bool {
+	return strings.EqualFold(location.Key, region)
+}
+
+// testSweepDelete deletes an entity and retries the deletion as long as the
+// entity is still in use, since some of the swept dependencies are deleted
+// asynchronously.
+func testSweepDelete(ctx context.Context, kind, name string, del func() error) error {
+	log.Printf("[INFO] deleting %s %q", kind, name)
+
+	err := resource.RetryContext(ctx, 5*time.Minute, func() *resource.RetryError {
+		err := del()
+
+		var apiErr goclient.APIError
+		if errors.As(err, &apiErr) && apiErr.Response().StatusCode == http.StatusConflict {
+			return resource.RetryableError(err)
+		}
+
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to delete %s %q: %w", kind, name, err)
+	}
+
+	return nil
+}
+
+func testSweepKubernetesClusters(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := kubernetes.NewClusterService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list clusters: %w", err)
+	}
+
+	for _, cluster := range list.Items {
+		if !testSweepLocation(cluster.Location, region) || !testSweepable(cluster.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "cluster", cluster.Name, func() error {
+			return service.Delete(ctx, cluster.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeLoadBalancerMembers(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewLoadBalancerService(client)
+
+	loadBalancers, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list load balancers: %w", err)
+	}
+
+	for _, loadBalancer := range loadBalancers.Items {
+		if !testSweepLocation(loadBalancer.Location, region) {
+			continue
+		}
+
+		pools, err := service.Pools(loadBalancer.ID).List(ctx, goclient.Cursor{NoFilter: 1})
+		if err != nil {
+			return fmt.Errorf("unable to list pools of load balancer %q: %w", loadBalancer.Name, err)
+		}
+
+		for _, pool := range pools.Items {
+			memberService := service.Pools(loadBalancer.ID).Members(pool.ID)
+
+			members, err := memberService.List(ctx, goclient.Cursor{NoFilter: 1})
+			if err != nil {
+				return fmt.Errorf("unable to list members of pool %q: %w", pool.Name, err)
+			}
+
+			for _, member := range members.Items {
+				if !testSweepable(loadBalancer.Name, pool.Name, member.Name) {
+					continue
+				}
+
+				err = testSweepDelete(ctx, "load balancer member", member.Name, func() error {
+					return memberService.Delete(ctx, member.ID)
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeLoadBalancerPools(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewLoadBalancerService(client)
+
+	loadBalancers, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list load balancers: %w", err)
+	}
+
+	for _, loadBalancer := range loadBalancers.Items {
+		if !testSweepLocation(loadBalancer.Location, region) {
+			continue
+		}
+
+		poolService := service.Pools(loadBalancer.ID)
+
+		pools, err := poolService.List(ctx, goclient.Cursor{NoFilter: 1})
+		if err != nil {
+			return fmt.Errorf("unable to list pools of load balancer %q: %w", loadBalancer.Name, err)
+		}
+
+		for _, pool := range pools.Items {
+			if !testSweepable(loadBalancer.Name, pool.Name) {
+				continue
+			}
+
+			err = testSweepDelete(ctx, "load balancer pool", pool.Name, func() error {
+				return poolService.Delete(ctx, pool.ID)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// testSweepComputeElasticIPs detaches and deletes the elastic ips attached to
+// resources created by tests. Elastic ips do not have a name, so the ones which
+// are not attached to anything cannot be attributed to a test and are kept.
+func testSweepComputeElasticIPs(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewElasticIPService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list elastic ips: %w", err)
+	}
+
+	for _, elasticIP := range list.Items {
+		if !testSweepLocation(elasticIP.Location, region) || !testSweepable(elasticIP.Attachment.Name) {
+			continue
+		}
+
+		attachment := elasticIP.Attachment
+
+		var detach func(ctx context.Context, id int) error
+		switch attachment.Type {
+		case "server":
+			detach = compute.NewServerElasticIPService(client, attachment.ID).Detach
+		default:
+			return fmt.Errorf("unable to detach elastic ip %s from unknown instance type %q", elasticIP.PublicIP, attachment.Type)
+		}
+
+		err = testSweepDelete(ctx, "elastic ip attachment", elasticIP.PublicIP, func() error {
+			return detach(ctx, elasticIP.ID)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = testSweepDelete(ctx, "elastic ip", elasticIP.PublicIP, func() error {
+			return service.Delete(ctx, elasticIP.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeLoadBalancers(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewLoadBalancerService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list load balancers: %w", err)
+	}
+
+	for _, loadBalancer := range list.Items {
+		if !testSweepLocation(loadBalancer.Location, region) || !testSweepable(loadBalancer.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "load balancer", loadBalancer.Name, func() error {
+			return service.Delete(ctx, loadBalancer.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeServers(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewServerService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list servers: %w", err)
+	}
+
+	for _, server := range list.Items {
+		if !testSweepLocation(server.Location, region) || !testSweepable(server.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "server", server.Name, func() error {
+			return service.Delete(ctx, server.ID, true)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeSnapshots(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewSnapshotService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list snapshots: %w", err)
+	}
+
+	for _, snapshot := range list.Items {
+		if !testSweepLocation(snapshot.Volume.Location, region) || !testSweepable(snapshot.Name, snapshot.Volume.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "snapshot", snapshot.Name, func() error {
+			return service.Delete(ctx, snapshot.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeVolumes(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewVolumeService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list volumes: %w", err)
+	}
+
+	for _, volume := range list.Items {
+		// root volumes are deleted together with their server
+		if volume.RootVolume || !testSweepLocation(volume.Location, region) || !testSweepable(volume.Name) {
+			continue
+		}
+
+		if volume.AttachedTo.ID != 0 {
+			err = testSweepDelete(ctx, "volume attachment", volume.Name, func() error {
+				return service.Detach(ctx, volume.ID, volume.AttachedTo.ID)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = testSweepDelete(ctx, "volume", volume.Name, func() error {
+			return service.Delete(ctx, volume.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// testSweepComputeRouterInterfaces removes the interfaces of routers created by
+// tests, as well as the interfaces connecting any router to a network created
+// by a test.
+func testSweepComputeRouterInterfaces(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewRouterService(client)
+
+	routers, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list routers: %w", err)
+	}
+
+	for _, router := range routers.Items {
+		if !testSweepLocation(router.Location, region) {
+			continue
+		}
+
+		interfaceService := service.RouterInterfaces(router.ID)
+
+		interfaces, err := interfaceService.List(ctx, goclient.Cursor{NoFilter: 1})
+		if err != nil {
+			return fmt.Errorf("unable to list interfaces of router %q: %w", router.Name, err)
+		}
+
+		for _, routerInterface := range interfaces.Items {
+			if !testSweepable(router.Name, routerInterface.Network.Name) {
+				continue
+			}
+
+			err = testSweepDelete(ctx, "router interface", routerInterface.PrivateIP, func() error {
+				return interfaceService.Delete(ctx, routerInterface.ID)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeRouters(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewRouterService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list routers: %w", err)
+	}
+
+	for _, router := range list.Items {
+		if !testSweepLocation(router.Location, region) || !testSweepable(router.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "router", router.Name, func() error {
+			return service.Delete(ctx, router.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeNetworks(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewNetworkService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list networks: %w", err)
+	}
+
+	for _, network := range list.Items {
+		if !testSweepLocation(network.Location, region) || !testSweepable(network.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "network", network.Name, func() error {
+			return service.Delete(ctx, network.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeSecurityGroups(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewSecurityGroupService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list security groups: %w", err)
+	}
+
+	for _, securityGroup := range list.Items {
+		// immutable security groups are managed by their cluster and deleted with it
+		if securityGroup.Default || securityGroup.Immutable {
+			continue
+		}
+
+		if !testSweepLocation(securityGroup.Location, region) || !testSweepable(securityGroup.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "security group", securityGroup.Name, func() error {
+			return service.Delete(ctx, securityGroup.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testSweepComputeCertificates(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewCertificateService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list certificates: %w", err)
+	}
+
+	for _, certificate := range list.Items {
+		if !testSweepLocation(certificate.Location, region) || !testSweepable(certificate.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "certificate", certificate.Name, func() error {
+			return service.Delete(ctx, certificate.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// testSweepComputeKeyPairs deletes the key pairs created by tests. Key pairs
+// are not bound to a location, so they are swept in every region.
+func testSweepComputeKeyPairs(region string) error {
+	ctx := context.Background()
+
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+
+	service := compute.NewKeyPairService(client)
+
+	list, err := service.List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		return fmt.Errorf("unable to list key pairs: %w", err)
+	}
+
+	for _, keyPair := range list.Items {
+		if !testSweepable(keyPair.Name) {
+			continue
+		}
+
+		err = testSweepDelete(ctx, "key pair", keyPair.Name, func() error {
+			return service.Delete(ctx, keyPair.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
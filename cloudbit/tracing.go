@@ -0,0 +1,161 @@
+package cloudbit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit"
+
+var (
+	traceResourceTypeKey   = attribute.Key("cloudbit.resource_type")
+	traceDataSourceTypeKey = attribute.Key("cloudbit.data_source_type")
+	traceOperationKey      = attribute.Key("cloudbit.operation")
+	traceRequestIDKey      = attribute.Key("cloudbit.request_id")
+)
+
+// tracingEnabled reports whether the spans should be exported, which is only the case if an otlp endpoint or exporter
+// has been configured using the standard environment variables.
+func tracingEnabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+
+	if exporter := os.Getenv("OTEL_TRACES_EXPORTER"); exporter != "" {
+		return exporter == "otlp"
+	}
+
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// SetupTracing registers a tracer provider exporting the spans of the provider using otlp over http, if it has been
+// enabled using the OTEL_* environment variables. The returned function flushes the remaining spans and has to be
+// called before the process exits.
+func SetupTracing(ctx context.Context, version string) (func(ctx context.Context) error, error) {
+	if !tracingEnabled() {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating otlp exporter: %w", err)
+	}
+
+	// attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence over the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String("terraform-provider-cloudbit"),
+			semconv.ServiceVersionKey.String(version),
+		),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating otel resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	return tracerProvider.Shutdown, nil
+}
+
+type traceAttributesKey struct{}
+
+// startResourceSpan starts the span of an operation (e.g. create) on a resource. The spans of the requests and waits
+// during the operation are children of this span and carry the same attributes.
+func startResourceSpan(ctx context.Context, resourceType string, operation string) (context.Context, trace.Span) {
+	return startOperationSpan(ctx, resourceType, operation, traceResourceTypeKey.String(resourceType))
+}
+
+// startDataSourceSpan starts the span of reading a data source.
+func startDataSourceSpan(ctx context.Context, dataSourceType string) (context.Context, trace.Span) {
+	return startOperationSpan(ctx, dataSourceType, "read", traceDataSourceTypeKey.String(dataSourceType))
+}
+
+func startOperationSpan(ctx context.Context, typeName string, operation string, typeAttribute attribute.KeyValue) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{typeAttribute, traceOperationKey.String(operation)}
+	ctx = context.WithValue(ctx, traceAttributesKey{}, attributes)
+
+	name := fmt.Sprintf("%s %s", typeName, operation)
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpanWithDiagnostics ends a span, marking it as failed if an error has been added to the diagnostics.
+func endSpanWithDiagnostics(span trace.Span, diagnostics *diag.Diagnostics) {
+	for _, d := range diagnostics.Errors() {
+		span.SetStatus(codes.Error, d.Summary())
+		break
+	}
+
+	span.End()
+}
+
+// startSpan starts a span within the current operation, e.g. for a long wait.
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if attributes, ok := ctx.Value(traceAttributesKey{}).([]attribute.KeyValue); ok {
+		opts = append(opts, trace.WithAttributes(attributes...))
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// endSpan ends a span, recording the error if there is one.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// traceTransport creates a client span for every request sent to the api.
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+func (t traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	name := fmt.Sprintf("HTTP %s", req.Method)
+	ctx, span := startSpan(req.Context(), name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...),
+	)
+
+	res, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		endSpan(span, err)
+		return res, err
+	}
+
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(res.StatusCode)...)
+	if requestID := res.Header.Get("X-Request-ID"); requestID != "" {
+		span.SetAttributes(traceRequestIDKey.String(requestID))
+	}
+
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(res.StatusCode, trace.SpanKindClient))
+	span.End()
+
+	return res, nil
+}
@@ -0,0 +1,146 @@
+package cloudbit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/flowswiss/goclient"
+	"github.com/flowswiss/goclient/common"
+	"github.com/flowswiss/goclient/compute"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cloudbit-ch/terraform-provider-cloudbit/cloudbit/fakeapi"
+)
+
+func TestTraceTransport_Spans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	api := fakeapi.New()
+	defer api.Close()
+
+	client := goclient.NewClient(
+		goclient.WithToken("fake"),
+		goclient.WithBase(api.URL()),
+		goclient.WithHTTPClientOption(func(c *http.Client) {
+			c.Transport = traceTransport{base: c.Transport}
+		}),
+	)
+
+	ctx, span := startResourceSpan(context.Background(), "cloudbit_compute_server", "create")
+
+	networks, err := compute.NewNetworkService(client).List(ctx, goclient.Cursor{NoFilter: 1})
+	if err != nil {
+		t.Fatalf("unable to list networks: %s", err)
+	}
+
+	ordering, err := compute.NewServerService(client).Create(ctx, compute.ServerCreate{
+		Name:       "test",
+		LocationID: fakeapi.LocationALP1,
+		ImageID:    fakeapi.ImageUbuntu,
+		ProductID:  fakeapi.ProductServerSmall,
+		NetworkID:  networks.Items[0].ID,
+		Password:   "password",
+	})
+	if err != nil {
+		t.Fatalf("unable to create server: %s", err)
+	}
+
+	_, err = waitForOrder(ctx, common.NewOrderService(client), ordering)
+	if err != nil {
+		t.Fatalf("unable to wait for order: %s", err)
+	}
+
+	span.End()
+
+	spans := exporter.GetSpans()
+
+	operation := findSpan(spans, "cloudbit_compute_server create")
+	if operation == nil {
+		t.Fatalf("missing span of the operation, got %d spans", len(spans))
+	}
+
+	wait := findSpan(spans, "WaitUntilProcessed")
+	if wait == nil {
+		t.Fatal("missing span of the wait for the order")
+	}
+
+	if wait.Parent.SpanID() != operation.SpanContext.SpanID() {
+		t.Error("expected the wait to be a child of the operation")
+	}
+
+	requests := 0
+	for _, s := range spans {
+		if s.Name != "HTTP GET" && s.Name != "HTTP POST" {
+			continue
+		}
+
+		requests++
+
+		attributes := attribute.NewSet(s.Attributes...)
+		for key, expected := range map[attribute.Key]string{
+			traceResourceTypeKey: "cloudbit_compute_server",
+			traceOperationKey:    "create",
+		} {
+			if value, _ := attributes.Value(key); value.AsString() != expected {
+				t.Errorf("expected %s of %s to be %q, got %q", key, s.Name, expected, value.AsString())
+			}
+		}
+
+		if value, _ := attributes.Value(traceRequestIDKey); value.AsString() == "" {
+			t.Errorf("expected %s to have a request id", s.Name)
+		}
+
+		if s.Parent.SpanID() != operation.SpanContext.SpanID() && s.Parent.SpanID() != wait.SpanContext.SpanID() {
+			t.Errorf("expected %s to be a child of the operation", s.Name)
+		}
+	}
+
+	if requests < 3 {
+		t.Errorf("expected a span for each request, got %d", requests)
+	}
+}
+
+func TestTracingEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected bool
+	}{
+		{name: "default", expected: false},
+		{name: "endpoint", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"}, expected: true},
+		{name: "traces endpoint", env: map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://localhost:4318/v1/traces"}, expected: true},
+		{name: "exporter", env: map[string]string{"OTEL_TRACES_EXPORTER": "otlp"}, expected: true},
+		{name: "other exporter", env: map[string]string{"OTEL_TRACES_EXPORTER": "none", "OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"}, expected: false},
+		{name: "disabled", env: map[string]string{"OTEL_SDK_DISABLED": "true", "OTEL_TRACES_EXPORTER": "otlp"}, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{"OTEL_SDK_DISABLED", "OTEL_TRACES_EXPORTER", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+				t.Setenv(key, test.env[key])
+			}
+
+			if actual := tracingEnabled(); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+
+	return nil
+}
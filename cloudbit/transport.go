@@ -0,0 +1,74 @@
+package cloudbit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// transportOptions configure the connection to the api, e.g. to reach it through a tls intercepting proxy.
+type transportOptions struct {
+	CABundle           string
+	CAFile             string
+	ClientCertificate  string
+	ClientKey          string
+	HTTPProxy          string
+	InsecureSkipVerify bool
+}
+
+// newHTTPTransport returns a clone of the default transport with the given options applied.
+func newHTTPTransport(options transportOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+	}
+
+	if options.CABundle != "" || options.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if options.CABundle != "" && !pool.AppendCertsFromPEM([]byte(options.CABundle)) {
+			return nil, errors.New("the ca bundle does not contain any pem encoded certificate")
+		}
+
+		if options.CAFile != "" {
+			data, err := os.ReadFile(options.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading ca file: %w", err)
+			}
+
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("the ca file %s does not contain any pem encoded certificate", options.CAFile)
+			}
+		}
+
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if options.ClientCertificate != "" || options.ClientKey != "" {
+		certificate, err := tls.X509KeyPair([]byte(options.ClientCertificate), []byte(options.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("parsing client certificate: %w", err)
+		}
+
+		transport.TLSClientConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if options.HTTPProxy != "" {
+		proxy, err := url.Parse(options.HTTPProxy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing http proxy: %w", err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return transport, nil
+}
@@ -0,0 +1,113 @@
+package cloudbit
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestNewHTTPTransport_CA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	bundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(bundle), 0o600); err != nil {
+		t.Fatalf("unable to write ca file: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		options transportOptions
+		valid   bool
+	}{
+		{name: "system", options: transportOptions{}, valid: false},
+		{name: "ca bundle", options: transportOptions{CABundle: bundle}, valid: true},
+		{name: "ca file", options: transportOptions{CAFile: caFile}, valid: true},
+		{name: "insecure", options: transportOptions{InsecureSkipVerify: true}, valid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport, err := newHTTPTransport(test.options)
+			if err != nil {
+				t.Fatalf("unable to create transport: %s", err)
+			}
+
+			res, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err == nil {
+				_ = res.Body.Close()
+			}
+
+			if test.valid && err != nil {
+				t.Errorf("expected the certificate to be trusted: %s", err)
+			}
+
+			if !test.valid && err == nil {
+				t.Error("expected the certificate not to be trusted")
+			}
+		})
+	}
+}
+
+func TestNewHTTPTransport_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	transport, err := newHTTPTransport(transportOptions{HTTPProxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("unable to create transport: %s", err)
+	}
+
+	res, err := (&http.Client{Transport: transport}).Get("http://api.cloudbit.invalid/v4/test")
+	if err != nil {
+		t.Fatalf("unable to send request: %s", err)
+	}
+	_ = res.Body.Close()
+
+	if proxied != "http://api.cloudbit.invalid/v4/test" {
+		t.Errorf("expected the request to be sent through the proxy, got %q", proxied)
+	}
+}
+
+func TestNewHTTPTransport_InvalidCABundle(t *testing.T) {
+	_, err := newHTTPTransport(transportOptions{CABundle: "invalid"})
+	if err == nil {
+		t.Fatal("expected an error for an invalid ca bundle")
+	}
+}
+
+func TestProvider_ConfigureTransport(t *testing.T) {
+	t.Setenv("CLOUDBIT_TOKEN", "token")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	response := testConfigureProvider(t, New().(*provider), map[string]tftypes.Value{
+		"insecure_skip_verify": tftypes.NewValue(tftypes.Bool, true),
+		"request_timeout":      tftypes.NewValue(tftypes.String, "30s"),
+	})
+
+	if response.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", response.Diagnostics)
+	}
+
+	if warnings := response.Diagnostics.Warnings(); len(warnings) != 1 || warnings[0].Summary() != "Insecure Connection" {
+		t.Errorf("expected a warning about the insecure connection, got %v", response.Diagnostics)
+	}
+
+	response = testConfigureProvider(t, New().(*provider), map[string]tftypes.Value{
+		"request_timeout": tftypes.NewValue(tftypes.String, "soon"),
+	})
+
+	if !response.Diagnostics.HasError() || response.Diagnostics.Errors()[0].Summary() != "Invalid Request Timeout" {
+		t.Errorf("expected an error for the invalid request timeout, got %v", response.Diagnostics)
+	}
+}
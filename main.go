@@ -43,7 +43,18 @@ func main() {
 		)
 	}
 
-	err := providerserver.Serve(context.Background(), factory, opts)
+	ctx := context.Background()
+
+	shutdownTracing, err := cloudbit.SetupTracing(ctx, version)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	err = providerserver.Serve(ctx, factory, opts)
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Printf("unable to flush traces: %s", shutdownErr)
+	}
+
 	if err != nil {
 		log.Fatal(err.Error())
 	}
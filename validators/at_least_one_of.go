@@ -0,0 +1,57 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+var _ tfsdk.ResourceConfigValidator = (*atLeastOneOfValidator)(nil)
+
+type atLeastOneOfValidator struct {
+	attributes []path.Path
+}
+
+func AtLeastOneOf(attributes ...string) tfsdk.ResourceConfigValidator {
+	return atLeastOneOfValidator{attributes: parseAttributePaths(attributes)}
+}
+
+func (a atLeastOneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("at least one of the attributes %s must be set", a.attributeList())
+}
+
+func (a atLeastOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return a.Description(ctx)
+}
+
+func (a atLeastOneOfValidator) ValidateResource(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	for _, attribute := range a.attributes {
+		value, diagnostics := getConfigValue(ctx, request.Config, attribute)
+		response.Diagnostics.Append(diagnostics...)
+		if response.Diagnostics.HasError() {
+			return
+		}
+
+		if value == nil || !value.IsNull() {
+			return
+		}
+	}
+
+	response.Diagnostics.AddAttributeError(
+		a.attributes[0],
+		"Missing Attribute Error",
+		fmt.Sprintf("At least one of the attributes %s must be set.", a.attributeList()),
+	)
+}
+
+func (a atLeastOneOfValidator) attributeList() string {
+	attributeStrings := make([]string, len(a.attributes))
+	for i, attribute := range a.attributes {
+		attributeStrings[i] = attribute.String()
+	}
+
+	return strings.Join(attributeStrings, ", ")
+}
@@ -5,24 +5,26 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 )
 
-var _ tfsdk.ResourceConfigValidator = (*mutuallyExclusiveValidator)(nil)
+var (
+	_ tfsdk.ResourceConfigValidator = (*mutuallyExclusiveValidator)(nil)
+	_ tfsdk.ProviderConfigValidator = (*mutuallyExclusiveValidator)(nil)
+)
 
 type mutuallyExclusiveValidator struct {
 	attributes []path.Path
 }
 
 func MutuallyExclusive(attributes ...string) tfsdk.ResourceConfigValidator {
-	attributePaths := make([]path.Path, len(attributes))
-	for i, attribute := range attributes {
-		attributePaths[i] = path.Root(attribute)
-	}
+	return mutuallyExclusiveValidator{attributes: parseAttributePaths(attributes)}
+}
 
-	return mutuallyExclusiveValidator{attributes: attributePaths}
+func ProviderMutuallyExclusive(attributes ...string) tfsdk.ProviderConfigValidator {
+	return mutuallyExclusiveValidator{attributes: parseAttributePaths(attributes)}
 }
 
 func (m mutuallyExclusiveValidator) Description(ctx context.Context) string {
@@ -39,23 +41,29 @@ func (m mutuallyExclusiveValidator) MarkdownDescription(ctx context.Context) str
 }
 
 func (m mutuallyExclusiveValidator) ValidateResource(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	m.validate(ctx, request.Config, &response.Diagnostics)
+}
+
+func (m mutuallyExclusiveValidator) ValidateProvider(ctx context.Context, request tfsdk.ValidateProviderConfigRequest, response *tfsdk.ValidateProviderConfigResponse) {
+	m.validate(ctx, request.Config, &response.Diagnostics)
+}
+
+func (m mutuallyExclusiveValidator) validate(ctx context.Context, config tfsdk.Config, diagnostics *diag.Diagnostics) {
 	previousAttributePath := path.Empty()
 
 	for _, attribute := range m.attributes {
-		var value attr.Value
-
-		diagnostics := request.Config.GetAttribute(ctx, attribute, &value)
-		response.Diagnostics.Append(diagnostics...)
-		if response.Diagnostics.HasError() {
+		value, d := getConfigValue(ctx, config, attribute)
+		diagnostics.Append(d...)
+		if diagnostics.HasError() {
 			return
 		}
 
-		if value.IsUnknown() || value.IsNull() {
+		if value == nil || value.IsUnknown() || value.IsNull() {
 			continue
 		}
 
 		if !previousAttributePath.Equal(path.Empty()) {
-			response.Diagnostics.AddAttributeError(
+			diagnostics.AddAttributeError(
 				attribute,
 				"Mutually Exclusive Attribute Error",
 				fmt.Sprintf("The attribute %s is mutually exclusive with %s. Please remove one of them.", attribute.String(), previousAttributePath.String()),
@@ -0,0 +1,42 @@
+package validators
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+// parseAttributePaths converts dot separated attribute names (e.g.
+// `health_check.type_id`) into paths of nested attributes.
+func parseAttributePaths(attributes []string) []path.Path {
+	attributePaths := make([]path.Path, len(attributes))
+	for i, attribute := range attributes {
+		steps := strings.Split(attribute, ".")
+
+		attributePaths[i] = path.Root(steps[0])
+		for _, step := range steps[1:] {
+			attributePaths[i] = attributePaths[i].AtName(step)
+		}
+	}
+
+	return attributePaths
+}
+
+// getConfigValue reads the value at the given path from the config. A nil
+// value is returned if any of the parent attributes is unknown, as the value
+// cannot be determined yet.
+func getConfigValue(ctx context.Context, config tfsdk.Config, attribute path.Path) (value attr.Value, diagnostics diag.Diagnostics) {
+	if parent := attribute.ParentPath(); !parent.Equal(path.Empty()) {
+		value, diagnostics = getConfigValue(ctx, config, parent)
+		if diagnostics.HasError() || value == nil || value.IsUnknown() {
+			return nil, diagnostics
+		}
+	}
+
+	diagnostics.Append(config.GetAttribute(ctx, attribute, &value)...)
+	return value, diagnostics
+}
@@ -0,0 +1,87 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+var (
+	_ tfsdk.ResourceConfigValidator = (*requiredTogetherValidator)(nil)
+	_ tfsdk.ProviderConfigValidator = (*requiredTogetherValidator)(nil)
+)
+
+type requiredTogetherValidator struct {
+	attributes []path.Path
+}
+
+func RequiredTogether(attributes ...string) tfsdk.ResourceConfigValidator {
+	return requiredTogetherValidator{attributes: parseAttributePaths(attributes)}
+}
+
+func ProviderRequiredTogether(attributes ...string) tfsdk.ProviderConfigValidator {
+	return requiredTogetherValidator{attributes: parseAttributePaths(attributes)}
+}
+
+func (r requiredTogetherValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("attributes %s must be set together", r.attributeList())
+}
+
+func (r requiredTogetherValidator) MarkdownDescription(ctx context.Context) string {
+	return r.Description(ctx)
+}
+
+func (r requiredTogetherValidator) ValidateResource(ctx context.Context, request tfsdk.ValidateResourceConfigRequest, response *tfsdk.ValidateResourceConfigResponse) {
+	r.validate(ctx, request.Config, &response.Diagnostics)
+}
+
+func (r requiredTogetherValidator) ValidateProvider(ctx context.Context, request tfsdk.ValidateProviderConfigRequest, response *tfsdk.ValidateProviderConfigResponse) {
+	r.validate(ctx, request.Config, &response.Diagnostics)
+}
+
+func (r requiredTogetherValidator) validate(ctx context.Context, config tfsdk.Config, diagnostics *diag.Diagnostics) {
+	var set, missing []path.Path
+
+	for _, attribute := range r.attributes {
+		value, d := getConfigValue(ctx, config, attribute)
+		diagnostics.Append(d...)
+		if diagnostics.HasError() {
+			return
+		}
+
+		if value == nil || value.IsUnknown() {
+			return
+		}
+
+		if value.IsNull() {
+			missing = append(missing, attribute)
+		} else {
+			set = append(set, attribute)
+		}
+	}
+
+	if len(set) == 0 {
+		return
+	}
+
+	for _, attribute := range missing {
+		diagnostics.AddAttributeError(
+			attribute,
+			"Missing Attribute Error",
+			fmt.Sprintf("The attributes %s must be set together. Please set %s as well.", r.attributeList(), attribute.String()),
+		)
+	}
+}
+
+func (r requiredTogetherValidator) attributeList() string {
+	attributeStrings := make([]string, len(r.attributes))
+	for i, attribute := range r.attributes {
+		attributeStrings[i] = attribute.String()
+	}
+
+	return strings.Join(attributeStrings, ", ")
+}